@@ -2,7 +2,9 @@ package binarysort
 
 import (
 	"bytes"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -16,6 +18,8 @@ func TestOrdering(t *testing.T) {
 		{"uint64", 0, 1000, func(buf []byte, i int) []byte { return AppendUint64(buf, uint64(i)) }},
 		{"int64", -1000, 1000, func(buf []byte, i int) []byte { return AppendInt64(buf, int64(i)) }},
 		{"float64", -1000, 1000, func(buf []byte, i int) []byte { return AppendFloat64(buf, float64(i)) }},
+		{"decimal", -1000, 1000, func(buf []byte, i int) []byte { return AppendDecimal(buf, big.NewInt(int64(i)), 2) }},
+		{"time", -1000, 1000, func(buf []byte, i int) []byte { return AppendTime(buf, time.Unix(0, int64(i)*1e3)) }},
 		{"text", -1000, 1000, func(buf []byte, i int) []byte {
 			b, err := AppendBase64(nil, AppendInt64(buf, int64(i)))
 			require.NoError(t, err)
@@ -39,3 +43,60 @@ func TestOrdering(t *testing.T) {
 		})
 	}
 }
+
+func TestTime(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(2020, 11, 2, 10, 30, 0, 123456000, time.UTC),
+		time.Date(1900, 1, 1, 0, 0, 0, 1000, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC),
+	} {
+		got, err := DecodeTime(AppendTime(nil, tm))
+		require.NoError(t, err)
+		require.True(t, tm.Equal(got), "expected %v, got %v", tm, got)
+		require.Equal(t, time.UTC, got.Location())
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		coef      int64
+		scale     int
+		wantCoef  int64
+		wantScale int
+	}{
+		{0, 2, 0, 0},
+		{12345, 2, 12345, 2},
+		{12500, 3, 125, 1},
+		{-12500, 3, -125, 1},
+		{5, 0, 5, 0},
+		{1200, 0, 1200, 0},
+		{-1, 5, -1, 5},
+	}
+
+	for _, test := range tests {
+		buf := AppendDecimal(nil, big.NewInt(test.coef), test.scale)
+		// add some data after the decimal to ensure it is not read.
+		buf = append(buf, 0x1f, 0x00)
+
+		coef, scale, n, err := DecodeDecimal(buf)
+		require.NoError(t, err)
+		require.Equal(t, len(buf)-2, n)
+		require.Equal(t, test.wantCoef, coef.Int64())
+		require.Equal(t, test.wantScale, scale)
+	}
+
+	// numbers with different scales must be sorted by value.
+	values := []struct {
+		coef  int64
+		scale int
+	}{
+		{-1000, 0}, {-1005, 1}, {-1, 0}, {-5, 1}, {-45, 2}, {-1, 3}, {0, 5},
+		{1, 3}, {45, 2}, {5, 1}, {1, 0}, {1005, 1}, {1000, 0},
+	}
+
+	for i := 1; i < len(values); i++ {
+		prev := AppendDecimal(nil, big.NewInt(values[i-1].coef), values[i-1].scale)
+		cur := AppendDecimal(nil, big.NewInt(values[i].coef), values[i].scale)
+		require.Equal(t, -1, bytes.Compare(prev, cur), "%v should be lower than %v", values[i-1], values[i])
+	}
+}
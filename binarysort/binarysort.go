@@ -9,6 +9,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"math"
+	"math/big"
+	"strings"
+	"time"
 )
 
 // Default Base64 encoder string doesn't preserve lexicographic order. This alternative
@@ -90,6 +93,122 @@ func DecodeFloat64(buf []byte) (float64, error) {
 	return math.Float64frombits(x), nil
 }
 
+// AppendTime takes a time and returns its binary representation.
+// The time is encoded as the number of microseconds elapsed since
+// January 1, 1970 UTC, so its location and nanoseconds are lost.
+func AppendTime(buf []byte, t time.Time) []byte {
+	return AppendInt64(buf, t.Unix()*1e6+int64(t.Nanosecond()/1e3))
+}
+
+// DecodeTime takes a byte slice and decodes it into a UTC time.
+func DecodeTime(buf []byte) (time.Time, error) {
+	x, err := DecodeInt64(buf)
+	if err != nil {
+		return time.Time{}, errors.New("cannot decode buffer to time")
+	}
+
+	return time.Unix(x/1e6, (x%1e6)*1e3).UTC(), nil
+}
+
+// Markers used to encode the sign of decimals.
+const (
+	decimalNegative byte = 0x01
+	decimalZero     byte = 0x02
+	decimalPositive byte = 0x03
+)
+
+// AppendDecimal takes a decimal number, whose value is coef * 10^-scale, and returns its binary representation.
+// Equal numbers have the same representation, regardless of their scale.
+// The number is encoded as its sign, followed, unless it is zero, by its exponent and its significant digits,
+// so that its value is 0.d1d2...dn * 10^exponent. For negative numbers, the exponent and the digits are inverted.
+func AppendDecimal(buf []byte, coef *big.Int, scale int) []byte {
+	if coef.Sign() == 0 {
+		return append(buf, decimalZero)
+	}
+
+	text := new(big.Int).Abs(coef).Text(10)
+	exp := int64(len(text) - scale)
+	digits := strings.TrimRight(text, "0")
+
+	if coef.Sign() > 0 {
+		buf = append(buf, decimalPositive)
+		buf = AppendInt64(buf, exp)
+		for i := 0; i < len(digits); i++ {
+			buf = append(buf, digits[i]-'0'+1)
+		}
+		return append(buf, 0x00)
+	}
+
+	buf = append(buf, decimalNegative)
+	buf = AppendInt64(buf, -exp)
+	for i := 0; i < len(digits); i++ {
+		buf = append(buf, 0xFF-(digits[i]-'0'+1))
+	}
+	return append(buf, 0xFF)
+}
+
+// DecodeDecimal takes a byte slice encoded with AppendDecimal and decodes the first decimal it contains.
+// It returns its coefficient and scale, along with the number of bytes read. Trailing zeros of the fractional part
+// are not encoded, thus the returned scale is the smallest scale that can represent the number.
+func DecodeDecimal(buf []byte) (coef *big.Int, scale int, n int, err error) {
+	errMalformed := errors.New("cannot decode buffer to decimal")
+
+	if len(buf) == 0 {
+		return nil, 0, 0, errMalformed
+	}
+
+	switch buf[0] {
+	case decimalZero:
+		return new(big.Int), 0, 1, nil
+	case decimalPositive, decimalNegative:
+	default:
+		return nil, 0, 0, errMalformed
+	}
+
+	neg := buf[0] == decimalNegative
+	if len(buf) < 9 {
+		return nil, 0, 0, errMalformed
+	}
+
+	exp, err := DecodeInt64(buf[1:9])
+	if err != nil {
+		return nil, 0, 0, errMalformed
+	}
+
+	var digits []byte
+	n = 9
+	for ; n < len(buf); n++ {
+		d := buf[n]
+		if neg {
+			d = 0xFF - d
+		}
+		if d == 0x00 {
+			break
+		}
+		digits = append(digits, d-1+'0')
+	}
+	if n == len(buf) || len(digits) == 0 {
+		return nil, 0, 0, errMalformed
+	}
+	n++
+
+	if neg {
+		exp = -exp
+	}
+
+	coef, _ = new(big.Int).SetString(string(digits), 10)
+	scale = len(digits) - int(exp)
+	if scale < 0 {
+		coef.Mul(coef, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+
+	return coef, scale, n, nil
+}
+
 // AppendBase64 encodes data into a custom base64 encoding. The resulting slice respects
 // natural sort-ordering.
 func AppendBase64(buf []byte, data []byte) ([]byte, error) {
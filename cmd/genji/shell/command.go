@@ -35,7 +35,7 @@ var commands = []struct {
 	{
 		Name:        ".tables",
 		DisplayName: ".tables",
-		Description: "List names of tables.",
+		Description: "List names of tables and views.",
 	},
 	{
 		Name:        ".indexes",
@@ -47,29 +47,42 @@ var commands = []struct {
 		Name:        ".dump",
 		Options:     "[table_name]",
 		DisplayName: ".dump",
-		Description: "Dump database content or table content as SQL statements.",
+		Description: "Dump database content or table and view content as SQL statements.",
 	},
 }
 
-// runTablesCmd shows all tables.
+// listTablesQuery selects the name of the tables, except the ones
+// storing the documents of materialized views.
+const listTablesQuery = "SELECT table_name FROM __genji_tables WHERE materialized_view IS NOT true"
+
+// runTablesCmd shows all tables, followed by all views.
 func runTablesCmd(db *genji.DB, cmd []string) error {
 	if len(cmd) > 1 {
 		return fmt.Errorf("usage: .tables")
 	}
 
-	res, err := db.Query("SELECT table_name FROM __genji_tables")
-	if err != nil {
-		return err
-	}
-	defer res.Close()
+	return db.View(func(tx *genji.Tx) error {
+		for _, q := range []string{listTablesQuery, "SELECT view_name FROM __genji_views"} {
+			res, err := tx.Query(q)
+			if err != nil {
+				return err
+			}
 
-	return res.Iterate(func(d document.Document) error {
-		var tableName string
-		err = document.Scan(d, &tableName)
-		if err != nil {
-			return err
+			err = res.Iterate(func(d document.Document) error {
+				var name string
+				err = document.Scan(d, &name)
+				if err != nil {
+					return err
+				}
+				fmt.Println(name)
+				return nil
+			})
+			res.Close()
+			if err != nil {
+				return err
+			}
 		}
-		fmt.Println(tableName)
+
 		return nil
 	})
 }
@@ -223,6 +236,9 @@ func dumpTable(tx *genji.Tx, tableName string, w io.Writer) error {
 
 		buf.WriteString("  " + fcs[i].Path.String() + " ")
 		buf.WriteString(strings.ToUpper(fcs[i].Type.String()))
+		if fc.Precision > 0 {
+			fmt.Fprintf(&buf, "(%d, %d)", fc.Precision, fc.Scale)
+		}
 		if fc.IsPrimaryKey {
 			buf.WriteString(" PRIMARY KEY")
 		}
@@ -293,7 +309,57 @@ func dumpTable(tx *genji.Tx, tableName string, w io.Writer) error {
 	})
 }
 
-// runDumpCmd dumps the given tables if provided, otherwise it dumps the whole database.
+// dumpView displays the given view as a SQL statement.
+func dumpView(tx *genji.Tx, viewName string, w io.Writer) error {
+	v, err := tx.GetView(viewName)
+	if err != nil {
+		return err
+	}
+
+	m := ""
+	if v.Materialized {
+		m = " MATERIALIZED"
+	}
+
+	_, err = fmt.Fprintf(w, "CREATE%s VIEW %s AS %s;\n", m, v.ViewName, v.Query)
+	return err
+}
+
+// sortViews returns the views ordered so that each view
+// comes after the views it depends on.
+func sortViews(views []*database.ViewConfig) []*database.ViewConfig {
+	byName := make(map[string]*database.ViewConfig, len(views))
+	for _, v := range views {
+		byName[v.ViewName] = v
+	}
+
+	sorted := make([]*database.ViewConfig, 0, len(views))
+	visited := make(map[string]bool, len(views))
+
+	var visit func(v *database.ViewConfig)
+	visit = func(v *database.ViewConfig) {
+		if visited[v.ViewName] {
+			return
+		}
+		visited[v.ViewName] = true
+
+		for _, name := range v.Dependencies {
+			if dep, ok := byName[name]; ok {
+				visit(dep)
+			}
+		}
+
+		sorted = append(sorted, v)
+	}
+
+	for _, v := range views {
+		visit(v)
+	}
+
+	return sorted
+}
+
+// runDumpCmd dumps the given tables and views if provided, otherwise it dumps the whole database.
 func runDumpCmd(db *genji.DB, tables []string, w io.Writer) error {
 	tx, err := db.Begin(false)
 	if err != nil {
@@ -306,9 +372,14 @@ func runDumpCmd(db *genji.DB, tables []string, w io.Writer) error {
 	}
 
 	for i, table := range tables {
-		err = dumpTable(tx, table, w)
+		// The documents of a materialized view are stored in a table
+		// with the same name, views must be looked up first.
+		err = dumpView(tx, table, w)
+		if errors.Is(err, database.ErrViewNotFound) {
+			err = dumpTable(tx, table, w)
+		}
 		if err != nil {
-			// If table doesn’t exist we skip it.
+			// If neither the view nor the table exist we skip it.
 			if errors.Is(err, database.ErrTableNotFound) {
 				continue
 			}
@@ -333,7 +404,7 @@ func runDumpCmd(db *genji.DB, tables []string, w io.Writer) error {
 
 	// tables slice argument is empty.
 	// Dump database content.
-	res, err := tx.Query("SELECT table_name FROM __genji_tables")
+	res, err := tx.Query(listTablesQuery)
 	if err != nil {
 		_, err = fmt.Fprintln(w, "ROLLBACK;")
 		return err
@@ -363,6 +434,28 @@ func runDumpCmd(db *genji.DB, tables []string, w io.Writer) error {
 		return err
 	}
 
+	// Views are dumped after the tables they may refer to.
+	views, err := tx.ListViews()
+	if err != nil {
+		_, err = fmt.Fprintln(w, "ROLLBACK;")
+		return err
+	}
+
+	for j, v := range sortViews(views) {
+		// Blank separation between the tables and the views.
+		if i > 0 && j == 0 {
+			if _, err := fmt.Fprintln(w, ""); err != nil {
+				return err
+			}
+		}
+
+		err = dumpView(tx, v.ViewName, w)
+		if err != nil {
+			_, err = fmt.Fprintln(w, "ROLLBACK;")
+			return err
+		}
+	}
+
 	_, err = fmt.Fprintln(w, "COMMIT;")
 	return err
 }
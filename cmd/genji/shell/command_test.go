@@ -168,3 +168,63 @@ func TestRunDumpCmd(t *testing.T) {
 	}
 
 }
+
+func TestRunDumpCmdWithViews(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a) VALUES (1);
+		CREATE VIEW v AS SELECT a FROM test WHERE a > 0;
+		CREATE MATERIALIZED VIEW mv AS SELECT a, COUNT(*) AS n FROM test GROUP BY a;
+		CREATE MATERIALIZED VIEW a AS SELECT a FROM v;
+	`)
+	require.NoError(t, err)
+
+	t.Run("Database", func(t *testing.T) {
+		var buf bytes.Buffer
+		err = runDumpCmd(db, nil, &buf)
+		require.NoError(t, err)
+
+		// views are dumped after the views they depend on.
+		require.Equal(t, `BEGIN TRANSACTION;
+CREATE TABLE test;
+INSERT INTO test VALUES {"a": 1};
+
+CREATE VIEW v AS SELECT a FROM test WHERE a > 0;
+CREATE MATERIALIZED VIEW a AS SELECT a FROM v;
+CREATE MATERIALIZED VIEW mv AS SELECT a, COUNT(*) AS n FROM test GROUP BY a;
+COMMIT;
+`, buf.String())
+
+		// the dump can be restored.
+		restored, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer restored.Close()
+
+		err = restored.Exec(buf.String())
+		require.NoError(t, err)
+	})
+
+	t.Run("View", func(t *testing.T) {
+		var buf bytes.Buffer
+		err = runDumpCmd(db, []string{"v"}, &buf)
+		require.NoError(t, err)
+		require.Equal(t, `BEGIN TRANSACTION;
+CREATE VIEW v AS SELECT a FROM test WHERE a > 0;
+COMMIT;
+`, buf.String())
+	})
+
+	t.Run("Materialized view", func(t *testing.T) {
+		var buf bytes.Buffer
+		err = runDumpCmd(db, []string{"mv"}, &buf)
+		require.NoError(t, err)
+		require.Equal(t, `BEGIN TRANSACTION;
+CREATE MATERIALIZED VIEW mv AS SELECT a, COUNT(*) AS n FROM test GROUP BY a;
+COMMIT;
+`, buf.String())
+	})
+}
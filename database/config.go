@@ -3,6 +3,7 @@ package database
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -20,6 +21,11 @@ type FieldConstraint struct {
 	IsPrimaryKey bool
 	IsNotNull    bool
 	DefaultValue document.Value
+
+	// Precision and Scale of decimal fields.
+	// A zero precision means the values are not rounded.
+	Precision int
+	Scale     int
 }
 
 func (f *FieldConstraint) HasDefaultValue() bool {
@@ -37,6 +43,10 @@ func (f *FieldConstraint) ToDocument() document.Document {
 	if f.HasDefaultValue() {
 		buf.Add("default_value", f.DefaultValue)
 	}
+	if f.Precision > 0 {
+		buf.Add("precision", document.NewIntegerValue(int64(f.Precision)))
+		buf.Add("scale", document.NewIntegerValue(int64(f.Scale)))
+	}
 	return buf
 }
 
@@ -78,9 +88,39 @@ func (f *FieldConstraint) ScanDocument(d document.Document) error {
 		f.DefaultValue = v
 	}
 
+	v, err = d.GetByField("precision")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		f.Precision = int(v.V.(int64))
+
+		v, err = d.GetByField("scale")
+		if err != nil {
+			return err
+		}
+		f.Scale = int(v.V.(int64))
+	}
+
 	return nil
 }
 
+// convert casts v to the type of the constraint.
+// Decimals are rounded to the scale of the constraint, if any.
+func (f *FieldConstraint) convert(v document.Value) (document.Value, error) {
+	v, err := v.CastAs(f.Type)
+	if err != nil || v.Type != document.DecimalValue || f.Precision == 0 {
+		return v, err
+	}
+
+	d, err := v.V.(document.Decimal).WithPrecision(f.Precision, f.Scale)
+	if err != nil {
+		return v, fmt.Errorf("field %q: %w", f.Path, err)
+	}
+
+	return document.NewDecimalValue(d), nil
+}
+
 // FieldConstraints is a list of field constraints.
 type FieldConstraints []FieldConstraint
 
@@ -127,7 +167,7 @@ func (f FieldConstraints) ValidateDocument(d document.Document) (document.Docume
 
 // Convert the document using the field constraints.
 // It converts any path that has a field constraint on it into the specified type.
-// If there is no constraint on an integer field or value, it converts it into a double.
+// If there is no constraint on an integer or decimal field or value, it converts it into a double.
 // Default values on missing fields are not applied.
 func (f FieldConstraints) Convert(d document.Document) (*document.FieldBuffer, error) {
 	fb := document.NewFieldBuffer()
@@ -138,7 +178,8 @@ func (f FieldConstraints) Convert(d document.Document) (*document.FieldBuffer, e
 
 	// convert the document using field constraints type information.
 	// if there is a type constraint on a path, apply it.
-	// if a value is an integer and has no constraint, convert it to double.
+	// if a value is an integer or a decimal and has no constraint, convert it to double,
+	// so that numbers of untyped fields are sorted and indexed together.
 	err = fb.Apply(func(p document.Path, v document.Value) (document.Value, error) {
 		for _, fc := range f {
 			if !fc.Path.IsEqual(p) {
@@ -148,14 +189,14 @@ func (f FieldConstraints) Convert(d document.Document) (*document.FieldBuffer, e
 			// check if the constraint enforce a particular type
 			// and if so convert the value to the new type.
 			if fc.Type != 0 {
-				return v.CastAs(fc.Type)
+				return fc.convert(v)
 			}
 			break
 		}
 
 		// no constraint have been found for this path.
-		// check if this is an integer and convert it to double.
-		if v.Type == document.IntegerValue {
+		// check if this is an integer or a decimal and convert it to double.
+		if v.Type == document.IntegerValue || v.Type == document.DecimalValue {
 			return v.CastAsDouble()
 		}
 
@@ -172,6 +213,9 @@ type TableInfo struct {
 	// name of the store associated with the table.
 	storeName []byte
 	readOnly  bool
+	// if true, the table stores the documents of the materialized view of the same name
+	// and can only be modified by refreshing the view.
+	materializedView bool
 	// if non-zero, this tableInfo has been created during the current transaction.
 	// it will be removed if the transaction is rolled back or set to false if its commited.
 	transactionID int64
@@ -206,6 +250,7 @@ func (ti *TableInfo) ToDocument() document.Document {
 	buf.Add("field_constraints", document.NewArrayValue(vbuf))
 
 	buf.Add("read_only", document.NewBoolValue(ti.readOnly))
+	buf.Add("materialized_view", document.NewBoolValue(ti.materializedView))
 	return buf
 }
 
@@ -250,6 +295,30 @@ func (ti *TableInfo) ScanDocument(d document.Document) error {
 	}
 
 	ti.readOnly = v.V.(bool)
+
+	// tables created before the introduction of materialized views
+	// don't have this field.
+	v, err = d.GetByField("materialized_view")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		ti.materializedView = v.V.(bool)
+	}
+
+	return nil
+}
+
+// checkWritable returns an error if the documents of the table cannot be modified.
+func (ti *TableInfo) checkWritable() error {
+	if ti.readOnly {
+		return errors.New("cannot write to read-only table")
+	}
+
+	if ti.materializedView {
+		return fmt.Errorf("cannot write to materialized view %q", ti.tableName)
+	}
+
 	return nil
 }
 
@@ -463,6 +532,21 @@ func (t *tableInfoStore) loadAllTableInfo(tx engine.Transaction) error {
 		},
 	}
 
+	t.tableInfos[viewStoreName] = TableInfo{
+		storeName: []byte(viewStoreName),
+		readOnly:  true,
+		FieldConstraints: []FieldConstraint{
+			{
+				Path: document.Path{
+					document.PathFragment{
+						FieldName: "view_name",
+					},
+				},
+				IsPrimaryKey: true,
+			},
+		},
+	}
+
 	t.tableInfos[indexStoreName] = TableInfo{
 		storeName: []byte(indexStoreName),
 		readOnly:  true,
@@ -687,6 +771,167 @@ func (t *indexStore) ListAll() ([]*IndexConfig, error) {
 	return idxList, nil
 }
 
+// ViewConfig holds the configuration of a view.
+type ViewConfig struct {
+	ViewName string
+	// SELECT statement returning the documents of the view.
+	Query string
+	// if true, the documents of the view are stored in a table with the same name.
+	Materialized bool
+	// if set, the materialized view is updated every time
+	// a document of this table is modified.
+	SourceTable string
+	// names of the tables and views read by the statement.
+	// They cannot be dropped or renamed while the view exists.
+	Dependencies []string
+}
+
+// ToDocument creates a document from a ViewConfig.
+func (v *ViewConfig) ToDocument() document.Document {
+	buf := document.NewFieldBuffer()
+
+	buf.Add("view_name", document.NewTextValue(v.ViewName))
+	buf.Add("query", document.NewTextValue(v.Query))
+	buf.Add("materialized", document.NewBoolValue(v.Materialized))
+	if v.SourceTable != "" {
+		buf.Add("source_table", document.NewTextValue(v.SourceTable))
+	}
+	if len(v.Dependencies) > 0 {
+		var vb document.ValueBuffer
+		for _, name := range v.Dependencies {
+			vb = vb.Append(document.NewTextValue(name))
+		}
+		buf.Add("dependencies", document.NewArrayValue(vb))
+	}
+	return buf
+}
+
+// ScanDocument implements the document.Scanner interface.
+func (v *ViewConfig) ScanDocument(d document.Document) error {
+	f, err := d.GetByField("view_name")
+	if err != nil {
+		return err
+	}
+	v.ViewName = f.V.(string)
+
+	f, err = d.GetByField("query")
+	if err != nil {
+		return err
+	}
+	v.Query = f.V.(string)
+
+	f, err = d.GetByField("materialized")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		v.Materialized = f.V.(bool)
+	}
+
+	f, err = d.GetByField("source_table")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		v.SourceTable = f.V.(string)
+	}
+
+	f, err = d.GetByField("dependencies")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		v.Dependencies = v.Dependencies[:0]
+		err = f.V.(document.Array).Iterate(func(i int, value document.Value) error {
+			v.Dependencies = append(v.Dependencies, value.V.(string))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type viewStore struct {
+	db *Database
+	st engine.Store
+}
+
+func (t *viewStore) Insert(cfg ViewConfig) error {
+	key := []byte(cfg.ViewName)
+	_, err := t.st.Get(key)
+	if err == nil {
+		return ErrViewAlreadyExists
+	}
+	if err != engine.ErrKeyNotFound {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = t.db.Codec.NewEncoder(&buf).EncodeDocument(cfg.ToDocument())
+	if err != nil {
+		return err
+	}
+
+	return t.st.Put(key, buf.Bytes())
+}
+
+func (t *viewStore) Get(viewName string) (*ViewConfig, error) {
+	v, err := t.st.Get([]byte(viewName))
+	if err == engine.ErrKeyNotFound {
+		return nil, ErrViewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ViewConfig
+	err = cfg.ScanDocument(t.db.Codec.NewDocument(v))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (t *viewStore) Delete(viewName string) error {
+	err := t.st.Delete([]byte(viewName))
+	if err == engine.ErrKeyNotFound {
+		return ErrViewNotFound
+	}
+	return err
+}
+
+func (t *viewStore) ListAll() ([]*ViewConfig, error) {
+	it := t.st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var views []*ViewConfig
+	var buf []byte
+	var err error
+	for it.Seek(nil); it.Valid(); it.Next() {
+		buf, err = it.Item().ValueCopy(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		var cfg ViewConfig
+		err = cfg.ScanDocument(t.db.Codec.NewDocument(buf))
+		if err != nil {
+			return nil, err
+		}
+
+		views = append(views, &cfg)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
 func arrayToPath(a document.Array) (document.Path, error) {
 	var path document.Path
 
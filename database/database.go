@@ -6,6 +6,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/genjidb/genji/document/encoding"
 	"github.com/genjidb/genji/engine"
@@ -23,6 +24,10 @@ type Database struct {
 	// incremented atomically every time Begin is called.
 	lastTransactionID int64
 
+	// This is incremented atomically every time a transaction
+	// that modified the tables, the indexes or the views is committed or rolled back.
+	schemaVersion int64
+
 	// If this is non-nil, the user is running an explicit transaction
 	// using the BEGIN statement.
 	// Only one attached transaction can be run at a time and any calls to DB.Begin()
@@ -32,10 +37,20 @@ type Database struct {
 
 	// Codec used to encode documents. Defaults to MessagePack.
 	Codec encoding.Codec
+
+	// ViewMaintainer computes the documents of the materialized views.
+	// If nil, materialized views cannot be created.
+	ViewMaintainer ViewMaintainer
+
+	// materialized views maintained from each table,
+	// loaded for the last schema version.
+	viewDeps   *viewDependencies
+	viewDepsMu sync.Mutex
 }
 
 type Options struct {
-	Codec encoding.Codec
+	Codec          encoding.Codec
+	ViewMaintainer ViewMaintainer
 }
 
 // New initializes the DB using the given engine.
@@ -45,8 +60,9 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 	}
 
 	db := Database{
-		ng:    ng,
-		Codec: opts.Codec,
+		ng:             ng,
+		Codec:          opts.Codec,
+		ViewMaintainer: opts.ViewMaintainer,
 	}
 
 	ntx, err := db.ng.Begin(ctx, engine.TxOptions{
@@ -88,6 +104,14 @@ func (db *Database) initInternalStores(tx engine.Transaction) error {
 	if err == engine.ErrStoreNotFound {
 		err = tx.CreateStore([]byte(indexStoreName))
 	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.GetStore([]byte(viewStoreName))
+	if err == engine.ErrStoreNotFound {
+		err = tx.CreateStore([]byte(viewStoreName))
+	}
 	return err
 }
 
@@ -135,6 +159,8 @@ func (db *Database) BeginTx(ctx context.Context, opts *TxOptions) (*Transaction,
 		tx:             ntx,
 		writable:       !opts.ReadOnly,
 		attached:       opts.Attached,
+		startedAt:      time.Now(),
+		schemaVersion:  db.SchemaVersion(),
 		tableInfoStore: db.tableInfoStore,
 	}
 
@@ -143,6 +169,11 @@ func (db *Database) BeginTx(ctx context.Context, opts *TxOptions) (*Transaction,
 		return nil, err
 	}
 
+	tx.viewStore, err = tx.getViewStore()
+	if err != nil {
+		return nil, err
+	}
+
 	if opts.Attached {
 		db.attachedTransaction = &tx
 	}
@@ -160,6 +191,13 @@ type TxOptions struct {
 	Attached bool
 }
 
+// SchemaVersion returns a number that changes every time a transaction that modified
+// the tables, the indexes or the views is closed. It can be used to know if a query plan
+// that depends on the schema must be rebuilt.
+func (db *Database) SchemaVersion() int64 {
+	return atomic.LoadInt64(&db.schemaVersion)
+}
+
 // GetAttachedTx returns the transaction attached to the database. It returns nil if there is no
 // such transaction.
 // The returned transaction is not thread safe.
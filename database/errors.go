@@ -2,6 +2,7 @@ package database
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
@@ -19,6 +20,13 @@ var (
 	// same name as an existing one.
 	ErrIndexAlreadyExists = errors.New("index already exists")
 
+	// ErrViewNotFound is returned when the targeted view doesn't exist.
+	ErrViewNotFound = errors.New("view not found")
+
+	// ErrViewAlreadyExists is returned when attempting to create a view with the
+	// same name as an existing view or table.
+	ErrViewAlreadyExists = errors.New("view already exists")
+
 	// ErrDocumentNotFound is returned when no document is associated with the provided key.
 	ErrDocumentNotFound = errors.New("document not found")
 
@@ -26,3 +34,18 @@ var (
 	// or if there is a unique index violation.
 	ErrDuplicateDocument = errors.New("duplicate document")
 )
+
+// ViewNotWritableError is returned when attempting to modify a view
+// as if it were a table. It wraps ErrTableNotFound.
+type ViewNotWritableError struct {
+	ViewName string
+}
+
+func (e *ViewNotWritableError) Error() string {
+	return fmt.Sprintf("cannot modify view %q", e.ViewName)
+}
+
+// Unwrap returns ErrTableNotFound.
+func (e *ViewNotWritableError) Unwrap() error {
+	return ErrTableNotFound
+}
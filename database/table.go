@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding"
@@ -50,11 +51,16 @@ func (t *Table) Insert(d document.Document) ([]byte, error) {
 		return nil, err
 	}
 
-	if info.readOnly {
-		return nil, errors.New("cannot write to read-only table")
+	err = info.checkWritable()
+	if err != nil {
+		return nil, err
 	}
 
-	d, err = info.FieldConstraints.ValidateDocument(d)
+	return t.insert(info, d)
+}
+
+func (t *Table) insert(info *TableInfo, d document.Document) ([]byte, error) {
+	d, err := info.FieldConstraints.ValidateDocument(d)
 	if err != nil {
 		return nil, err
 	}
@@ -64,25 +70,36 @@ func (t *Table) Insert(d document.Document) ([]byte, error) {
 		return nil, err
 	}
 
-	_, err = t.Store.Get(key)
+	err = t.insertWithKey(key, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// insertWithKey stores d under the given key and updates the indexes
+// and the materialized views. d is expected to be validated.
+func (t *Table) insertWithKey(key []byte, d document.Document) error {
+	_, err := t.Store.Get(key)
 	if err == nil {
-		return nil, ErrDuplicateDocument
+		return ErrDuplicateDocument
 	}
 
 	var buf bytes.Buffer
 	err = t.tx.db.Codec.NewEncoder(&buf).EncodeDocument(d)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode document: %w", err)
+		return fmt.Errorf("failed to encode document: %w", err)
 	}
 
 	err = t.Store.Put(key, buf.Bytes())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	indexes, err := t.Indexes()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	for _, idx := range indexes {
@@ -94,14 +111,113 @@ func (t *Table) Insert(d document.Document) ([]byte, error) {
 		err = idx.Set(v, key)
 		if err != nil {
 			if err == index.ErrDuplicate {
-				return nil, ErrDuplicateDocument
+				return ErrDuplicateDocument
+			}
+
+			return err
+		}
+	}
+
+	views, err := t.materializedViews()
+	if err != nil {
+		return err
+	}
+
+	return t.updateViews(views, nil, d)
+}
+
+// A ConflictTarget restricts the constraints checked by FindConflict.
+// The zero value designates the primary key and every unique index of the table.
+type ConflictTarget struct {
+	// Path designates the primary key or the unique index of the given path.
+	Path document.Path
+	// IndexName designates the unique index of the given name.
+	IndexName string
+}
+
+// FindConflict returns the key of the document that prevents d from being inserted
+// in the table because it has the same primary key or the same value for a unique index.
+// Only the constraints designated by target are checked.
+// If there is no such document, it returns a nil key.
+// The document is expected to be validated against the field constraints of the table.
+func (t *Table) FindConflict(d document.Document, target ConflictTarget) ([]byte, error) {
+	info, err := t.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := t.Indexes()
+	if err != nil {
+		return nil, err
+	}
+
+	pk := info.GetPrimaryKey()
+	checkPK := pk != nil && target.IndexName == "" && (target.Path == nil || pk.Path.IsEqual(target.Path))
+
+	var unique []Index
+	for _, idx := range indexes {
+		if !idx.Opts.Unique {
+			continue
+		}
+
+		switch {
+		case target.IndexName != "":
+			if idx.Opts.IndexName != target.IndexName {
+				continue
+			}
+		case target.Path != nil:
+			if !idx.Opts.Path.IsEqual(target.Path) {
+				continue
 			}
+		}
+
+		unique = append(unique, idx)
+	}
+
+	// check indexes in a deterministic order.
+	sort.Slice(unique, func(i, j int) bool {
+		return unique[i].Opts.IndexName < unique[j].Opts.IndexName
+	})
+
+	switch {
+	case target.IndexName != "" && len(unique) == 0:
+		return nil, fmt.Errorf("no unique index %q on table %q", target.IndexName, t.name)
+	case target.Path != nil && !checkPK && len(unique) == 0:
+		return nil, fmt.Errorf("no primary key or unique index on path %q of table %q", target.Path, t.name)
+	}
+
+	if checkPK {
+		key, err := t.generateKey(d)
+		if err != nil {
+			return nil, err
+		}
 
+		_, err = t.Store.Get(key)
+		if err == nil {
+			return key, nil
+		}
+		if err != engine.ErrKeyNotFound {
 			return nil, err
 		}
 	}
 
-	return key, nil
+	for _, idx := range unique {
+		// missing values are indexed as null by Insert.
+		v, err := idx.Opts.Path.GetValue(d)
+		if err != nil {
+			v = document.NewNullValue()
+		}
+
+		key, err := idx.Get(v)
+		if err == nil {
+			return key, nil
+		}
+		if err != engine.ErrKeyNotFound {
+			return nil, err
+		}
+	}
+
+	return nil, nil
 }
 
 // Delete a document by key.
@@ -112,24 +228,46 @@ func (t *Table) Delete(key []byte) error {
 		return err
 	}
 
-	if info.readOnly {
-		return errors.New("cannot write to read-only table")
+	err = info.checkWritable()
+	if err != nil {
+		return err
 	}
 
+	return t.delete(key)
+}
+
+func (t *Table) delete(key []byte) error {
 	d, err := t.GetDocument(key)
 	if err != nil {
 		return err
 	}
 
+	views, err := t.materializedViews()
+	if err != nil {
+		return err
+	}
+
+	// the document must remain readable once deleted
+	// to update the materialized views.
+	if len(views) > 0 {
+		var fb document.FieldBuffer
+		err = fb.Copy(d)
+		if err != nil {
+			return err
+		}
+		d = &fb
+	}
+
 	indexes, err := t.Indexes()
 	if err != nil {
 		return err
 	}
 
 	for _, idx := range indexes {
+		// missing values are indexed as null by Insert.
 		v, err := idx.Opts.Path.GetValue(d)
 		if err != nil {
-			return err
+			v = document.NewNullValue()
 		}
 
 		err = idx.Delete(v, key)
@@ -138,7 +276,12 @@ func (t *Table) Delete(key []byte) error {
 		}
 	}
 
-	return t.Store.Delete(key)
+	err = t.Store.Delete(key)
+	if err != nil {
+		return err
+	}
+
+	return t.updateViews(views, d, nil)
 }
 
 // Replace a document by key.
@@ -150,8 +293,9 @@ func (t *Table) Replace(key []byte, d document.Document) error {
 		return err
 	}
 
-	if info.readOnly {
-		return errors.New("cannot write to read-only table")
+	err = info.checkWritable()
+	if err != nil {
+		return err
 	}
 
 	d, err = info.FieldConstraints.ValidateDocument(d)
@@ -174,11 +318,28 @@ func (t *Table) replace(indexes map[string]Index, key []byte, d document.Documen
 		return err
 	}
 
+	views, err := t.materializedViews()
+	if err != nil {
+		return err
+	}
+
+	// the old document must remain readable once replaced
+	// to update the materialized views.
+	if len(views) > 0 {
+		var fb document.FieldBuffer
+		err = fb.Copy(old)
+		if err != nil {
+			return err
+		}
+		old = &fb
+	}
+
 	// remove key from indexes
 	for _, idx := range indexes {
+		// missing values are indexed as null by Insert.
 		v, err := idx.Opts.Path.GetValue(old)
 		if err != nil {
-			return err
+			v = document.NewNullValue()
 		}
 
 		err = idx.Delete(v, key)
@@ -204,16 +365,20 @@ func (t *Table) replace(indexes map[string]Index, key []byte, d document.Documen
 	for _, idx := range indexes {
 		v, err := idx.Opts.Path.GetValue(d)
 		if err != nil {
-			continue
+			v = document.NewNullValue()
 		}
 
 		err = idx.Set(v, key)
 		if err != nil {
+			if err == index.ErrDuplicate {
+				return ErrDuplicateDocument
+			}
+
 			return err
 		}
 	}
 
-	return err
+	return t.updateViews(views, old, d)
 }
 
 // Indexes returns a map of all the indexes of a table.
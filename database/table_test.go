@@ -342,8 +342,8 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, false, document.Value{}},
-				{parsePath(t, "bar"), document.IntegerValue, false, false, document.Value{}},
+				{Path: parsePath(t, "foo"), Type: document.IntegerValue},
+				{Path: parsePath(t, "bar"), Type: document.IntegerValue},
 			},
 		})
 		require.NoError(t, err)
@@ -383,7 +383,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.DoubleValue, false, false, document.Value{}},
+				{Path: parsePath(t, "foo"), Type: document.DoubleValue},
 			},
 		})
 		require.NoError(t, err)
@@ -405,7 +405,7 @@ func TestTableInsert(t *testing.T) {
 		// no enforced type, not null
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), 0, false, true, document.Value{}},
+				{Path: parsePath(t, "foo"), IsNotNull: true},
 			},
 		})
 		require.NoError(t, err)
@@ -415,7 +415,7 @@ func TestTableInsert(t *testing.T) {
 		// enforced type, not null
 		err = tx.CreateTable("test2", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, true, document.Value{}},
+				{Path: parsePath(t, "foo"), Type: document.IntegerValue, IsNotNull: true},
 			},
 		})
 		require.NoError(t, err)
@@ -460,7 +460,7 @@ func TestTableInsert(t *testing.T) {
 		// no enforced type, not null
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), 0, false, true, document.NewIntegerValue(42)},
+				{Path: parsePath(t, "foo"), IsNotNull: true, DefaultValue: document.NewIntegerValue(42)},
 			},
 		})
 		require.NoError(t, err)
@@ -470,7 +470,7 @@ func TestTableInsert(t *testing.T) {
 		// enforced type, not null
 		err = tx.CreateTable("test2", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, true, document.NewIntegerValue(42)},
+				{Path: parsePath(t, "foo"), Type: document.IntegerValue, IsNotNull: true, DefaultValue: document.NewIntegerValue(42)},
 			},
 		})
 		require.NoError(t, err)
@@ -526,7 +526,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo[1]"), 0, false, true, document.Value{}},
+				{Path: parsePath(t, "foo[1]"), IsNotNull: true},
 			},
 		})
 		require.NoError(t, err)
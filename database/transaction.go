@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
@@ -14,6 +16,7 @@ var (
 	internalPrefix     = "__genji_"
 	tableInfoStoreName = internalPrefix + "tables"
 	indexStoreName     = internalPrefix + "indexes"
+	viewStoreName      = internalPrefix + "views"
 )
 
 // Transaction represents a database transaction. It provides methods for managing the
@@ -27,9 +30,18 @@ type Transaction struct {
 	writable bool
 	// if set to true, this transaction is attached to the database
 	attached bool
+	// time at which the transaction was started
+	startedAt time.Time
+	// if set to true, this transaction modified the tables, the indexes or the views
+	schemaChanged bool
+	// schema version of the database when the transaction was started
+	schemaVersion int64
+	// materialized views maintained from each table, as seen by this transaction
+	viewDeps *viewDependencies
 
 	tableInfoStore *tableInfoStore
 	indexStore     *indexStore
+	viewStore      *viewStore
 }
 
 // DB returns the underlying database that created the transaction.
@@ -37,6 +49,11 @@ func (tx *Transaction) DB() *Database {
 	return tx.db
 }
 
+// StartedAt returns the time at which the transaction was started.
+func (tx *Transaction) StartedAt() time.Time {
+	return tx.startedAt
+}
+
 // Rollback the transaction. Can be used safely after commit.
 func (tx *Transaction) Rollback() error {
 	if tx.writable {
@@ -44,6 +61,7 @@ func (tx *Transaction) Rollback() error {
 	}
 
 	err := tx.tx.Rollback()
+	tx.closeSchema()
 	if err != nil {
 		return err
 	}
@@ -67,6 +85,7 @@ func (tx *Transaction) Commit() error {
 	}
 
 	err := tx.tx.Commit()
+	tx.closeSchema()
 	if err != nil {
 		return err
 	}
@@ -84,6 +103,20 @@ func (tx *Transaction) Commit() error {
 
 }
 
+// SchemaChanged returns whether the transaction modified the tables, the indexes or the views.
+func (tx *Transaction) SchemaChanged() bool {
+	return tx.schemaChanged
+}
+
+// closeSchema changes the schema version of the database
+// if the transaction modified the schema.
+func (tx *Transaction) closeSchema() {
+	if tx.schemaChanged {
+		tx.schemaChanged = false
+		atomic.AddInt64(&tx.db.schemaVersion, 1)
+	}
+}
+
 // Writable indicates if the transaction is writable or not.
 func (tx *Transaction) Writable() bool {
 	return tx.writable
@@ -100,7 +133,20 @@ func (tx *Transaction) CreateTable(name string, info *TableInfo) error {
 		info = new(TableInfo)
 	}
 
+	_, err := tx.viewStore.Get(name)
+	if err == nil {
+		return ErrViewAlreadyExists
+	}
+	if err != ErrViewNotFound {
+		return err
+	}
+
+	return tx.createTable(name, info)
+}
+
+func (tx *Transaction) createTable(name string, info *TableInfo) error {
 	info.tableName = name
+	tx.schemaChanged = true
 	err := tx.tableInfoStore.Insert(tx, name, info)
 	if err != nil {
 		return err
@@ -118,7 +164,7 @@ func (tx *Transaction) CreateTable(name string, info *TableInfo) error {
 func (tx *Transaction) GetTable(name string) (*Table, error) {
 	ti, err := tx.tableInfoStore.Get(tx, name)
 	if err != nil {
-		return nil, err
+		return nil, tx.viewError(name, err)
 	}
 
 	s, err := tx.tx.GetStore(ti.storeName)
@@ -135,7 +181,8 @@ func (tx *Transaction) GetTable(name string) (*Table, error) {
 }
 
 func (tx *Transaction) AddField(name string, fc FieldConstraint) error {
-	return tx.tableInfoStore.modifyTable(tx, name, func(info *TableInfo) error {
+	tx.schemaChanged = true
+	err := tx.tableInfoStore.modifyTable(tx, name, func(info *TableInfo) error {
 		for _, field := range info.FieldConstraints {
 			if field.Path.IsEqual(fc.Path) {
 				return fmt.Errorf("field %q already exists", fc.Path.String())
@@ -151,21 +198,37 @@ func (tx *Transaction) AddField(name string, fc FieldConstraint) error {
 		info.FieldConstraints = append(info.FieldConstraints, fc)
 		return nil
 	})
+	return tx.viewError(name, err)
 }
 
 // RenameTable renames a table.
 // If it doesn't exist, it returns ErrTableNotFound.
 func (tx *Transaction) RenameTable(oldName, newName string) error {
 	ti, err := tx.tableInfoStore.Get(tx, oldName)
+	if err != nil {
+		return tx.viewError(oldName, err)
+	}
+
+	err = ti.checkWritable()
 	if err != nil {
 		return err
 	}
 
-	if ti.readOnly {
-		return errors.New("cannot write to read-only table")
+	err = tx.checkDependentViews(oldName)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.viewStore.Get(newName)
+	if err == nil {
+		return ErrViewAlreadyExists
+	}
+	if err != ErrViewNotFound {
+		return err
 	}
 
 	ti.tableName = newName
+	tx.schemaChanged = true
 	// Insert the TableInfo keyed by the newName name.
 	err = tx.tableInfoStore.Insert(tx, newName, ti)
 	if err != nil {
@@ -195,17 +258,32 @@ func (tx *Transaction) RenameTable(oldName, newName string) error {
 func (tx *Transaction) DropTable(name string) error {
 	ti, err := tx.tableInfoStore.Get(tx, name)
 	if err != nil {
-		return err
+		return tx.viewError(name, err)
 	}
 
 	if ti.readOnly {
 		return errors.New("cannot write to read-only table")
 	}
 
+	if ti.materializedView {
+		return fmt.Errorf("%q is a materialized view", name)
+	}
+
+	err = tx.checkDependentViews(name)
+	if err != nil {
+		return err
+	}
+
+	return tx.dropTable(name, ti)
+}
+
+func (tx *Transaction) dropTable(name string, ti *TableInfo) error {
+	tx.schemaChanged = true
 	it := tx.indexStore.st.Iterator(engine.IteratorOptions{})
 	defer it.Close()
 
 	var buf []byte
+	var err error
 	for it.Seek(nil); it.Valid(); it.Next() {
 		item := it.Item()
 		buf, err = item.ValueCopy(buf)
@@ -266,6 +344,7 @@ func (tx *Transaction) CreateIndex(opts IndexConfig) error {
 		}
 	}
 
+	tx.schemaChanged = true
 	return tx.indexStore.Insert(opts)
 }
 
@@ -293,6 +372,7 @@ func (tx *Transaction) DropIndex(name string) error {
 	if err != nil {
 		return err
 	}
+	tx.schemaChanged = true
 	err = tx.indexStore.Delete(name)
 	if err != nil {
 		return err
@@ -374,6 +454,92 @@ func (tx *Transaction) ReIndexAll() error {
 	return nil
 }
 
+// CreateView creates a view with the given configuration.
+// If a view or a table with the same name already exists, it returns
+// ErrViewAlreadyExists or ErrTableAlreadyExists.
+// The documents of a materialized view are stored in a table with the same name,
+// which is filled by running the statement of the view.
+func (tx *Transaction) CreateView(cfg ViewConfig) error {
+	if strings.HasPrefix(cfg.ViewName, internalPrefix) {
+		return fmt.Errorf("view name must not start with %s", internalPrefix)
+	}
+
+	_, err := tx.tableInfoStore.Get(tx, cfg.ViewName)
+	if err == nil {
+		return ErrTableAlreadyExists
+	}
+	if !errors.Is(err, ErrTableNotFound) {
+		return err
+	}
+
+	if cfg.Materialized {
+		if tx.db.ViewMaintainer == nil {
+			return errors.New("materialized views are not supported by this database")
+		}
+
+		cfg.SourceTable, err = tx.db.ViewMaintainer.SourceTable(tx, &cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx.schemaChanged = true
+	tx.viewDeps = nil
+	err = tx.viewStore.Insert(cfg)
+	if err != nil || !cfg.Materialized {
+		return err
+	}
+
+	err = tx.createTable(cfg.ViewName, &TableInfo{materializedView: true})
+	if err != nil {
+		return err
+	}
+
+	return tx.RefreshView(cfg.ViewName)
+}
+
+// GetView returns the configuration of a view by name.
+func (tx *Transaction) GetView(name string) (*ViewConfig, error) {
+	return tx.viewStore.Get(name)
+}
+
+// DropView deletes a view from the database.
+// The table storing the documents of a materialized view is deleted as well.
+func (tx *Transaction) DropView(name string) error {
+	cfg, err := tx.viewStore.Get(name)
+	if err != nil {
+		return err
+	}
+
+	err = tx.checkDependentViews(name)
+	if err != nil {
+		return err
+	}
+
+	err = tx.viewStore.Delete(name)
+	if err != nil {
+		return err
+	}
+
+	tx.schemaChanged = true
+	tx.viewDeps = nil
+	if !cfg.Materialized {
+		return nil
+	}
+
+	ti, err := tx.tableInfoStore.Get(tx, name)
+	if err != nil {
+		return err
+	}
+
+	return tx.dropTable(name, ti)
+}
+
+// ListViews lists all views.
+func (tx *Transaction) ListViews() ([]*ViewConfig, error) {
+	return tx.viewStore.ListAll()
+}
+
 func (tx *Transaction) getIndexStore() (*indexStore, error) {
 	st, err := tx.tx.GetStore([]byte(indexStoreName))
 	if err != nil {
@@ -384,3 +550,14 @@ func (tx *Transaction) getIndexStore() (*indexStore, error) {
 		db: tx.db,
 	}, nil
 }
+
+func (tx *Transaction) getViewStore() (*viewStore, error) {
+	st, err := tx.tx.GetStore([]byte(viewStoreName))
+	if err != nil {
+		return nil, err
+	}
+	return &viewStore{
+		st: st,
+		db: tx.db,
+	}, nil
+}
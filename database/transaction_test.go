@@ -471,3 +471,99 @@ func TestReIndexAll(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestTxSchemaVersion(t *testing.T) {
+	db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+		Codec: msgpack.NewCodec(),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	version := db.SchemaVersion()
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+	require.NoError(t, tx.CreateTable("test", nil))
+	require.True(t, tx.SchemaChanged())
+	require.NoError(t, tx.Commit())
+	require.Equal(t, version+1, db.SchemaVersion())
+
+	// writing documents doesn't change the schema.
+	tx, err = db.Begin(true)
+	require.NoError(t, err)
+	tb, err := tx.GetTable("test")
+	require.NoError(t, err)
+	_, err = tb.Insert(document.NewFieldBuffer().Add("a", document.NewIntegerValue(1)))
+	require.NoError(t, err)
+	require.False(t, tx.SchemaChanged())
+	require.NoError(t, tx.Commit())
+	require.Equal(t, version+1, db.SchemaVersion())
+
+	// rolled back changes also change the version, since plans
+	// may have been computed by the transaction using them.
+	tx, err = db.Begin(true)
+	require.NoError(t, err)
+	require.NoError(t, tx.CreateIndex(database.IndexConfig{
+		IndexName: "idx_test_a",
+		TableName: "test",
+		Path:      parsePath(t, "a"),
+	}))
+	require.True(t, tx.SchemaChanged())
+	require.NoError(t, tx.Rollback())
+	require.False(t, tx.SchemaChanged())
+	require.Equal(t, version+2, db.SchemaVersion())
+}
+
+func TestTxView(t *testing.T) {
+	t.Run("Create", func(t *testing.T) {
+		tx, cleanup := newTestDB(t)
+		defer cleanup()
+
+		err := tx.CreateTable("test", nil)
+		require.NoError(t, err)
+
+		err = tx.CreateView(database.ViewConfig{ViewName: "v", Query: "SELECT * FROM test"})
+		require.NoError(t, err)
+
+		cfg, err := tx.GetView("v")
+		require.NoError(t, err)
+		require.Equal(t, &database.ViewConfig{ViewName: "v", Query: "SELECT * FROM test"}, cfg)
+
+		// names are shared by tables and views.
+		err = tx.CreateView(database.ViewConfig{ViewName: "v", Query: "SELECT 1"})
+		require.Equal(t, database.ErrViewAlreadyExists, err)
+		err = tx.CreateView(database.ViewConfig{ViewName: "test", Query: "SELECT 1"})
+		require.Equal(t, database.ErrTableAlreadyExists, err)
+		err = tx.CreateTable("v", nil)
+		require.Equal(t, database.ErrViewAlreadyExists, err)
+	})
+
+	t.Run("Materialized without maintainer", func(t *testing.T) {
+		tx, cleanup := newTestDB(t)
+		defer cleanup()
+
+		err := tx.CreateView(database.ViewConfig{ViewName: "v", Query: "SELECT 1", Materialized: true})
+		require.Error(t, err)
+
+		_, err = tx.GetView("v")
+		require.Equal(t, database.ErrViewNotFound, err)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		tx, cleanup := newTestDB(t)
+		defer cleanup()
+
+		err := tx.CreateView(database.ViewConfig{ViewName: "v", Query: "SELECT 1"})
+		require.NoError(t, err)
+
+		err = tx.DropView("v")
+		require.NoError(t, err)
+
+		err = tx.DropView("v")
+		require.Equal(t, database.ErrViewNotFound, err)
+
+		views, err := tx.ListViews()
+		require.NoError(t, err)
+		require.Empty(t, views)
+	})
+}
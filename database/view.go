@@ -0,0 +1,257 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A ViewMaintainer computes the documents of the materialized views.
+// The database doesn't know how to run the statement of a view, it relies
+// on the ViewMaintainer provided by the SQL layer.
+type ViewMaintainer interface {
+	// SourceTable returns the name of the table the view can be incrementally
+	// maintained from, or an empty string if the view can only be refreshed.
+	SourceTable(tx *Transaction, cfg *ViewConfig) (string, error)
+
+	// Refresh replaces all the documents of the view.
+	Refresh(tx *Transaction, cfg *ViewConfig, v *MaterializedView) error
+
+	// Update updates the documents of the view after a document of its source table
+	// was modified. old is nil if the document was inserted and d is nil if it was deleted.
+	Update(tx *Transaction, cfg *ViewConfig, v *MaterializedView, old, d document.Document) error
+}
+
+// A MaterializedView gives the ViewMaintainer access to the table
+// storing the documents of a materialized view.
+type MaterializedView struct {
+	t *Table
+}
+
+// Iterate goes through all the documents of the view.
+func (v *MaterializedView) Iterate(fn func(d document.Document) error) error {
+	return v.t.Iterate(fn)
+}
+
+// Get returns the document of the view stored under the given key.
+// If there is no such document, it returns ErrDocumentNotFound.
+func (v *MaterializedView) Get(key []byte) (document.Document, error) {
+	return v.t.GetDocument(key)
+}
+
+// Insert a document into the view, under a generated key.
+func (v *MaterializedView) Insert(d document.Document) error {
+	info, err := v.t.Info()
+	if err != nil {
+		return err
+	}
+
+	_, err = v.t.insert(info, d)
+	return err
+}
+
+// Put stores a document in the view under the given key,
+// replacing the document stored under that key, if any.
+func (v *MaterializedView) Put(key []byte, d document.Document) error {
+	info, err := v.t.Info()
+	if err != nil {
+		return err
+	}
+
+	d, err = info.FieldConstraints.ValidateDocument(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = v.t.GetDocument(key)
+	if err == ErrDocumentNotFound {
+		return v.t.insertWithKey(key, d)
+	}
+	if err != nil {
+		return err
+	}
+
+	indexes, err := v.t.Indexes()
+	if err != nil {
+		return err
+	}
+
+	return v.t.replace(indexes, key, d)
+}
+
+// Delete a document of the view by key.
+func (v *MaterializedView) Delete(key []byte) error {
+	return v.t.delete(key)
+}
+
+// DeleteAll deletes all the documents of the view.
+func (v *MaterializedView) DeleteAll() error {
+	var keys [][]byte
+	err := v.t.Iterate(func(d document.Document) error {
+		keys = append(keys, append([]byte(nil), d.(document.Keyer).Key()...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err = v.t.delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefreshView replaces the documents of a materialized view by the
+// documents currently returned by its statement.
+func (tx *Transaction) RefreshView(name string) error {
+	cfg, err := tx.GetView(name)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Materialized {
+		return fmt.Errorf("%q is not a materialized view", name)
+	}
+
+	if tx.db.ViewMaintainer == nil {
+		return errors.New("materialized views are not supported by this database")
+	}
+
+	t, err := tx.GetTable(name)
+	if err != nil {
+		return err
+	}
+
+	return tx.db.ViewMaintainer.Refresh(tx, cfg, &MaterializedView{t: t})
+}
+
+// viewDependencies lists the views reading each table or view,
+// and the materialized views maintained from the documents of each table.
+type viewDependencies struct {
+	// schema version the dependencies were loaded at.
+	version int64
+	readers map[string][]*ViewConfig
+	tables  map[string][]*ViewConfig
+}
+
+// viewDependencies returns the dependencies between the tables and the materialized views.
+// They are loaded once per schema version and shared by the transactions, except for
+// the transactions modifying the views, which load their own.
+func (tx *Transaction) viewDependencies() (*viewDependencies, error) {
+	if tx.viewDeps != nil {
+		return tx.viewDeps, nil
+	}
+
+	if !tx.schemaChanged {
+		tx.db.viewDepsMu.Lock()
+		deps := tx.db.viewDeps
+		tx.db.viewDepsMu.Unlock()
+
+		if deps != nil && deps.version == tx.schemaVersion {
+			tx.viewDeps = deps
+			return deps, nil
+		}
+	}
+
+	views, err := tx.ListViews()
+	if err != nil {
+		return nil, err
+	}
+
+	deps := viewDependencies{
+		version: tx.schemaVersion,
+		readers: make(map[string][]*ViewConfig),
+		tables:  make(map[string][]*ViewConfig),
+	}
+	for _, cfg := range views {
+		for _, name := range cfg.Dependencies {
+			deps.readers[name] = append(deps.readers[name], cfg)
+		}
+		if cfg.Materialized && cfg.SourceTable != "" {
+			deps.tables[cfg.SourceTable] = append(deps.tables[cfg.SourceTable], cfg)
+		}
+	}
+
+	tx.viewDeps = &deps
+	if !tx.schemaChanged {
+		tx.db.viewDepsMu.Lock()
+		if tx.db.viewDeps == nil || tx.db.viewDeps.version < deps.version {
+			tx.db.viewDeps = &deps
+		}
+		tx.db.viewDepsMu.Unlock()
+	}
+
+	return &deps, nil
+}
+
+// checkDependentViews returns an error if views read the given table or view,
+// or if materialized views are maintained from the documents of the given table.
+func (tx *Transaction) checkDependentViews(name string) error {
+	deps, err := tx.viewDependencies()
+	if err != nil {
+		return err
+	}
+
+	if views := deps.readers[name]; len(views) > 0 {
+		return fmt.Errorf("view %q depends on %q", views[0].ViewName, name)
+	}
+
+	if views := deps.tables[name]; len(views) > 0 {
+		return fmt.Errorf("materialized view %q depends on table %q", views[0].ViewName, name)
+	}
+
+	return nil
+}
+
+// viewError returns a ViewNotWritableError if err is ErrTableNotFound
+// and name refers to a view, otherwise it returns err.
+func (tx *Transaction) viewError(name string, err error) error {
+	if !errors.Is(err, ErrTableNotFound) {
+		return err
+	}
+
+	_, verr := tx.viewStore.Get(name)
+	if verr != nil {
+		return err
+	}
+
+	return &ViewNotWritableError{ViewName: name}
+}
+
+// materializedViews returns the materialized views that must
+// be updated when the documents of the table are modified.
+func (t *Table) materializedViews() ([]*ViewConfig, error) {
+	if t.tx.db.ViewMaintainer == nil {
+		return nil, nil
+	}
+
+	deps, err := t.tx.viewDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	return deps.tables[t.name], nil
+}
+
+// updateViews updates the given materialized views after a document
+// of the table was modified.
+func (t *Table) updateViews(views []*ViewConfig, old, d document.Document) error {
+	for _, cfg := range views {
+		vt, err := t.tx.GetTable(cfg.ViewName)
+		if err != nil {
+			return err
+		}
+
+		err = t.tx.db.ViewMaintainer.Update(t.tx, cfg, &MaterializedView{t: vt}, old, d)
+		if err != nil {
+			return fmt.Errorf("failed to update materialized view %q: %w", cfg.ViewName, err)
+		}
+	}
+
+	return nil
+}
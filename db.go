@@ -2,11 +2,14 @@ package genji
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/parser"
 	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
 )
 
 // DB represents a collection of tables stored in the underlying engine.
@@ -14,14 +17,78 @@ type DB struct {
 	DB *database.Database
 
 	ctx context.Context
+
+	// functions that can be called by the queries,
+	// including the user-defined ones.
+	functions *expr.Functions
 }
 
 // WithContext creates a new database handle using the given context for every operation.
 func (db *DB) WithContext(ctx context.Context) *DB {
 	return &DB{
-		DB:  db.DB,
-		ctx: ctx,
+		DB:        db.DB,
+		ctx:       ctx,
+		functions: db.functions,
+	}
+}
+
+// RegisterFunc registers a scalar function that can be called by the queries run on the database,
+// within its transactions or through database/sql connections created by driver.NewConnector.
+// fn must be a Go function whose parameters are of type document.Value, the last one being
+// optionally variadic, and that returns a document.Value and optionally an error, e.g.
+//
+//	func(a, b document.Value) (document.Value, error)
+//
+// Calls with the wrong number of arguments are rejected when the query is parsed.
+// NULL arguments are passed to fn as is.
+// Functions must be registered before the database is used by other goroutines.
+func (db *DB) RegisterFunc(name string, fn interface{}) error {
+	builder, err := expr.NewUserFunc(name, fn)
+	if err != nil {
+		return err
+	}
+
+	return db.addFunc(name, builder)
+}
+
+// RegisterAggregate registers an aggregate function that can be called by the queries
+// run on the database, like the builtin aggregate functions such as COUNT or SUM.
+// The factory creates one aggregator per group of documents. See expr.AggregatorFactory
+// for the requirements of the aggregators.
+// Functions must be registered before the database is used by other goroutines.
+func (db *DB) RegisterAggregate(name string, factory expr.AggregatorFactory) error {
+	return db.addFunc(name, expr.NewUserAggregate(name, factory))
+}
+
+// addFunc adds a function, unless a function with the same name already exists.
+func (db *DB) addFunc(name string, builder func(args ...expr.Expr) (expr.Expr, error)) error {
+	if db.functions == nil {
+		functions := expr.NewFunctions()
+		db.functions = &functions
+	}
+
+	if db.functions.HasFunc(name) {
+		return fmt.Errorf("function %q already exists", name)
 	}
+
+	db.functions.AddFunc(name, builder)
+	return nil
+}
+
+// ParseQuery parses the query, which can call the functions registered
+// using RegisterFunc and RegisterAggregate.
+func (db *DB) ParseQuery(q string) (query.Query, error) {
+	return parseQuery(q, db.functions)
+}
+
+// parseQuery parses the query using the given functions,
+// or the builtin functions if functions is nil.
+func parseQuery(q string, functions *expr.Functions) (query.Query, error) {
+	if functions == nil {
+		return parser.ParseQuery(q)
+	}
+
+	return parser.NewParserWithOptions(strings.NewReader(q), &parser.Options{Functions: *functions}).ParseQuery()
 }
 
 // Close the database.
@@ -41,6 +108,7 @@ func (db *DB) Begin(writable bool) (*Tx, error) {
 
 	return &Tx{
 		Transaction: tx,
+		functions:   db.functions,
 	}, nil
 }
 
@@ -84,7 +152,7 @@ func (db *DB) Exec(q string, args ...interface{}) error {
 // Query the database and return the result.
 // The returned result must always be closed after usage.
 func (db *DB) Query(q string, args ...interface{}) (*query.Result, error) {
-	pq, err := parser.ParseQuery(q)
+	pq, err := db.ParseQuery(q)
 	if err != nil {
 		return nil, err
 	}
@@ -125,12 +193,14 @@ func (db *DB) QueryDocument(q string, args ...interface{}) (document.Document, e
 // and read/write can be used to read, create, delete and modify tables.
 type Tx struct {
 	*database.Transaction
+
+	functions *expr.Functions
 }
 
 // Query the database withing the transaction and returns the result.
 // Closing the returned result after usage is not mandatory.
 func (tx *Tx) Query(q string, args ...interface{}) (*query.Result, error) {
-	pq, err := parser.ParseQuery(q)
+	pq, err := parseQuery(q, tx.functions)
 	if err != nil {
 		return nil, err
 	}
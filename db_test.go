@@ -1,13 +1,17 @@
 package genji_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/stretchr/testify/require"
 )
 
@@ -116,3 +120,255 @@ func TestQueryDocument(t *testing.T) {
 		require.Nil(t, r)
 	})
 }
+
+func TestPrepare(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+
+	insert, err := db.Prepare("INSERT INTO test (a, b) VALUES (?, ?)")
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, insert.Exec(i, fmt.Sprintf("foo%d", i)))
+	}
+
+	sel, err := db.Prepare("SELECT b FROM test WHERE a = ?")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, sel.Columns())
+
+	requireB := func(t *testing.T, st *genji.Statement, a int, expected string) {
+		t.Helper()
+
+		d, err := st.QueryDocument(a)
+		require.NoError(t, err)
+		var b string
+		require.NoError(t, document.Scan(d, &b))
+		require.Equal(t, expected, b)
+	}
+
+	t.Run("Params", func(t *testing.T) {
+		requireB(t, sel, 1, "foo1")
+		requireB(t, sel, 3, "foo3")
+
+		_, err := sel.QueryDocument(10)
+		require.Equal(t, database.ErrDocumentNotFound, err)
+	})
+
+	t.Run("Schema changes", func(t *testing.T) {
+		// the plan must use the new index, then stop using it once it is dropped.
+		require.NoError(t, db.Exec("CREATE INDEX idx_test_a ON test(a); REINDEX idx_test_a"))
+		requireB(t, sel, 2, "foo2")
+		requireB(t, sel, 3, "foo3")
+
+		require.NoError(t, db.Exec("DROP INDEX idx_test_a"))
+		requireB(t, sel, 2, "foo2")
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		err := db.Update(func(tx *genji.Tx) error {
+			st, err := tx.Prepare("SELECT b FROM test WHERE a = ?")
+			require.NoError(t, err)
+			requireB(t, st, 1, "foo1")
+
+			// schema changes made by the transaction
+			// are taken into account before it is committed.
+			err = tx.Exec("CREATE INDEX idx_test_a ON test(a); REINDEX idx_test_a")
+			require.NoError(t, err)
+			requireB(t, st, 2, "foo2")
+
+			err = tx.Exec("DROP INDEX idx_test_a")
+			require.NoError(t, err)
+			requireB(t, st, 3, "foo3")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Subqueries", func(t *testing.T) {
+		err := db.Update(func(tx *genji.Tx) error {
+			st, err := tx.Prepare("SELECT COUNT(*) AS n, (SELECT MAX(a) FROM test WHERE a < ?) AS m FROM test WHERE a IN (SELECT a FROM test)")
+			require.NoError(t, err)
+
+			requireNM := func(arg, n, m int) {
+				t.Helper()
+
+				d, err := st.QueryDocument(arg)
+				require.NoError(t, err)
+				var rn, rm int
+				require.NoError(t, document.Scan(d, &rn, &rm))
+				require.Equal(t, n, rn)
+				require.Equal(t, m, rm)
+			}
+
+			// the subqueries must see the new document and use the new parameter.
+			requireNM(3, 3, 2)
+			err = tx.Exec("INSERT INTO test (a, b) VALUES (4, 'foo4')")
+			require.NoError(t, err)
+			requireNM(10, 4, 4)
+			requireNM(2, 4, 1)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := db.Prepare("SELECT FROM")
+		require.Error(t, err)
+
+		st, err := db.Prepare("SELECT * FROM unknown")
+		require.NoError(t, err)
+		err = st.Exec()
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+	})
+}
+
+func TestRegisterFunc(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.RegisterFunc("twice", func(v document.Value) (document.Value, error) {
+		if !v.Type.IsNumber() {
+			return document.Value{}, fmt.Errorf("expected number, got %s", v.Type)
+		}
+		i, err := v.CastAsInteger()
+		if err != nil {
+			return document.Value{}, err
+		}
+		return document.NewIntegerValue(i.V.(int64) * 2), nil
+	})
+	require.NoError(t, err)
+
+	err = db.RegisterFunc("Implode", func(sep document.Value, values ...document.Value) document.Value {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = fmt.Sprintf("%v", v)
+		}
+		return document.NewTextValue(strings.Join(parts, sep.V.(string)))
+	})
+	require.NoError(t, err)
+
+	err = db.Exec("CREATE TABLE test; INSERT INTO test (a) VALUES (1), (2)")
+	require.NoError(t, err)
+
+	t.Run("DB", func(t *testing.T) {
+		d, err := db.QueryDocument("SELECT TWICE(a) AS d, implode('-', a, 'x') AS j FROM test WHERE twice(a) > 2")
+		require.NoError(t, err)
+
+		var dv int
+		var jv string
+		require.NoError(t, document.Scan(d, &dv, &jv))
+		require.Equal(t, 4, dv)
+		require.Equal(t, `2-"x"`, jv)
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		err := db.View(func(tx *genji.Tx) error {
+			d, err := tx.QueryDocument("SELECT twice(a) AS d FROM test")
+			if err != nil {
+				return err
+			}
+
+			v, err := d.GetByField("d")
+			require.NoError(t, err)
+			require.Equal(t, document.NewIntegerValue(2), v)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := db.Query("SELECT twice(a, 2) FROM test")
+		require.EqualError(t, err, "TWICE() takes 1 argument")
+
+		_, err = db.QueryDocument("SELECT twice('a')")
+		require.EqualError(t, err, "TWICE(): expected number, got text")
+
+		require.Error(t, db.RegisterFunc("TWICE", func() document.Value { return document.Value{} }))
+		require.Error(t, db.RegisterFunc("lower", func() document.Value { return document.Value{} }))
+		require.Error(t, db.RegisterFunc("f", func(a int) document.Value { return document.Value{} }))
+		require.Error(t, db.RegisterFunc("f", func(a document.Value) error { return nil }))
+		require.Error(t, db.RegisterFunc("f", 10))
+	})
+}
+
+// productAggregator multiplies the values of an expression.
+type productAggregator struct {
+	e       expr.Expr
+	product float64
+}
+
+func (p *productAggregator) Add(d document.Document) error {
+	v, err := p.e.Eval(expr.EvalStack{Document: d})
+	if err != nil {
+		return err
+	}
+	if v.Type.IsNumber() {
+		v, err = v.CastAsDouble()
+		if err != nil {
+			return err
+		}
+		p.product *= v.V.(float64)
+	}
+
+	return nil
+}
+
+func (p *productAggregator) Aggregate(fb *document.FieldBuffer) error {
+	fb.Add("product", document.NewDoubleValue(p.product))
+	return nil
+}
+
+func TestRegisterAggregate(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.RegisterAggregate("product", func(args ...expr.Expr) (document.Aggregator, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("takes 1 argument")
+		}
+		return &productAggregator{e: args[0], product: 1}, nil
+	})
+	require.NoError(t, err)
+
+	err = db.Exec("CREATE TABLE test; INSERT INTO test (a, b) VALUES (1, 2), (1, 3), (2, 2), (2, 2), (2, 5)")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Aggregate", "SELECT PRODUCT(b) FROM test", `[{"PRODUCT(b)": 120.0}]`},
+		{"Group by", "SELECT a, product(b) AS p FROM test GROUP BY a", `[{"a": 1, "p": 6.0}, {"a": 2, "p": 20.0}]`},
+		{"Distinct", "SELECT product(DISTINCT b) AS p FROM test GROUP BY a", `[{"p": 6.0}, {"p": 10.0}]`},
+		{"Having", "SELECT a FROM test GROUP BY a HAVING product(b) > 10", `[{"a": 2}]`},
+		{"Window", "SELECT product(b) OVER (PARTITION BY a) AS p FROM test WHERE a = 1", `[{"p": 6.0}, {"p": 6.0}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := db.Query(test.query)
+			require.NoError(t, err)
+			defer res.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, res)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := db.Query("SELECT product(a, b) FROM test")
+		require.EqualError(t, err, "PRODUCT(): takes 1 argument")
+
+		require.Error(t, db.RegisterAggregate("count", func(args ...expr.Expr) (document.Aggregator, error) {
+			return nil, nil
+		}))
+	})
+}
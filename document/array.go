@@ -260,12 +260,13 @@ func (a sortableArray) Len() int {
 func (a *sortableArray) Swap(i, j int) { a.vb[i], a.vb[j] = a.vb[j], a.vb[i] }
 
 var typeSortOrder = map[ValueType]int{
-	NullValue:     0,
-	BoolValue:     1,
-	DoubleValue:   2,
-	TextValue:     3,
-	ArrayValue:    4,
-	DocumentValue: 5,
+	NullValue:      0,
+	BoolValue:      1,
+	DoubleValue:    2,
+	TimestampValue: 3,
+	TextValue:      4,
+	ArrayValue:     5,
+	DocumentValue:  6,
 }
 
 func (a *sortableArray) Less(i, j int) (ok bool) {
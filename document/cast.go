@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // CastAs casts v as the selected type when possible.
@@ -24,6 +25,10 @@ func (v Value) CastAs(t ValueType) (Value, error) {
 		return v.CastAsInteger()
 	case DoubleValue:
 		return v.CastAsDouble()
+	case DecimalValue:
+		return v.CastAsDecimal()
+	case TimestampValue:
+		return v.CastAsTimestamp()
 	case BlobValue:
 		return v.CastAsBlob()
 	case TextValue:
@@ -39,6 +44,7 @@ func (v Value) CastAs(t ValueType) (Value, error) {
 
 // CastAsBool casts according to the following rules:
 // Integer: true if truthy, otherwise false.
+// Decimal: true if not zero, otherwise false.
 // Text: uses strconv.Parsebool to determine the boolean value,
 // it fails if the text doesn't contain a valid boolean.
 // Any other type is considered an invalid cast.
@@ -48,6 +54,8 @@ func (v Value) CastAsBool() (Value, error) {
 		return v, nil
 	case IntegerValue:
 		return NewBoolValue(v.V.(int64) != 0), nil
+	case DecimalValue:
+		return NewBoolValue(v.V.(Decimal).Sign() != 0), nil
 	case TextValue:
 		b, err := strconv.ParseBool(v.V.(string))
 		if err != nil {
@@ -62,6 +70,7 @@ func (v Value) CastAsBool() (Value, error) {
 // CastAsInteger casts according to the following rules:
 // Bool: returns 1 if true, 0 if false.
 // Double: cuts off the decimal and remaining numbers.
+// Decimal: cuts off the fractional part, it fails if the result overflows.
 // Text: uses strconv.ParseInt to determine the integer value,
 // then casts it to an integer. If it fails uses strconv.ParseFloat
 // to determine the double value, then casts it to an integer
@@ -78,6 +87,12 @@ func (v Value) CastAsInteger() (Value, error) {
 		return NewIntegerValue(0), nil
 	case DoubleValue:
 		return NewIntegerValue(int64(v.V.(float64))), nil
+	case DecimalValue:
+		i, err := v.V.(Decimal).Int64()
+		if err != nil {
+			return Value{}, err
+		}
+		return NewIntegerValue(i), nil
 	case TextValue:
 		i, err := strconv.ParseInt(v.V.(string), 10, 64)
 		if err != nil {
@@ -96,6 +111,7 @@ func (v Value) CastAsInteger() (Value, error) {
 
 // CastAsDouble casts according to the following rules:
 // Integer: returns a double version of the integer.
+// Decimal: returns the nearest double.
 // Text: uses strconv.ParseFloat to determine the double value,
 // it fails if the text doesn't contain a valid float value.
 // Any other type is considered an invalid cast.
@@ -105,6 +121,8 @@ func (v Value) CastAsDouble() (Value, error) {
 		return v, nil
 	case IntegerValue:
 		return NewDoubleValue(float64(v.V.(int64))), nil
+	case DecimalValue:
+		return NewDoubleValue(v.V.(Decimal).Float64()), nil
 	case TextValue:
 		f, err := strconv.ParseFloat(v.V.(string), 64)
 		if err != nil {
@@ -116,6 +134,68 @@ func (v Value) CastAsDouble() (Value, error) {
 	return Value{}, fmt.Errorf("cannot cast %s as double", v.Type)
 }
 
+// CastAsDecimal casts according to the following rules:
+// Integer: returns a decimal with a scale of 0.
+// Double: returns the decimal with the shortest representation
+// that converts back to the double. It fails if the double is not finite.
+// Text: parses a decimal number, it fails if the text doesn't contain a valid number.
+// Any other type is considered an invalid cast.
+func (v Value) CastAsDecimal() (Value, error) {
+	switch v.Type {
+	case DecimalValue:
+		return v, nil
+	case IntegerValue:
+		return NewDecimalValue(NewDecimal(v.V.(int64), 0)), nil
+	case DoubleValue:
+		d, err := DecimalFromFloat64(v.V.(float64))
+		if err != nil {
+			return Value{}, err
+		}
+		return NewDecimalValue(d), nil
+	case TextValue:
+		d, err := ParseDecimal(v.V.(string))
+		if err != nil {
+			return Value{}, fmt.Errorf(`cannot cast %q as decimal: %w`, v.V, err)
+		}
+		return NewDecimalValue(d), nil
+	}
+
+	return Value{}, fmt.Errorf("cannot cast %s as decimal", v.Type)
+}
+
+// timestampLayouts lists the formats accepted when casting a text to a timestamp.
+// Texts without time zone are considered to be in UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// CastAsTimestamp casts according to the following rules:
+// Text: parses an RFC 3339 date, with or without time zone,
+// or a date of the form YYYY-MM-DD. Texts without time zone are considered to be in UTC.
+// Any other type is considered an invalid cast.
+func (v Value) CastAsTimestamp() (Value, error) {
+	switch v.Type {
+	case TimestampValue:
+		return v, nil
+	case TextValue:
+		s := v.V.(string)
+		for _, layout := range timestampLayouts {
+			t, err := time.Parse(layout, s)
+			if err == nil {
+				return NewTimestampValue(t), nil
+			}
+		}
+
+		return Value{}, fmt.Errorf(`cannot cast %q as timestamp`, s)
+	}
+
+	return Value{}, fmt.Errorf("cannot cast %s as timestamp", v.Type)
+}
+
 // CastAsText returns a JSON representation of v.
 // If the representation is a string, it gets unquoted.
 func (v Value) CastAsText() (Value, error) {
@@ -130,7 +210,7 @@ func (v Value) CastAsText() (Value, error) {
 
 	s := string(d)
 
-	if v.Type == BlobValue {
+	if v.Type == BlobValue || v.Type == TimestampValue {
 		s, err = strconv.Unquote(s)
 		if err != nil {
 			return Value{}, err
@@ -1,7 +1,9 @@
 package document
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -17,6 +19,8 @@ func TestCastAs(t *testing.T) {
 	doubleV := NewDoubleValue(10.5)
 	textV := NewTextValue("foo")
 	blobV := NewBlobValue([]byte("abc"))
+	decimalV := NewDecimalValue(NewDecimal(1050, 2))
+	timestampV := NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 123456000, time.UTC))
 	arrayV := NewArrayValue(NewValueBuffer().
 		Append(NewTextValue("bar")).
 		Append(integerV))
@@ -43,6 +47,7 @@ func TestCastAs(t *testing.T) {
 			{boolV, boolV, false},
 			{integerV, boolV, false},
 			{NewIntegerValue(0), NewBoolValue(false), false},
+			{decimalV, boolV, false},
 			{doubleV, Value{}, true},
 			{textV, Value{}, true},
 			{NewTextValue("true"), boolV, false},
@@ -59,6 +64,7 @@ func TestCastAs(t *testing.T) {
 			{NewBoolValue(false), NewIntegerValue(0), false},
 			{integerV, integerV, false},
 			{doubleV, integerV, false},
+			{decimalV, integerV, false},
 			{textV, Value{}, true},
 			{NewTextValue("10"), integerV, false},
 			{NewTextValue("10.5"), integerV, false},
@@ -73,6 +79,7 @@ func TestCastAs(t *testing.T) {
 			{boolV, Value{}, true},
 			{integerV, NewDoubleValue(10), false},
 			{doubleV, doubleV, false},
+			{decimalV, doubleV, false},
 			{textV, Value{}, true},
 			{NewTextValue("10"), NewDoubleValue(10), false},
 			{NewTextValue("10.5"), doubleV, false},
@@ -82,13 +89,32 @@ func TestCastAs(t *testing.T) {
 		})
 	})
 
+	t.Run("decimal", func(t *testing.T) {
+		check(t, DecimalValue, []test{
+			{boolV, Value{}, true},
+			{integerV, NewDecimalValue(NewDecimal(10, 0)), false},
+			{doubleV, NewDecimalValue(NewDecimal(105, 1)), false},
+			{decimalV, decimalV, false},
+			{textV, Value{}, true},
+			{NewTextValue("10.50"), decimalV, false},
+			{NewTextValue("1.05e1"), NewDecimalValue(NewDecimal(105, 1)), false},
+			{NewTextValue("1.2.3"), Value{}, true},
+			{NewDoubleValue(math.Inf(1)), Value{}, true},
+			{blobV, Value{}, true},
+			{arrayV, Value{}, true},
+			{docV, Value{}, true},
+		})
+	})
+
 	t.Run("text", func(t *testing.T) {
 		check(t, TextValue, []test{
 			{boolV, NewTextValue("true"), false},
 			{integerV, NewTextValue("10"), false},
 			{doubleV, NewTextValue("10.5"), false},
+			{decimalV, NewTextValue("10.50"), false},
 			{textV, textV, false},
 			{blobV, NewTextValue("YWJj"), false},
+			{timestampV, NewTextValue("2020-11-02T10:30:00.123456Z"), false},
 			{arrayV, NewTextValue(`["bar", 10]`), false},
 			{docV,
 				NewTextValue(`{"a": 10, "b": "foo"}`),
@@ -96,6 +122,23 @@ func TestCastAs(t *testing.T) {
 		})
 	})
 
+	t.Run("timestamp", func(t *testing.T) {
+		check(t, TimestampValue, []test{
+			{boolV, Value{}, true},
+			{integerV, Value{}, true},
+			{doubleV, Value{}, true},
+			{textV, Value{}, true},
+			{NewTextValue("2020-11-02T10:30:00.123456Z"), timestampV, false},
+			{NewTextValue("2020-11-02T12:30:00.123456+02:00"), timestampV, false},
+			{NewTextValue("2020-11-02 10:30:00.123456"), timestampV, false},
+			{NewTextValue("2020-11-02"), NewTimestampValue(time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)), false},
+			{timestampV, timestampV, false},
+			{blobV, Value{}, true},
+			{arrayV, Value{}, true},
+			{docV, Value{}, true},
+		})
+	})
+
 	t.Run("blob", func(t *testing.T) {
 		check(t, BlobValue, []test{
 			{boolV, Value{}, true},
@@ -3,6 +3,7 @@ package document
 import (
 	"bytes"
 	"strings"
+	"time"
 )
 
 type operator uint8
@@ -93,6 +94,23 @@ func compare(op operator, l, r Value) (bool, error) {
 	case l.Type.IsNumber() && r.Type.IsNumber():
 		return compareNumbers(op, l, r)
 
+	// compare timestamps together
+	case l.Type == TimestampValue && r.Type == TimestampValue:
+		return compareTimestamps(op, l.V.(time.Time), r.V.(time.Time)), nil
+
+	// compare timestamps with texts, if the text represents a timestamp
+	case l.Type == TimestampValue && r.Type == TextValue,
+		l.Type == TextValue && r.Type == TimestampValue:
+		lt, err := l.CastAsTimestamp()
+		if err != nil {
+			return false, nil
+		}
+		rt, err := r.CastAsTimestamp()
+		if err != nil {
+			return false, nil
+		}
+		return compareTimestamps(op, lt.V.(time.Time), rt.V.(time.Time)), nil
+
 	// compare arrays together
 	case l.Type == ArrayValue && r.Type == ArrayValue:
 		return compareArrays(op, l.V.(Array), r.V.(Array))
@@ -184,9 +202,59 @@ func compareIntegers(op operator, l, r int64) bool {
 	return false
 }
 
+func compareTimestamps(op operator, l, r time.Time) bool {
+	switch op {
+	case operatorEq:
+		return l.Equal(r)
+	case operatorGt:
+		return l.After(r)
+	case operatorGte:
+		return !l.Before(r)
+	case operatorLt:
+		return l.Before(r)
+	case operatorLte:
+		return !l.After(r)
+	}
+
+	return false
+}
+
+func compareDecimals(op operator, l, r Decimal) bool {
+	c := l.Cmp(r)
+
+	switch op {
+	case operatorEq:
+		return c == 0
+	case operatorGt:
+		return c > 0
+	case operatorGte:
+		return c >= 0
+	case operatorLt:
+		return c < 0
+	case operatorLte:
+		return c <= 0
+	}
+
+	return false
+}
+
 func compareNumbers(op operator, l, r Value) (bool, error) {
 	var err error
 
+	// decimals are compared exactly, unless a double is involved.
+	if l.Type != DoubleValue && r.Type != DoubleValue {
+		l, err = l.CastAsDecimal()
+		if err != nil {
+			return false, err
+		}
+		r, err = r.CastAsDecimal()
+		if err != nil {
+			return false, err
+		}
+
+		return compareDecimals(op, l.V.(Decimal), r.V.(Decimal)), nil
+	}
+
 	l, err = l.CastAsDouble()
 	if err != nil {
 		return false, err
@@ -33,10 +33,24 @@ func jsonToBoolean(t testing.TB, x string) document.Value {
 	return document.NewBoolValue(b)
 }
 
+func toDecimal(t testing.TB, x string) document.Value {
+	d, err := document.ParseDecimal(x)
+	require.NoError(t, err)
+
+	return document.NewDecimalValue(d)
+}
+
 func toText(t testing.TB, x string) document.Value {
 	return document.NewTextValue(x)
 }
 
+func toTimestamp(t testing.TB, x string) document.Value {
+	v, err := document.NewTextValue(x).CastAsTimestamp()
+	require.NoError(t, err)
+
+	return v
+}
+
 func toBlob(t testing.TB, x string) document.Value {
 	return document.NewBlobValue([]byte(x))
 }
@@ -118,6 +132,15 @@ func TestCompare(t *testing.T) {
 		{"<=", "1", "2", true, jsonToDouble},
 		{"<=", "2", "2", true, jsonToDouble},
 
+		// decimal
+		{"=", "2.10", "2.1", true, toDecimal},
+		{"!=", "2.10", "2.1", false, toDecimal},
+		{">", "0.30", "0.3", false, toDecimal},
+		{">", "0.3000000000000000001", "0.3", true, toDecimal},
+		{">=", "-1.5", "-1.50", true, toDecimal},
+		{"<", "-1.51", "-1.5", true, toDecimal},
+		{"<=", "100", "99.99", false, toDecimal},
+
 		// text
 		{"=", "b", "a", false, toText},
 		{"=", "b", "b", true, toText},
@@ -136,6 +159,15 @@ func TestCompare(t *testing.T) {
 		{"<=", "a", "b", true, toText},
 		{"<=", "b", "b", true, toText},
 
+		// timestamp
+		{"=", "2020-11-02T10:30:00Z", "2020-11-02T12:30:00+02:00", true, toTimestamp},
+		{"!=", "2020-11-02T10:30:00Z", "2020-11-02T10:30:00+02:00", true, toTimestamp},
+		{">", "2020-11-02T10:30:00Z", "2020-11-02T11:30:00+02:00", true, toTimestamp},
+		{">", "2020-11-02T10:30:00Z", "2020-11-02T10:30:00Z", false, toTimestamp},
+		{">=", "2020-11-02T10:30:00Z", "2020-11-02T10:30:00Z", true, toTimestamp},
+		{"<", "2020-11-02T10:30:00Z", "2020-11-02T10:30:00-01:00", true, toTimestamp},
+		{"<=", "2020-11-02T10:30:00Z", "2020-11-02T10:30:00.000001Z", true, toTimestamp},
+
 		// blob
 		{"=", "b", "a", false, toBlob},
 		{"=", "b", "b", true, toBlob},
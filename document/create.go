@@ -170,13 +170,21 @@ func newFromStruct(ref reflect.Value) (Document, error) {
 }
 
 // NewValue creates a value whose type is infered from x.
+// time.Time values are converted to timestamps. Previous versions
+// converted them to RFC3339Nano texts: documents written before that
+// change keep their text values, which still compare with timestamps
+// in expressions but are sorted after all the timestamps by ORDER BY
+// and by indexes, and are not returned by index lookups using timestamps.
+// Such fields can be converted with UPDATE ... SET f = CAST(f AS TIMESTAMP).
 func NewValue(x interface{}) (Value, error) {
 	// Attempt exact matches first:
 	switch v := x.(type) {
 	case time.Duration:
 		return NewIntegerValue(v.Nanoseconds()), nil
 	case time.Time:
-		return NewTextValue(v.Format(time.RFC3339Nano)), nil
+		return NewTimestampValue(v), nil
+	case Decimal:
+		return NewDecimalValue(v), nil
 	case nil:
 		return NewNullValue(), nil
 	case Document:
@@ -0,0 +1,276 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalDigits is the maximum number of digits before and after
+// the decimal point of a parsed decimal. It prevents numbers like 1e1000000000
+// from allocating huge amounts of memory.
+const maxDecimalDigits = 1000
+
+// divisionScale is the minimum scale of the result of a division.
+const divisionScale = 16
+
+var bigTen = big.NewInt(10)
+
+// A Decimal is an exact decimal number.
+// It is stored as an arbitrary precision integer coefficient and a scale,
+// its value being coefficient * 10^-scale.
+// The zero value represents 0.
+type Decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+// NewDecimal returns a decimal whose value is coef * 10^-scale.
+func NewDecimal(coef int64, scale int) Decimal {
+	if scale < 0 {
+		return newDecimal(new(big.Int).Mul(big.NewInt(coef), pow10(-scale)), 0)
+	}
+
+	return newDecimal(big.NewInt(coef), scale)
+}
+
+func newDecimal(coef *big.Int, scale int) Decimal {
+	return Decimal{coef: coef, scale: scale}
+}
+
+// ParseDecimal parses a decimal number, with an optional sign,
+// fractional part and exponent, e.g. -12.50 or 1.5e3.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exp, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+
+	digits := intPart + fracPart
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		digits = digits[1:]
+	}
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" || strings.ContainsAny(fracPart, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	// bound the exponent first so that computing the scale cannot overflow.
+	if exp > math.MaxInt32 || exp < -math.MaxInt32 {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+
+	scale := len(fracPart) - exp
+	if scale > maxDecimalDigits || len(coef.Text(10))-scale > maxDecimalDigits {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+	if scale < 0 {
+		return newDecimal(coef.Mul(coef, pow10(-scale)), 0), nil
+	}
+
+	return newDecimal(coef, scale), nil
+}
+
+// DecimalFromFloat64 returns the decimal with the shortest representation
+// that converts back to f.
+func DecimalFromFloat64(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("cannot convert %v to decimal", f)
+	}
+
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Coefficient returns the coefficient of d.
+func (d Decimal) Coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(d.coef)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1 if d is negative, 0 if d is zero and 1 otherwise.
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+
+	return d.coef.Sign()
+}
+
+// String returns the representation of d, with exactly d.Scale() digits after the decimal point.
+func (d Decimal) String() string {
+	digits := d.Coefficient().String()
+
+	var sign string
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+
+	if d.scale == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+}
+
+// Float64 returns the nearest double of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Int64 returns the integer part of d.
+// It returns an error if the integer part doesn't fit in an int64.
+func (d Decimal) Int64() (int64, error) {
+	x := d.Coefficient()
+	x.Quo(x, pow10(d.scale))
+	if !x.IsInt64() {
+		return 0, errors.New(`cannot convert "decimal" to "integer" without overflowing`)
+	}
+
+	return x.Int64(), nil
+}
+
+// Cmp compares d and e and returns -1 if d < e, 0 if d == e and 1 if d > e.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b := align(d, e)
+	return a.Cmp(b)
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b := align(d, e)
+	return newDecimal(a.Add(a, b), max(d.scale, e.scale))
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b := align(d, e)
+	return newDecimal(a.Sub(a, b), max(d.scale, e.scale))
+}
+
+// Mul returns d * e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	x := d.Coefficient()
+	return newDecimal(x.Mul(x, e.Coefficient()), d.scale+e.scale)
+}
+
+// Quo returns d / e, rounded to at least 16 digits after the decimal point.
+// It returns an error if e is zero.
+func (d Decimal) Quo(e Decimal) (Decimal, error) {
+	if e.Sign() == 0 {
+		return Decimal{}, errors.New("division by zero")
+	}
+
+	scale := max(divisionScale, max(d.scale, e.scale))
+
+	// compute the result with one more digit, then round it.
+	x := d.Coefficient()
+	x.Mul(x, pow10(scale+1+e.scale-d.scale))
+	x.Quo(x, e.Coefficient())
+
+	return newDecimal(x, scale+1).Round(scale), nil
+}
+
+// Rem returns the remainder of d / e, which has the sign of d.
+// It returns an error if e is zero.
+func (d Decimal) Rem(e Decimal) (Decimal, error) {
+	if e.Sign() == 0 {
+		return Decimal{}, errors.New("division by zero")
+	}
+
+	a, b := align(d, e)
+	return newDecimal(a.Rem(a, b), max(d.scale, e.scale)), nil
+}
+
+// Round rounds d to the given number of digits after the decimal point.
+// Halves are rounded away from zero.
+func (d Decimal) Round(scale int) Decimal {
+	if scale >= d.scale {
+		x := d.Coefficient()
+		return newDecimal(x.Mul(x, pow10(scale-d.scale)), scale)
+	}
+
+	x := d.Coefficient()
+	neg := x.Sign() < 0
+	x.Abs(x)
+
+	var r big.Int
+	x.QuoRem(x, pow10(d.scale-scale), &r)
+	r.Mul(&r, big.NewInt(2))
+	if r.Cmp(pow10(d.scale-scale)) >= 0 {
+		x.Add(x, big.NewInt(1))
+	}
+	if neg {
+		x.Neg(x)
+	}
+
+	return newDecimal(x, scale)
+}
+
+// WithPrecision rounds d to the given scale and ensures the result
+// has at most precision digits in total.
+func (d Decimal) WithPrecision(precision, scale int) (Decimal, error) {
+	r := d.Round(scale)
+
+	x := r.Coefficient()
+	if x.Sign() != 0 && len(x.Abs(x).Text(10)) > precision {
+		return Decimal{}, fmt.Errorf("value %s doesn't fit in DECIMAL(%d, %d)", d, precision, scale)
+	}
+
+	return r, nil
+}
+
+// align returns the coefficients of d and e, scaled to the same scale.
+func align(d, e Decimal) (*big.Int, *big.Int) {
+	a, b := d.Coefficient(), e.Coefficient()
+
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(e.scale-d.scale))
+	case d.scale > e.scale:
+		b.Mul(b, pow10(d.scale-e.scale))
+	}
+
+	return a, b
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,118 @@
+package document_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s     string
+		want  string
+		fails bool
+	}{
+		{"0", "0", false},
+		{"12.50", "12.50", false},
+		{"-0.001", "-0.001", false},
+		{"+3", "3", false},
+		{".5", "0.5", false},
+		{"5.", "5", false},
+		{"1.5e3", "1500", false},
+		{"1.5E-3", "0.0015", false},
+		{"123456789012345678901234567890.5", "123456789012345678901234567890.5", false},
+		{"", "", true},
+		{"-", "", true},
+		{"1.2.3", "", true},
+		{"1e", "", true},
+		{"1.-2", "", true},
+		{"abc", "", true},
+		{"1e1000000000", "", true},
+		{"1e-9223372036854775808", "", true},
+		{"1e1000", "", true},
+		{"1e-1001", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			d, err := document.ParseDecimal(test.s)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, d.String())
+		})
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		s     string
+		scale int
+		want  string
+	}{
+		{"1.005", 2, "1.01"},
+		{"1.004", 2, "1.00"},
+		{"-1.005", 2, "-1.01"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"2", 2, "2.00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			d, err := document.ParseDecimal(test.s)
+			require.NoError(t, err)
+			require.Equal(t, test.want, d.Round(test.scale).String())
+		})
+	}
+}
+
+func TestDecimalWithPrecision(t *testing.T) {
+	tests := []struct {
+		s                string
+		precision, scale int
+		want             string
+		fails            bool
+	}{
+		{"12.345", 5, 2, "12.35", false},
+		{"999.994", 5, 2, "999.99", false},
+		{"999.995", 5, 2, "", true},
+		{"-999.99", 5, 2, "-999.99", false},
+		{"0.001", 3, 2, "0.00", false},
+		{"12", 2, 0, "12", false},
+		{"123", 2, 0, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			d, err := document.ParseDecimal(test.s)
+			require.NoError(t, err)
+
+			r, err := d.WithPrecision(test.precision, test.scale)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, r.String())
+		})
+	}
+}
+
+func TestDecimalInt64(t *testing.T) {
+	d, err := document.ParseDecimal("-12.99")
+	require.NoError(t, err)
+	i, err := d.Int64()
+	require.NoError(t, err)
+	require.EqualValues(t, -12, i)
+
+	d, err = document.ParseDecimal("100000000000000000000")
+	require.NoError(t, err)
+	_, err = d.Int64()
+	require.Error(t, err)
+}
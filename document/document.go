@@ -41,6 +41,16 @@ type Keyer interface {
 	Key() []byte
 }
 
+// A NamedDocumentGetter is a document made of named documents, like
+// the documents produced by joins. Paths made of more than one fragment
+// whose first fragment is the name of one of these documents refer to
+// the fields of that document, even if a field has the same name.
+type NamedDocumentGetter interface {
+	// GetNamedDocument returns the document with the given name, or NULL if
+	// that document is missing. It returns false if there is no such name.
+	GetNamedDocument(name string) (Value, bool)
+}
+
 // Length returns the length of a document.
 func Length(d Document) (int, error) {
 	if fb, ok := d.(*FieldBuffer); ok {
@@ -434,6 +444,12 @@ func (p Path) getValueFromDocument(d Document) (Value, error) {
 		return Value{}, ErrFieldNotFound
 	}
 
+	if nd, ok := d.(NamedDocumentGetter); ok && len(p) > 1 {
+		if v, ok := nd.GetNamedDocument(p[0].FieldName); ok {
+			return p[1:].getValueFromValue(v)
+		}
+	}
+
 	v, err := d.GetByField(p[0].FieldName)
 	if err != nil {
 		return Value{}, err
@@ -4,6 +4,7 @@ package encodingtest
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding"
@@ -19,6 +20,8 @@ func TestCodec(t *testing.T, codecBuilder func() encoding.Codec) {
 		{"EncodeDecode", testEncodeDecode},
 		{"NewDocument", testDecodeDocument},
 		{"Array/GetByIndex", testArrayGetByIndex},
+		{"Timestamp", testTimestamp},
+		{"Decimal", testDecimal},
 	}
 
 	for _, test := range tests {
@@ -177,3 +180,39 @@ func testDecodeDocument(t *testing.T, codecBuilder func() encoding.Codec) {
 	require.NoError(t, err)
 	require.Equal(t, 3, i)
 }
+
+func testTimestamp(t *testing.T, codecBuilder func() encoding.Codec) {
+	codec := codecBuilder()
+
+	ts := document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 123456000, time.FixedZone("", 3600)))
+
+	var buf bytes.Buffer
+
+	err := codec.NewEncoder(&buf).EncodeDocument(document.NewFieldBuffer().Add("a", ts))
+	require.NoError(t, err)
+
+	d := codec.NewDocument(buf.Bytes())
+	v, err := d.GetByField("a")
+	require.NoError(t, err)
+	require.Equal(t, ts, v)
+}
+
+func testDecimal(t *testing.T, codecBuilder func() encoding.Codec) {
+	codec := codecBuilder()
+
+	for _, text := range []string{"0", "12.50", "-0.001", "123456789012345678901234567890.123456789"} {
+		dec, err := document.ParseDecimal(text)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = codec.NewEncoder(&buf).EncodeDocument(document.NewFieldBuffer().Add("a", document.NewDecimalValue(dec)))
+		require.NoError(t, err)
+
+		d := codec.NewDocument(buf.Bytes())
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		require.Equal(t, document.DecimalValue, v.Type)
+		require.Equal(t, text, v.V.(document.Decimal).String())
+	}
+}
@@ -1,8 +1,10 @@
 package msgpack
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding"
@@ -10,6 +12,14 @@ import (
 	"github.com/vmihailenco/msgpack/v5/codes"
 )
 
+// decimalExtID is the MessagePack extension type of decimals.
+// Decimals are stored as an extension containing their textual representation,
+// in order to keep their scale.
+const decimalExtID int8 = 1
+
+// timestampExtID is the MessagePack extension type predefined for timestamps.
+const timestampExtID int8 = -1
+
 // A Codec is a MessagePack implementation of an encoding.Codec.
 type Codec struct{}
 
@@ -109,6 +119,8 @@ func (e *Encoder) EncodeArray(a document.Array) error {
 // - int32 -> int32
 // - int64 -> int64
 // - float64 -> float64
+// - decimal -> decimal extension
+// - timestamp -> timestamp extension
 func (e *Encoder) EncodeValue(v document.Value) error {
 	switch v.Type {
 	case document.DocumentValue:
@@ -127,11 +139,27 @@ func (e *Encoder) EncodeValue(v document.Value) error {
 		return e.enc.EncodeInt64(v.V.(int64))
 	case document.DoubleValue:
 		return e.enc.EncodeFloat64(v.V.(float64))
+	case document.DecimalValue:
+		return e.encodeDecimal(v.V.(document.Decimal))
+	case document.TimestampValue:
+		return e.enc.EncodeTime(v.V.(time.Time))
 	}
 
 	return e.enc.Encode(v.V)
 }
 
+// encodeDecimal encodes d as a decimal extension.
+func (e *Encoder) encodeDecimal(d document.Decimal) error {
+	b := []byte(d.String())
+	err := e.enc.EncodeExtHeader(decimalExtID, len(b))
+	if err != nil {
+		return err
+	}
+
+	_, err = e.enc.Writer().Write(b)
+	return err
+}
+
 // Close puts the encoder into the pool for reuse.
 func (e *Encoder) Close() {
 	msgpack.PutEncoder(e.enc)
@@ -232,11 +260,58 @@ func (d *Decoder) DecodeValue() (v document.Value, err error) {
 		}
 		v.Type = document.DoubleValue
 		return
+	case codes.FixExt1, codes.FixExt2, codes.FixExt4, codes.FixExt8, codes.FixExt16, codes.Ext8, codes.Ext16, codes.Ext32:
+		var raw msgpack.RawMessage
+		raw, err = d.dec.DecodeRaw()
+		if err != nil {
+			return
+		}
+
+		return decodeExt(raw)
 	}
 
 	panic(fmt.Sprintf("unsupported type %v", c))
 }
 
+// decodeExt decodes a timestamp or a decimal extension.
+func decodeExt(raw []byte) (v document.Value, err error) {
+	dec := msgpack.GetDecoder()
+	defer msgpack.PutDecoder(dec)
+	dec.Reset(bytes.NewReader(raw))
+
+	id, n, err := dec.DecodeExtHeader()
+	if err != nil {
+		return
+	}
+
+	switch id {
+	case timestampExtID:
+		var t time.Time
+		err = msgpack.Unmarshal(raw, &t)
+		if err != nil {
+			return
+		}
+
+		return document.NewTimestampValue(t), nil
+	case decimalExtID:
+		b := make([]byte, n)
+		err = dec.ReadFull(b)
+		if err != nil {
+			return
+		}
+
+		var x document.Decimal
+		x, err = document.ParseDecimal(string(b))
+		if err != nil {
+			return
+		}
+
+		return document.NewDecimalValue(x), nil
+	}
+
+	return v, fmt.Errorf("unsupported extension type %d", id)
+}
+
 // DecodeDocument decodes one document from the reader.
 // If the document is malformed, it will not return an error.
 // However, calls to Iterate or GetByField will fail.
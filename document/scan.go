@@ -315,7 +315,11 @@ func scanValue(v Value, ref reflect.Value) error {
 	// test with supported stdlib types
 	switch ref.Type().String() {
 	case "time.Time":
-		if v.Type == TextValue {
+		switch v.Type {
+		case TimestampValue:
+			ref.Set(reflect.ValueOf(v.V))
+			return nil
+		case TextValue:
 			parsed, err := time.Parse(time.RFC3339Nano, v.V.(string))
 			if err != nil {
 				return err
@@ -324,6 +328,14 @@ func scanValue(v Value, ref reflect.Value) error {
 			ref.Set(reflect.ValueOf(parsed))
 			return nil
 		}
+	case "document.Decimal":
+		v, err := v.CastAsDecimal()
+		if err != nil {
+			return err
+		}
+
+		ref.Set(reflect.ValueOf(v.V))
+		return nil
 	}
 
 	switch ref.Kind() {
@@ -67,6 +67,7 @@ func TestScan(t *testing.T) {
 		)).
 		Add("o", document.NewNullValue()).
 		Add("p", document.NewTextValue(now.Format(time.RFC3339Nano))).
+		Add("q", document.NewTimestampValue(now)).
 		Add("r", document.NewDocumentValue(codec.NewDocument(buf.Bytes())))
 
 	type foo struct {
@@ -91,9 +92,10 @@ func TestScan(t *testing.T) {
 	var n map[string]string
 	var o []int = []int{1, 2, 3}
 	var p time.Time
+	var q time.Time
 	var r map[string]interface{}
 
-	err = document.Scan(doc, &a, &b, &c, &d, &e, &f, &g, &h, &i, &j, &k, &l, &m, &n, &o, &p, &r)
+	err = document.Scan(doc, &a, &b, &c, &d, &e, &f, &g, &h, &i, &j, &k, &l, &m, &n, &o, &p, &q, &r)
 	require.NoError(t, err)
 	require.Equal(t, a, []byte("foo"))
 	require.Equal(t, b, "bar")
@@ -112,6 +114,7 @@ func TestScan(t *testing.T) {
 	require.Equal(t, map[string]string{"foo": "foo", "bar": "bar"}, n)
 	require.Equal(t, []int(nil), o)
 	require.Equal(t, now.Format(time.RFC3339Nano), p.Format(time.RFC3339Nano))
+	require.True(t, now.Truncate(time.Microsecond).Equal(q))
 	require.Equal(t, map[string]interface{}{
 		"foo": map[string]interface{}{
 			"foo": "foo",
@@ -136,14 +139,14 @@ func TestScan(t *testing.T) {
 		m := make(map[string]interface{})
 		err := document.MapScan(doc, m)
 		require.NoError(t, err)
-		require.Len(t, m, 17)
+		require.Len(t, m, 18)
 	})
 
 	t.Run("MapPtr", func(t *testing.T) {
 		var m map[string]interface{}
 		err := document.MapScan(doc, &m)
 		require.NoError(t, err)
-		require.Len(t, m, 17)
+		require.Len(t, m, 18)
 	})
 
 	t.Run("Small Slice", func(t *testing.T) {
@@ -165,6 +168,14 @@ func TestScan(t *testing.T) {
 		require.Len(t, s, 2)
 		require.Equal(t, []int{1, 2}, s)
 	})
+
+	t.Run("Decimal", func(t *testing.T) {
+		var d document.Decimal
+		doc := document.NewFieldBuffer().Add("a", document.NewTextValue("12.50"))
+		err := document.Scan(doc, &d)
+		require.NoError(t, err)
+		require.Equal(t, "12.50", d.String())
+	})
 }
 
 type documentScanner struct {
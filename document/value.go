@@ -7,19 +7,21 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/buger/jsonparser"
 	"github.com/genjidb/genji/binarysort"
 )
 
 var (
-	boolZeroValue     = NewZeroValue(BoolValue)
-	integerZeroValue  = NewZeroValue(IntegerValue)
-	doubleZeroValue   = NewZeroValue(DoubleValue)
-	blobZeroValue     = NewZeroValue(BlobValue)
-	textZeroValue     = NewZeroValue(TextValue)
-	arrayZeroValue    = NewZeroValue(ArrayValue)
-	documentZeroValue = NewZeroValue(DocumentValue)
+	boolZeroValue      = NewZeroValue(BoolValue)
+	integerZeroValue   = NewZeroValue(IntegerValue)
+	doubleZeroValue    = NewZeroValue(DoubleValue)
+	timestampZeroValue = NewZeroValue(TimestampValue)
+	blobZeroValue      = NewZeroValue(BlobValue)
+	textZeroValue      = NewZeroValue(TextValue)
+	arrayZeroValue     = NewZeroValue(ArrayValue)
+	documentZeroValue  = NewZeroValue(DocumentValue)
 )
 
 // ErrUnsupportedType is used to skip struct or array fields that are not supported.
@@ -46,9 +48,15 @@ const (
 	// integer family: 0x90 to 0x9F
 	IntegerValue ValueType = 0x90
 
-	// double family: 0xA0 to 0xAF
+	// double family: 0xA0 to 0xA7
 	DoubleValue ValueType = 0xA0
 
+	// decimal family: 0xA8 to 0xAF
+	DecimalValue ValueType = 0xA8
+
+	// timestamp family: 0xB0 to 0xBF
+	TimestampValue ValueType = 0xB0
+
 	// string family: 0xC0 to 0xCF
 	TextValue ValueType = 0xC0
 
@@ -72,6 +80,10 @@ func (t ValueType) String() string {
 		return "integer"
 	case DoubleValue:
 		return "double"
+	case DecimalValue:
+		return "decimal"
+	case TimestampValue:
+		return "timestamp"
 	case BlobValue:
 		return "blob"
 	case TextValue:
@@ -85,9 +97,9 @@ func (t ValueType) String() string {
 	return ""
 }
 
-// IsNumber returns true if t is either an integer of a float.
+// IsNumber returns true if t is either an integer, a float or a decimal.
 func (t ValueType) IsNumber() bool {
-	return t == IntegerValue || t == DoubleValue
+	return t == IntegerValue || t == DoubleValue || t == DecimalValue
 }
 
 // A Value stores encoded data alongside its type.
@@ -128,6 +140,23 @@ func NewDoubleValue(x float64) Value {
 	}
 }
 
+// NewDecimalValue encodes x and returns a value.
+func NewDecimalValue(x Decimal) Value {
+	return Value{
+		Type: DecimalValue,
+		V:    x,
+	}
+}
+
+// NewTimestampValue encodes x and returns a value.
+// Timestamps are stored in UTC, with a precision of one microsecond.
+func NewTimestampValue(x time.Time) Value {
+	return Value{
+		Type: TimestampValue,
+		V:    x.Truncate(time.Microsecond).UTC(),
+	}
+}
+
 // NewBlobValue encodes x and returns a value.
 func NewBlobValue(x []byte) Value {
 	return Value{
@@ -172,6 +201,10 @@ func NewZeroValue(t ValueType) Value {
 		return NewIntegerValue(0)
 	case DoubleValue:
 		return NewDoubleValue(0)
+	case DecimalValue:
+		return NewDecimalValue(Decimal{})
+	case TimestampValue:
+		return NewTimestampValue(time.Unix(0, 0))
 	case BlobValue:
 		return NewBlobValue(nil)
 	case TextValue:
@@ -205,6 +238,10 @@ func (v Value) IsZeroValue() (bool, error) {
 		return v.V == integerZeroValue.V, nil
 	case DoubleValue:
 		return v.V == doubleZeroValue.V, nil
+	case DecimalValue:
+		return v.V.(Decimal).Sign() == 0, nil
+	case TimestampValue:
+		return v.V.(time.Time).Equal(timestampZeroValue.V.(time.Time)), nil
 	case BlobValue:
 		return bytes.Compare(v.V.([]byte), blobZeroValue.V.([]byte)) == 0, nil
 	case TextValue:
@@ -264,6 +301,10 @@ func (v Value) MarshalJSON() ([]byte, error) {
 		prec := -1
 
 		return strconv.AppendFloat(nil, v.V.(float64), fmt, prec, 64), nil
+	case DecimalValue:
+		return []byte(v.V.(Decimal).String()), nil
+	case TimestampValue:
+		return []byte(strconv.Quote(v.V.(time.Time).Format(time.RFC3339Nano))), nil
 	case TextValue:
 		return []byte(strconv.Quote(v.V.(string))), nil
 	case BlobValue:
@@ -311,6 +352,11 @@ func (v Value) Append(buf []byte) ([]byte, error) {
 		return binarysort.AppendInt64(buf, v.V.(int64)), nil
 	case DoubleValue:
 		return binarysort.AppendFloat64(buf, v.V.(float64)), nil
+	case DecimalValue:
+		d := v.V.(Decimal)
+		return binarysort.AppendDecimal(buf, d.Coefficient(), d.Scale()), nil
+	case TimestampValue:
+		return binarysort.AppendTime(buf, v.V.(time.Time)), nil
 	case NullValue:
 		return buf, nil
 	case ArrayValue:
@@ -365,6 +411,18 @@ func (v *Value) UnmarshalBinary(data []byte) error {
 			return err
 		}
 		v.V = x
+	case DecimalValue:
+		coef, scale, _, err := binarysort.DecodeDecimal(data)
+		if err != nil {
+			return err
+		}
+		v.V = newDecimal(coef, scale)
+	case TimestampValue:
+		x, err := binarysort.DecodeTime(data)
+		if err != nil {
+			return err
+		}
+		v.V = x
 	case ArrayValue:
 		a, _, err := decodeArray(data)
 		if err != nil {
@@ -446,7 +504,17 @@ func calculateValues(a, b Value, operator byte) (res Value, err error) {
 		return NewNullValue(), nil
 	}
 
+	if a.Type == TimestampValue || b.Type == TimestampValue {
+		return calculateTimestamps(a, b, operator)
+	}
+
 	if a.Type.IsNumber() && b.Type.IsNumber() {
+		// decimals are exact, the other operand is converted to a decimal,
+		// even if it's a double.
+		if a.Type == DecimalValue || b.Type == DecimalValue {
+			return calculateDecimals(a, b, operator)
+		}
+
 		if a.Type == DoubleValue || b.Type == DoubleValue {
 			return calculateFloats(a, b, operator)
 		}
@@ -546,6 +614,127 @@ func calculateIntegers(a, b Value, operator byte) (res Value, err error) {
 	}
 }
 
+// calculateDecimals computes the result of an operation on two decimals, or a decimal and an integer.
+// The result is exact, except for divisions which are rounded.
+func calculateDecimals(a, b Value, operator byte) (res Value, err error) {
+	da, err := a.CastAsDecimal()
+	if err != nil {
+		return NewNullValue(), nil
+	}
+	xa := da.V.(Decimal)
+
+	db, err := b.CastAsDecimal()
+	if err != nil {
+		return NewNullValue(), nil
+	}
+	xb := db.V.(Decimal)
+
+	switch operator {
+	case '+':
+		return NewDecimalValue(xa.Add(xb)), nil
+	case '-':
+		return NewDecimalValue(xa.Sub(xb)), nil
+	case '*':
+		return NewDecimalValue(xa.Mul(xb)), nil
+	case '/':
+		q, err := xa.Quo(xb)
+		if err != nil {
+			return NewNullValue(), nil
+		}
+
+		return NewDecimalValue(q), nil
+	case '%':
+		r, err := xa.Rem(xb)
+		if err != nil {
+			return NewNullValue(), nil
+		}
+
+		return NewDecimalValue(r), nil
+	case '&', '|', '^':
+		ia, err := xa.Int64()
+		if err != nil {
+			return NewNullValue(), nil
+		}
+		ib, err := xb.Int64()
+		if err != nil {
+			return NewNullValue(), nil
+		}
+
+		return calculateIntegers(NewIntegerValue(ia), NewIntegerValue(ib), operator)
+	default:
+		panic(fmt.Sprintf("unknown operator %c", operator))
+	}
+}
+
+// calculateTimestamps supports adding or subtracting an integer number of microseconds
+// to a timestamp, and subtracting two timestamps, which returns the number of microseconds between them.
+// If the result cannot be represented, it returns NULL.
+func calculateTimestamps(a, b Value, operator byte) (res Value, err error) {
+	switch {
+	case a.Type == TimestampValue && b.Type == TimestampValue:
+		if operator != '-' {
+			return NewNullValue(), nil
+		}
+
+		ua, ok := unixMicro(a.V.(time.Time))
+		if !ok {
+			return NewNullValue(), nil
+		}
+		ub, ok := unixMicro(b.V.(time.Time))
+		if !ok {
+			return NewNullValue(), nil
+		}
+
+		d := ua - ub
+		// if there is an integer overflow
+		if (d < ua) != (ub > 0) {
+			return NewNullValue(), nil
+		}
+		return NewIntegerValue(d), nil
+	case a.Type == TimestampValue && b.Type == IntegerValue:
+		ua, ok := unixMicro(a.V.(time.Time))
+		if !ok {
+			return NewNullValue(), nil
+		}
+		xb := b.V.(int64)
+
+		var r int64
+		switch operator {
+		case '+':
+			r = ua + xb
+			if (r > ua) != (xb > 0) {
+				return NewNullValue(), nil
+			}
+		case '-':
+			r = ua - xb
+			if (r < ua) != (xb > 0) {
+				return NewNullValue(), nil
+			}
+		default:
+			return NewNullValue(), nil
+		}
+
+		return NewTimestampValue(time.Unix(r/1e6, (r%1e6)*1e3)), nil
+	case a.Type == IntegerValue && b.Type == TimestampValue:
+		if operator == '+' {
+			return calculateTimestamps(b, a, operator)
+		}
+	}
+
+	return NewNullValue(), nil
+}
+
+// unixMicro returns the number of microseconds elapsed between January 1, 1970 UTC and t,
+// or false if it cannot be represented by an int64.
+func unixMicro(t time.Time) (int64, bool) {
+	s := t.Unix()
+	if s > math.MaxInt64/1000000-1 || s < math.MinInt64/1000000+1 {
+		return 0, false
+	}
+
+	return s*1e6 + int64(t.Nanosecond()/1e3), true
+}
+
 func calculateFloats(a, b Value, operator byte) (res Value, err error) {
 	var xa, xb float64
 
@@ -3,6 +3,7 @@ package document
 import (
 	"errors"
 	"io"
+	"time"
 
 	"github.com/genjidb/genji/binarysort"
 )
@@ -68,6 +69,11 @@ func (ve *ValueEncoder) appendValue(v Value) error {
 		ve.buf = binarysort.AppendInt64(ve.buf, v.V.(int64))
 	case DoubleValue:
 		ve.buf = binarysort.AppendFloat64(ve.buf, v.V.(float64))
+	case DecimalValue:
+		d := v.V.(Decimal)
+		ve.buf = binarysort.AppendDecimal(ve.buf, d.Coefficient(), d.Scale())
+	case TimestampValue:
+		ve.buf = binarysort.AppendTime(ve.buf, v.V.(time.Time))
 	default:
 		return errors.New("cannot encode type " + v.Type.String() + " as key")
 	}
@@ -178,6 +184,18 @@ func decodeValue(data []byte) (Value, error) {
 			return Value{}, err
 		}
 		return NewDoubleValue(x), nil
+	case DecimalValue:
+		coef, scale, _, err := binarysort.DecodeDecimal(data)
+		if err != nil {
+			return Value{}, err
+		}
+		return NewDecimalValue(newDecimal(coef, scale)), nil
+	case TimestampValue:
+		x, err := binarysort.DecodeTime(data)
+		if err != nil {
+			return Value{}, err
+		}
+		return NewTimestampValue(x), nil
 	case ArrayValue:
 		a, _, err := decodeArray(data)
 		if err != nil {
@@ -217,12 +235,18 @@ func decodeValueUntil(data []byte, delim, end byte) (Value, int, error) {
 	case NullValue:
 	case BoolValue:
 		i++
-	case IntegerValue, DoubleValue:
+	case IntegerValue, DoubleValue, TimestampValue:
 		if i+8 < len(data) && data[i+8] == delim {
 			i += 8
 		} else {
 			return Value{}, 0, errors.New("malformed " + t.String())
 		}
+	case DecimalValue:
+		_, _, n, err := binarysort.DecodeDecimal(data[i:])
+		if err != nil || i+n >= len(data) || data[i+n] != delim {
+			return Value{}, 0, errors.New("malformed " + t.String())
+		}
+		i += n
 	case BlobValue, TextValue:
 		for i < len(data) && data[i] != delim && data[i] != end {
 			i++
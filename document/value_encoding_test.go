@@ -3,6 +3,7 @@ package document
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -16,12 +17,16 @@ func TestValueEncoder(t *testing.T) {
 		{"bool", NewBoolValue(true)},
 		{"integer", NewIntegerValue(-10)},
 		{"double", NewDoubleValue(-3.14)},
+		{"decimal", NewDecimalValue(NewDecimal(-1255, 2))},
+		{"timestamp", NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 123456000, time.UTC))},
 		{"text", NewTextValue("foo")},
 		{"blob", NewBlobValue([]byte("bar"))},
 		{"array", NewArrayValue(NewValueBuffer(
 			NewBoolValue(true),
 			NewIntegerValue(55),
 			NewDoubleValue(789.58),
+			NewDecimalValue(NewDecimal(78958, 2)),
+			NewTimestampValue(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)),
 			NewArrayValue(NewValueBuffer(
 				NewBoolValue(false),
 				NewIntegerValue(100),
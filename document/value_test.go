@@ -75,7 +75,7 @@ func TestNewValue(t *testing.T) {
 		{"null", nil, nil},
 		{"document", document.NewFieldBuffer().Add("a", document.NewIntegerValue(10)), document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))},
 		{"array", document.NewValueBuffer(document.NewIntegerValue(10)), document.NewValueBuffer(document.NewIntegerValue(10))},
-		{"time", now, now.Format(time.RFC3339Nano)},
+		{"time", now, now.Truncate(time.Microsecond).UTC()},
 		{"bytes", myBytes("bar"), []byte("bar")},
 		{"string", myString("bar"), "bar"},
 		{"myUint", myUint(10), int64(10)},
@@ -119,6 +119,15 @@ func TestValueAdd(t *testing.T) {
 		{"text('120')+text('120')", document.NewTextValue("120"), document.NewTextValue("120"), document.NewNullValue(), false},
 		{"document+document", document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))), document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))), document.NewNullValue(), false},
 		{"array+array", document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(10))), document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(10))), document.NewNullValue(), false},
+		{"timestamp+integer(1000000)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(1000000), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 1, 0, time.UTC)), false},
+		{"timestamp+int64(max)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(math.MaxInt64), document.NewNullValue(), false},
+		{"timestamp+integer(300 years)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(300 * 365 * 86400 * 1000000), document.NewTimestampValue(time.Date(2320, 8, 22, 10, 30, 0, 0, time.UTC)), false},
+		{"integer(1)+timestamp", document.NewIntegerValue(1), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 1000, time.UTC)), false},
+		{"timestamp+timestamp", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewNullValue(), false},
+		{"decimal(1.10)+decimal(2.205)", toDecimal(t, "1.10"), toDecimal(t, "2.205"), toDecimal(t, "3.305"), false},
+		{"decimal(0.1)+integer(1)", toDecimal(t, "0.1"), document.NewIntegerValue(1), toDecimal(t, "1.1"), false},
+		{"decimal(0.1)+double(0.2)", toDecimal(t, "0.1"), document.NewDoubleValue(0.2), toDecimal(t, "0.3"), false},
+		{"timestamp+double(1)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewDoubleValue(1), document.NewNullValue(), false},
 	}
 
 	for _, test := range tests {
@@ -155,6 +164,14 @@ func TestValueSub(t *testing.T) {
 		{"text('120')-text('120')", document.NewTextValue("120"), document.NewTextValue("120"), document.NewNullValue(), false},
 		{"document-document", document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))), document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))), document.NewNullValue(), false},
 		{"array-array", document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(10))), document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(10))), document.NewNullValue(), false},
+		{"timestamp-integer(1000000)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(1000000), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 29, 59, 0, time.UTC)), false},
+		{"timestamp-timestamp", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewTimestampValue(time.Date(2020, 11, 1, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(86400000000), false},
+		{"timestamp-int64(min)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(math.MinInt64), document.NewNullValue(), false},
+		{"timestamp-timestamp(520 years)", document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewTimestampValue(time.Date(1500, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewIntegerValue(16409692800000000), false},
+		{"timestamp-timestamp(overflow)", document.NewTimestampValue(time.Date(200000, 1, 1, 0, 0, 0, 0, time.UTC)), document.NewTimestampValue(time.Date(-200000, 1, 1, 0, 0, 0, 0, time.UTC)), document.NewNullValue(), false},
+		{"decimal(1.00)-decimal(0.01)", toDecimal(t, "1.00"), toDecimal(t, "0.01"), toDecimal(t, "0.99"), false},
+		{"integer(1)-decimal(2.5)", document.NewIntegerValue(1), toDecimal(t, "2.5"), toDecimal(t, "-1.5"), false},
+		{"integer(1)-timestamp", document.NewIntegerValue(1), document.NewTimestampValue(time.Date(2020, 11, 2, 10, 30, 0, 0, time.UTC)), document.NewNullValue(), false},
 	}
 
 	for _, test := range tests {
@@ -184,6 +201,8 @@ func TestValueMult(t *testing.T) {
 		{"integer(10)*integer(10)", document.NewIntegerValue(10), document.NewIntegerValue(10), document.NewIntegerValue(100), false},
 		{"integer(10)*integer(80)", document.NewIntegerValue(10), document.NewIntegerValue(80), document.NewIntegerValue(800), false},
 		{"integer(10)*float64(80)", document.NewIntegerValue(10), document.NewDoubleValue(80), document.NewDoubleValue(800), false},
+		{"decimal(1.5)*integer(3)", toDecimal(t, "1.5"), document.NewIntegerValue(3), toDecimal(t, "4.5"), false},
+		{"decimal(0.1)*decimal(0.1)", toDecimal(t, "0.1"), toDecimal(t, "0.1"), toDecimal(t, "0.01"), false},
 		{"int64(max)*int64(max)", document.NewIntegerValue(math.MaxInt64), document.NewIntegerValue(math.MaxInt64), document.NewDoubleValue(math.MaxInt64 * math.MaxInt64), false},
 		{"integer(120)*text('120')", document.NewIntegerValue(120), document.NewTextValue("120"), document.NewNullValue(), false},
 		{"text('120')*text('120')", document.NewTextValue("120"), document.NewTextValue("120"), document.NewNullValue(), false},
@@ -219,6 +238,9 @@ func TestValueDiv(t *testing.T) {
 		{"integer(10)/integer(10)", document.NewIntegerValue(10), document.NewIntegerValue(10), document.NewIntegerValue(1), false},
 		{"integer(10)/integer(8)", document.NewIntegerValue(10), document.NewIntegerValue(8), document.NewIntegerValue(1), false},
 		{"integer(10)/float64(8)", document.NewIntegerValue(10), document.NewDoubleValue(8), document.NewDoubleValue(1.25), false},
+		{"decimal(1)/integer(3)", toDecimal(t, "1"), document.NewIntegerValue(3), toDecimal(t, "0.3333333333333333"), false},
+		{"decimal(2)/decimal(3)", toDecimal(t, "2"), toDecimal(t, "3"), toDecimal(t, "0.6666666666666667"), false},
+		{"decimal(10)/decimal(0)", toDecimal(t, "10"), toDecimal(t, "0"), document.NewNullValue(), false},
 		{"int64(maxint)/float64(maxint)", document.NewIntegerValue(math.MaxInt64), document.NewDoubleValue(math.MaxInt64), document.NewDoubleValue(1), false},
 		{"integer(120)/text('120')", document.NewIntegerValue(120), document.NewTextValue("120"), document.NewNullValue(), false},
 		{"text('120')/text('120')", document.NewTextValue("120"), document.NewTextValue("120"), document.NewNullValue(), false},
@@ -255,6 +277,8 @@ func TestValueMod(t *testing.T) {
 		{"integer(10)%integer(8)", document.NewIntegerValue(10), document.NewIntegerValue(8), document.NewIntegerValue(2), false},
 		{"integer(10)%float64(8)", document.NewIntegerValue(10), document.NewDoubleValue(8), document.NewDoubleValue(2), false},
 		{"int64(maxint)%float64(maxint)", document.NewIntegerValue(math.MaxInt64), document.NewDoubleValue(math.MaxInt64), document.NewDoubleValue(0), false},
+		{"decimal(5.5)%integer(2)", toDecimal(t, "5.5"), document.NewIntegerValue(2), toDecimal(t, "1.5"), false},
+		{"decimal(-5.5)%decimal(2)", toDecimal(t, "-5.5"), toDecimal(t, "2"), toDecimal(t, "-1.5"), false},
 		{"double(> maxint)%int64(100)", document.NewDoubleValue(math.MaxInt64 + 1000), document.NewIntegerValue(100), document.NewDoubleValue(8), false},
 		{"int64(100)%float64(> maxint)", document.NewIntegerValue(100), document.NewDoubleValue(math.MaxInt64 + 1000), document.NewDoubleValue(100), false},
 		{"integer(120)%text('120')", document.NewIntegerValue(120), document.NewTextValue("120"), document.NewNullValue(), false},
@@ -1019,6 +1019,41 @@ func TestStoreDelete(t *testing.T, builder Builder) {
 		err = st.Delete([]byte("foo"))
 		require.Equal(t, context.Canceled, err)
 	})
+
+	t.Run("Should keep a key put back after being deleted", func(t *testing.T) {
+		ng, cleanup := builder()
+		defer cleanup()
+		defer ng.Close()
+
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		err = tx.CreateStore([]byte("test"))
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Put([]byte("foo"), []byte("FOO"))
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Delete([]byte("foo"))
+		require.NoError(t, err)
+		err = st.Put([]byte("foo"), []byte("BAR"))
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		v, err := st.Get([]byte("foo"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("BAR"), v)
+	})
 }
 
 // TestStoreTruncate verifies Truncate behaviour.
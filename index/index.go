@@ -20,6 +20,8 @@ var valueTypes = []document.ValueType{
 	document.BoolValue,
 	document.IntegerValue,
 	document.DoubleValue,
+	document.DecimalValue,
+	document.TimestampValue,
 	document.TextValue,
 	document.BlobValue,
 	document.ArrayValue,
@@ -157,6 +159,39 @@ func (idx *Index) Delete(v document.Value, k []byte) error {
 	return engine.ErrKeyNotFound
 }
 
+// Get returns the key associated with v in a unique index.
+// Unlike the iteration methods, it can look up NULL values.
+// It returns engine.ErrKeyNotFound if v is not indexed.
+func (idx *Index) Get(v document.Value) ([]byte, error) {
+	if !idx.Unique {
+		return nil, errors.New("cannot get a key from a non-unique index")
+	}
+
+	if idx.Type != 0 && idx.Type != v.Type {
+		return nil, engine.ErrKeyNotFound
+	}
+
+	st, err := idx.tx.GetStore(idx.storeName)
+	if err == engine.ErrStoreNotFound {
+		return nil, engine.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := idx.EncodeValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := st.Get(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, k...), nil
+}
+
 // AscendGreaterOrEqual seeks for the pivot and then goes through all the subsequent key value pairs in increasing order and calls the given function for each pair.
 // If the given function returns an error, the iteration stops and returns that error.
 // If the pivot is empty, starts from the beginning.
@@ -276,7 +311,9 @@ func (idx *Index) iterate(st engine.Store, pivot document.Value, reverse bool, f
 		}
 	}
 
-	if pivot.Type != 0 && pivot.V == nil {
+	// typed indexes don't store the type of the values,
+	// there is no need to seek to the first value of that type.
+	if idx.Type == 0 && pivot.Type != 0 && pivot.V == nil {
 		seek = []byte{byte(pivot.Type)}
 
 		if reverse {
@@ -68,6 +68,33 @@ func TestIndexSet(t *testing.T) {
 	})
 }
 
+func TestIndexGet(t *testing.T) {
+	idx, cleanup := getIndex(t, true)
+	defer cleanup()
+
+	_, err := idx.Get(document.NewIntegerValue(10))
+	require.Equal(t, engine.ErrKeyNotFound, err)
+
+	require.NoError(t, idx.Set(document.NewIntegerValue(10), []byte("a")))
+	require.NoError(t, idx.Set(document.NewNullValue(), []byte("b")))
+
+	k, err := idx.Get(document.NewIntegerValue(10))
+	require.NoError(t, err)
+	require.Equal(t, []byte("a"), k)
+
+	k, err = idx.Get(document.NewNullValue())
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), k)
+
+	_, err = idx.Get(document.NewIntegerValue(11))
+	require.Equal(t, engine.ErrKeyNotFound, err)
+
+	idx, cleanup = getIndex(t, false)
+	defer cleanup()
+	_, err = idx.Get(document.NewIntegerValue(10))
+	require.Error(t, err)
+}
+
 func TestIndexDelete(t *testing.T) {
 	t.Run("Unique: false, Delete valid key succeeds", func(t *testing.T) {
 		idx, cleanup := getIndex(t, false)
@@ -295,6 +322,28 @@ func TestIndexAscendGreaterThan(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, 10, ints)
 		})
+
+		t.Run(text+"With typed empty pivot and typed index, should iterate over all documents in order", func(t *testing.T) {
+			idx, cleanup := getIndex(t, unique)
+			idx.Type = document.IntegerValue
+			defer cleanup()
+
+			for i := int64(0); i < 10; i++ {
+				require.NoError(t, idx.Set(document.NewIntegerValue(i), []byte{'i', 'a' + byte(i)}))
+			}
+
+			var ints int
+			err := idx.AscendGreaterOrEqual(document.Value{Type: document.IntegerValue}, func(val, rid []byte, isEqual bool) error {
+				enc, err := document.NewIntegerValue(int64(ints)).MarshalBinary()
+				require.NoError(t, err)
+				require.Equal(t, enc, val)
+				ints++
+
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, 10, ints)
+		})
 	}
 
 	t.Run("Unique: false, Must iterate through similar values properly", func(t *testing.T) {
@@ -8,17 +8,26 @@ import (
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document/encoding/custom"
 	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query/expr"
 )
 
 // New initializes the DB using the given engine.
 func New(ctx context.Context, ng engine.Engine) (*DB, error) {
-	db, err := database.New(ctx, ng, database.Options{Codec: custom.NewCodec()})
+	functions := expr.NewFunctions()
+
+	db, err := database.New(ctx, ng, database.Options{
+		Codec:          custom.NewCodec(),
+		ViewMaintainer: planner.NewViewMaintainer(parser.NewViewParser(functions)),
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &DB{
-		DB:  db,
-		ctx: context.Background(),
+		DB:        db,
+		ctx:       context.Background(),
+		functions: &functions,
 	}, nil
 }
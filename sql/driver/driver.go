@@ -11,8 +11,6 @@ import (
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
-	"github.com/genjidb/genji/sql/parser"
-	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 )
@@ -41,14 +39,25 @@ func (d sqlDriver) OpenConnector(name string) (driver.Connector, error) {
 	}
 
 	c := &connector{
-		db:     db,
-		driver: d,
+		db:      db,
+		driver:  d,
+		closeDB: true,
 	}
 	runtime.SetFinalizer(c, (*connector).Close)
 
 	return c, nil
 }
 
+// NewConnector returns a connector that creates connections to an already opened database,
+// to be used with sql.OpenDB. Unlike the connectors created by sql.Open, it can run queries
+// calling the functions registered on db. Closing the connector doesn't close db.
+func NewConnector(db *genji.DB) driver.Connector {
+	return &connector{
+		db:     db,
+		driver: sqlDriver{},
+	}
+}
+
 var (
 	_ driver.Connector = (*connector)(nil)
 	_ io.Closer        = (*connector)(nil)
@@ -58,6 +67,9 @@ type connector struct {
 	driver driver.Driver
 
 	db *genji.DB
+	// whether the database was opened by the connector
+	// and must be closed with it.
+	closeDB bool
 
 	closeOnce sync.Once
 }
@@ -73,7 +85,9 @@ func (c *connector) Driver() driver.Driver {
 func (c *connector) Close() error {
 	var err error
 	c.closeOnce.Do(func() {
-		err = c.db.Close()
+		if c.closeDB {
+			err = c.db.Close()
+		}
 	})
 	return err
 }
@@ -92,15 +106,20 @@ func (c *conn) Prepare(q string) (driver.Stmt, error) {
 
 // PrepareContext returns a prepared statement, bound to this connection.
 func (c *conn) PrepareContext(ctx context.Context, q string) (driver.Stmt, error) {
-	pq, err := parser.ParseQuery(q)
+	var st *genji.Statement
+	var err error
+
+	if c.tx != nil {
+		st, err = c.tx.Prepare(q)
+	} else {
+		st, err = c.db.Prepare(q)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return stmt{
-		db: c.db,
-		tx: c.tx,
-		q:  pq,
+		st: st,
 	}, nil
 }
 
@@ -151,9 +170,7 @@ func (c *conn) Rollback() error {
 // Stmt is a prepared statement. It is bound to a Conn and not
 // used by multiple goroutines concurrently.
 type stmt struct {
-	db *genji.DB
-	tx *genji.Tx
-	q  query.Query
+	st *genji.Statement
 }
 
 // NumInput returns the number of placeholder parameters.
@@ -196,24 +213,14 @@ func (s stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver
 	default:
 	}
 
-	var res *query.Result
-	var err error
-
-	// if calling ExecContext within a transaction, use it,
-	// otherwise use DB.
-	if s.tx != nil {
-		res, err = s.q.Exec(s.tx.Transaction, driverNamedValueToParams(args))
-	} else {
-		res, err = s.q.Run(ctx, s.db.DB, driverNamedValueToParams(args))
-	}
-
+	res, err := s.st.WithContext(ctx).Query(driverNamedValueToParams(args)...)
 	if err != nil {
 		return nil, err
 	}
 
-	// s.q.Run might return a stream if the last Statement is a Select,
+	// the statement might return a stream if the last Statement is a Select,
 	// make sure the result is closed before returning so any transaction
-	// created by s.q.Run is closed.
+	// created by the statement is closed.
 	return result{res}, res.Close()
 }
 
@@ -246,48 +253,24 @@ func (s stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (drive
 	default:
 	}
 
-	var res *query.Result
-	var err error
-
-	// if calling QueryContext within a transaction, use it,
-	// otherwise use DB.
-	if s.tx != nil {
-		res, err = s.q.Exec(s.tx.Transaction, driverNamedValueToParams(args))
-	} else {
-		res, err = s.q.Run(ctx, s.db.DB, driverNamedValueToParams(args))
-	}
-
+	res, err := s.st.WithContext(ctx).Query(driverNamedValueToParams(args)...)
 	if err != nil {
 		return nil, err
 	}
 
 	rs := newRecordStream(res)
-	if len(s.q.Statements) == 0 {
-		return rs, nil
-	}
-
-	lastStmt := s.q.Statements[len(s.q.Statements)-1]
-
-	tree, ok := lastStmt.(*planner.Tree)
-	if !ok {
-		return rs, nil
-	}
-
-	if pn, ok := tree.Root.(*planner.ProjectionNode); ok && len(pn.Expressions) > 0 {
-		rs.fields = make([]string, len(pn.Expressions))
-		for i := range pn.Expressions {
-			rs.fields[i] = pn.Expressions[i].Name()
-		}
-	}
+	rs.fields = s.st.Columns()
 
 	return rs, nil
 }
 
-func driverNamedValueToParams(args []driver.NamedValue) []expr.Param {
-	params := make([]expr.Param, len(args))
+func driverNamedValueToParams(args []driver.NamedValue) []interface{} {
+	params := make([]interface{}, len(args))
 	for i, arg := range args {
-		params[i].Name = arg.Name
-		params[i].Value = arg.Value
+		params[i] = expr.Param{
+			Name:  arg.Name,
+			Value: arg.Value,
+		}
 	}
 
 	return params
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
 	"github.com/stretchr/testify/require"
 )
@@ -132,6 +134,19 @@ func TestDriver(t *testing.T) {
 		require.Equal(t, 1, count)
 	})
 
+	t.Run("Prepared statement", func(t *testing.T) {
+		st, err := db.Prepare("SELECT a FROM test WHERE a = ?")
+		require.NoError(t, err)
+		defer st.Close()
+
+		for i := 0; i < 3; i++ {
+			var a int
+			err = st.QueryRow(i).Scan(&a)
+			require.NoError(t, err)
+			require.Equal(t, i, a)
+		}
+	})
+
 	t.Run("Transactions", func(t *testing.T) {
 		tx, err := db.Begin()
 		require.NoError(t, err)
@@ -211,4 +226,42 @@ func TestDriver(t *testing.T) {
 		`)
 		require.Equal(t, err, engine.ErrTransactionReadOnly)
 	})
+
+	t.Run("Rows affected by INSERT ... SELECT and RETURNING", func(t *testing.T) {
+		_, err := db.Exec("CREATE TABLE copy")
+		require.NoError(t, err)
+
+		for _, q := range []string{
+			"INSERT INTO copy SELECT * FROM test WHERE a < 3",
+			"INSERT INTO copy (a) VALUES (1), (2), (3) RETURNING a",
+		} {
+			res, err := db.Exec(q)
+			require.NoError(t, err)
+			n, err := res.RowsAffected()
+			require.NoError(t, err)
+			require.EqualValues(t, 3, n, q)
+		}
+	})
+}
+
+func TestConnector(t *testing.T) {
+	gdb, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer gdb.Close()
+
+	err = gdb.RegisterFunc("answer", func() document.Value {
+		return document.NewIntegerValue(42)
+	})
+	require.NoError(t, err)
+
+	db := sql.OpenDB(NewConnector(gdb))
+
+	var n int
+	err = db.QueryRow("SELECT answer() AS a").Scan(&n)
+	require.NoError(t, err)
+	require.Equal(t, 42, n)
+
+	// closing the sql.DB doesn't close the Genji database.
+	require.NoError(t, db.Close())
+	require.NoError(t, gdb.Exec("CREATE TABLE test"))
 }
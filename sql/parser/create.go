@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -24,9 +25,17 @@ func (p *Parser) parseCreateStatement() (query.Statement, error) {
 		return p.parseCreateIndexStatement(true)
 	case scanner.INDEX:
 		return p.parseCreateIndexStatement(false)
+	case scanner.VIEW:
+		return p.parseCreateViewStatement(false)
+	case scanner.MATERIALIZED:
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.VIEW {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"VIEW"}, pos)
+		}
+
+		return p.parseCreateViewStatement(true)
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "VIEW", "MATERIALIZED"}, pos)
 }
 
 // parseCreateTableStatement parses a create table string and returns a Statement AST object.
@@ -87,6 +96,13 @@ func (p *Parser) parseFieldDefinition(fc *database.FieldConstraint) (err error)
 		return err
 	}
 
+	if fc.Type == document.DecimalValue {
+		fc.Precision, fc.Scale, err = p.parseDecimalModifiers()
+		if err != nil {
+			return err
+		}
+	}
+
 	return p.parseFieldConstraint(fc)
 }
 
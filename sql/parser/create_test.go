@@ -126,6 +126,30 @@ func TestParserCreateTable(t *testing.T) {
 					},
 				},
 			}, false},
+		{"With timestamp type",
+			"CREATE TABLE test(created_at timestamp)",
+			query.CreateTableStmt{
+				TableName: "test",
+				Info: database.TableInfo{
+					FieldConstraints: []database.FieldConstraint{
+						{Path: parsePath(t, "created_at"), Type: document.TimestampValue},
+					},
+				},
+			}, false},
+		{"With decimal type",
+			"CREATE TABLE test(price DECIMAL(10, 2), rate NUMERIC(5), amount DECIMAL)",
+			query.CreateTableStmt{
+				TableName: "test",
+				Info: database.TableInfo{
+					FieldConstraints: []database.FieldConstraint{
+						{Path: parsePath(t, "price"), Type: document.DecimalValue, Precision: 10, Scale: 2},
+						{Path: parsePath(t, "rate"), Type: document.DecimalValue, Precision: 5},
+						{Path: parsePath(t, "amount"), Type: document.DecimalValue},
+					},
+				},
+			}, false},
+		{"With invalid decimal precision", "CREATE TABLE test(price DECIMAL(0))", query.CreateTableStmt{}, true},
+		{"With invalid decimal scale", "CREATE TABLE test(price DECIMAL(2, 3))", query.CreateTableStmt{}, true},
 		{"With integer aliases types",
 			"CREATE TABLE test(i int, ii int2, ei int8, m mediumint, s smallint, b bigint, t tinyint)",
 			query.CreateTableStmt{
@@ -221,3 +245,45 @@ func TestParserCreateIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestParserCreateView(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected query.Statement
+		errored  bool
+	}{
+		{"Basic", "CREATE VIEW v AS SELECT a FROM test", query.CreateViewStmt{ViewName: "v", Query: "SELECT a FROM test", Dependencies: []string{"test"}}, false},
+		{"If not exists", "CREATE VIEW IF NOT EXISTS v AS  SELECT a, COUNT(*) FROM test GROUP BY a ", query.CreateViewStmt{ViewName: "v", Query: "SELECT a, COUNT(*) FROM test GROUP BY a", IfNotExists: true, Dependencies: []string{"test"}}, false},
+		{"With CTE", "CREATE VIEW v AS WITH t AS (SELECT 1 AS a) SELECT a FROM t", query.CreateViewStmt{ViewName: "v", Query: "WITH t AS (SELECT 1 AS a) SELECT a FROM t"}, false},
+		{"Compound", "CREATE VIEW v AS SELECT a FROM foo UNION SELECT a FROM bar ORDER BY a", query.CreateViewStmt{ViewName: "v", Query: "SELECT a FROM foo UNION SELECT a FROM bar ORDER BY a", Dependencies: []string{"foo", "bar"}}, false},
+		{"Dependencies", "CREATE VIEW v AS SELECT foo.a FROM foo JOIN bar ON foo.a = bar.a JOIN foo AS f ON f.a = bar.a WHERE foo.a IN (SELECT a FROM baz)",
+			query.CreateViewStmt{ViewName: "v", Query: "SELECT foo.a FROM foo JOIN bar ON foo.a = bar.a JOIN foo AS f ON f.a = bar.a WHERE foo.a IN (SELECT a FROM baz)", Dependencies: []string{"foo", "bar", "baz"}}, false},
+		{"Materialized", "CREATE MATERIALIZED VIEW IF NOT EXISTS v AS SELECT a, COUNT(*) FROM test GROUP BY a", query.CreateViewStmt{ViewName: "v", Query: "SELECT a, COUNT(*) FROM test GROUP BY a", IfNotExists: true, Materialized: true, Dependencies: []string{"test"}}, false},
+		{"Materialized without VIEW", "CREATE MATERIALIZED v AS SELECT a FROM test", nil, true},
+		{"No AS", "CREATE VIEW v SELECT a FROM test", nil, true},
+		{"Not a SELECT", "CREATE VIEW v AS DELETE FROM test", nil, true},
+		{"Positional param", "CREATE VIEW v AS SELECT a FROM test WHERE a = ?", nil, true},
+		{"Named param", "CREATE VIEW v AS SELECT a FROM test WHERE a = $a", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+
+	t.Run("Followed by another statement", func(t *testing.T) {
+		q, err := ParseQuery("CREATE VIEW v AS SELECT a FROM test; SELECT * FROM v")
+		require.NoError(t, err)
+		require.Len(t, q.Statements, 2)
+		require.EqualValues(t, query.CreateViewStmt{ViewName: "v", Query: "SELECT a FROM test", Dependencies: []string{"test"}}, q.Statements[0])
+	})
+}
@@ -26,7 +26,19 @@ func (p *Parser) parseDeleteStatement() (*planner.Tree, error) {
 	}
 
 	// Parse condition: "WHERE EXPR".
-	cfg.WhereExpr, err = p.parseCondition()
+	refs, err := p.trackRefs(func() (err error) {
+		cfg.WhereExpr, err = p.parseCondition()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// documents are named after the table if subqueries refer to them.
+	cfg.NamedTable = refs[cfg.TableName]
+
+	// Parse "RETURNING expr, ...".
+	cfg.Returning, err = p.parseReturning()
 	if err != nil {
 		return nil, err
 	}
@@ -36,19 +48,29 @@ func (p *Parser) parseDeleteStatement() (*planner.Tree, error) {
 
 // DeleteConfig holds DELETE configuration.
 type deleteConfig struct {
-	TableName string
-	WhereExpr expr.Expr
+	TableName  string
+	NamedTable bool
+	WhereExpr  expr.Expr
+	Returning  []planner.ProjectedField
 }
 
 // ToTree turns the statement into an expression tree.
 func (cfg deleteConfig) ToTree() *planner.Tree {
 	t := planner.NewTableInputNode(cfg.TableName)
 
+	if cfg.NamedTable {
+		t = planner.NewRenameNode(t, cfg.TableName)
+	}
+
 	if cfg.WhereExpr != nil {
 		t = planner.NewSelectionNode(t, cfg.WhereExpr)
 	}
 
 	t = planner.NewDeletionNode(t, cfg.TableName)
 
+	if cfg.Returning != nil {
+		t = planner.NewReturningNode(t, cfg.Returning, cfg.TableName)
+	}
+
 	return &planner.Tree{Root: t}
 }
@@ -14,9 +14,17 @@ func (p *Parser) parseDropStatement() (query.Statement, error) {
 		return p.parseDropTableStatement()
 	case scanner.INDEX:
 		return p.parseDropIndexStatement()
+	case scanner.VIEW:
+		return p.parseDropViewStatement(false)
+	case scanner.MATERIALIZED:
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.VIEW {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"VIEW"}, pos)
+		}
+
+		return p.parseDropViewStatement(true)
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "VIEW", "MATERIALIZED"}, pos)
 }
 
 // parseDropTableStatement parses a drop table string and returns a Statement AST object.
@@ -74,3 +82,31 @@ func (p *Parser) parseDropIndexStatement() (query.DropIndexStmt, error) {
 
 	return stmt, nil
 }
+
+// parseDropViewStatement parses a drop view string and returns a Statement AST object.
+// This function assumes the DROP VIEW or DROP MATERIALIZED VIEW tokens have already been consumed.
+func (p *Parser) parseDropViewStatement(materialized bool) (query.DropViewStmt, error) {
+	stmt := query.DropViewStmt{Materialized: materialized}
+	var err error
+
+	// Parse "IF"
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.IF {
+		// Parse "EXISTS"
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.EXISTS {
+			return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"EXISTS"}, pos)
+		}
+		stmt.IfExists = true
+	} else {
+		p.Unscan()
+	}
+
+	// Parse view name
+	stmt.ViewName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"view_name"}
+		return stmt, pErr
+	}
+
+	return stmt, nil
+}
@@ -18,6 +18,10 @@ func TestParserDrop(t *testing.T) {
 		{"Drop table If not exists", "DROP TABLE IF EXISTS test", query.DropTableStmt{TableName: "test", IfExists: true}, false},
 		{"Drop index", "DROP INDEX test", query.DropIndexStmt{IndexName: "test"}, false},
 		{"Drop index if exists", "DROP INDEX IF EXISTS test", query.DropIndexStmt{IndexName: "test", IfExists: true}, false},
+		{"Drop view", "DROP VIEW test", query.DropViewStmt{ViewName: "test"}, false},
+		{"Drop view if exists", "DROP VIEW IF EXISTS test", query.DropViewStmt{ViewName: "test", IfExists: true}, false},
+		{"Drop materialized view", "DROP MATERIALIZED VIEW IF EXISTS test", query.DropViewStmt{ViewName: "test", IfExists: true, Materialized: true}, false},
+		{"Drop view without name", "DROP VIEW", nil, true},
 	}
 
 	for _, test := range tests {
@@ -84,12 +84,6 @@ func (p *Parser) parseOperator() (func(lhs, rhs expr.Expr) expr.Expr, scanner.To
 		return nil, 0, nil
 	}
 
-	// Ignore currently unused operators.
-	if op == scanner.EQREGEX || op == scanner.NEQREGEX {
-		p.Unscan()
-		return nil, 0, nil
-	}
-
 	switch op {
 	case scanner.EQ:
 		return expr.Eq, op, nil
@@ -143,6 +137,10 @@ func (p *Parser) parseOperator() (func(lhs, rhs expr.Expr) expr.Expr, scanner.To
 		return nil, 0, newParseError(scanner.Tokstr(tok, lit), []string{"IN, LIKE"}, pos)
 	case scanner.LIKE:
 		return expr.Like, op, nil
+	case scanner.EQREGEX:
+		return expr.Regex, op, nil
+	case scanner.NEQREGEX:
+		return expr.NotRegex, op, nil
 	}
 
 	panic(fmt.Sprintf("unknown operator %q", op))
@@ -155,6 +153,14 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 	case scanner.CAST:
 		p.Unscan()
 		return p.parseCastExpression()
+	case scanner.CASE:
+		return p.parseCaseExpr()
+	case scanner.INTERVAL:
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.STRING {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"string"}, pos)
+		}
+		return parseInterval(lit, pos)
 	case scanner.IDENT:
 		// if the next token is a left parenthesis, this is a function
 		if tok1, _, _ := p.Scan(); tok1 == scanner.LPAREN {
@@ -168,6 +174,13 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
+		// record the first part of qualified paths, they
+		// may refer to the name of a table.
+		if len(field) > 1 && p.refs != nil {
+			if _, ok := p.refs[field[0].FieldName]; !ok {
+				p.refs[field[0].FieldName] = false
+			}
+		}
 		fs := expr.Path(field)
 		return fs, nil
 	case scanner.NAMEDPARAM:
@@ -214,7 +227,27 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 	case scanner.LSBRACKET:
 		p.Unscan()
 		return p.parseExprList(scanner.LSBRACKET, scanner.RSBRACKET)
+	case scanner.EXISTS:
+		p.Unscan()
+		return p.parseExists()
+	case scanner.NOT:
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.EXISTS {
+			p.Unscan()
+			e, err := p.parseExists()
+			if err != nil {
+				return nil, err
+			}
+			e.Not = true
+			return e, nil
+		}
+		p.Unscan()
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"identifier", "string", "number", "bool"}, pos)
 	case scanner.LPAREN:
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.SELECT {
+			return p.parseSubquery()
+		}
+		p.Unscan()
+
 		e, _, err := p.ParseExpr()
 		if err != nil {
 			return nil, err
@@ -241,6 +274,63 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 	}
 }
 
+// parseSubquery parses a SELECT statement used as an expression.
+// This function assumes the left parenthesis and the SELECT token have already been consumed.
+func (p *Parser) parseSubquery() (*expr.Subquery, error) {
+	// the expressions of the subquery use their own buffer. the raw text of the subquery
+	// is captured and added to the current buffer once parsed.
+	var capture bytes.Buffer
+	buf := p.buf
+	p.buf = nil
+	p.captures = append(p.captures, &capture)
+
+	cfg, err := p.parseSelect()
+
+	p.captures = p.captures[:len(p.captures)-1]
+	p.buf = buf
+	if p.buf != nil {
+		p.buf.Write(capture.Bytes())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	t, err := cfg.ToTree()
+	if err != nil {
+		return nil, err
+	}
+
+	sq := expr.Subquery{Stmt: t, Correlated: cfg.Correlated}
+	p.subqueries = append(p.subqueries, &sq)
+	return &sq, nil
+}
+
+// parseExists parses the EXISTS operator, followed by a subquery.
+func (p *Parser) parseExists() (expr.Exists, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.EXISTS {
+		return expr.Exists{}, newParseError(scanner.Tokstr(tok, lit), []string{"EXISTS"}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return expr.Exists{}, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+		return expr.Exists{}, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+	}
+
+	sq, err := p.parseSubquery()
+	if err != nil {
+		return expr.Exists{}, err
+	}
+
+	return expr.Exists{Subquery: sq}, nil
+}
+
 // parseIdent parses an identifier.
 func (p *Parser) parseIdent() (string, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
@@ -310,6 +400,8 @@ func (p *Parser) parseType() (document.ValueType, error) {
 		return document.BoolValue, nil
 	case scanner.TYPEBYTES:
 		return document.BlobValue, nil
+	case scanner.TYPEDECIMAL, scanner.TYPENUMERIC:
+		return document.DecimalValue, nil
 	case scanner.TYPEDOCUMENT:
 		return document.DocumentValue, nil
 	case scanner.TYPEREAL:
@@ -326,6 +418,8 @@ func (p *Parser) parseType() (document.ValueType, error) {
 		return document.IntegerValue, nil
 	case scanner.TYPETEXT:
 		return document.TextValue, nil
+	case scanner.TYPETIMESTAMP:
+		return document.TimestampValue, nil
 	case scanner.TYPEVARCHAR, scanner.TYPECHARACTER:
 		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
 			return 0, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
@@ -347,6 +441,58 @@ func (p *Parser) parseType() (document.ValueType, error) {
 	return 0, nil
 }
 
+// maxDecimalPrecision is the maximum precision of a decimal type.
+const maxDecimalPrecision = 1000
+
+// parseDecimalModifiers parses the optional precision and scale of a decimal type,
+// e.g. DECIMAL(10, 2). If the scale is omitted, it defaults to 0.
+// If there are no modifiers, it returns a zero precision.
+func (p *Parser) parseDecimalModifiers() (precision, scale int, err error) {
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		p.Unscan()
+		return 0, 0, nil
+	}
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.INTEGER {
+		return 0, 0, newParseError(scanner.Tokstr(tok, lit), []string{"integer"}, pos)
+	}
+	precision, err = strconv.Atoi(lit)
+	if err != nil || precision < 1 || precision > maxDecimalPrecision {
+		return 0, 0, &ParseError{Message: fmt.Sprintf("decimal precision must be between 1 and %d", maxDecimalPrecision), Pos: pos}
+	}
+
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.COMMA {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.INTEGER {
+			return 0, 0, newParseError(scanner.Tokstr(tok, lit), []string{"integer"}, pos)
+		}
+		scale, err = strconv.Atoi(lit)
+		if err != nil || scale > precision {
+			return 0, 0, &ParseError{Message: fmt.Sprintf("decimal scale must be between 0 and %d", precision), Pos: pos}
+		}
+	} else {
+		p.Unscan()
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return 0, 0, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	return precision, scale, nil
+}
+
+// parseInterval parses the text of an interval literal
+// and returns the number of microseconds it represents.
+func parseInterval(s string, pos scanner.Pos) (expr.Expr, error) {
+	d, err := expr.ParseInterval(s)
+	if err != nil {
+		return nil, &ParseError{Message: err.Error(), Pos: pos}
+	}
+
+	return expr.IntegerValue(d.Microseconds()), nil
+}
+
 // parseDocument parses a document
 func (p *Parser) parseDocument() (expr.Expr, error) {
 	// Parse { token.
@@ -501,10 +647,96 @@ func (p *Parser) parseExprList(leftToken, rightToken scanner.Token) (expr.Litera
 	return p.parseExprListUntil(rightToken)
 }
 
-// parseFunction parses a function call.
+// parseFunction parses a function call, optionally followed by an OVER clause.
+func (p *Parser) parseFunction() (expr.Expr, error) {
+	e, err := p.parseFunctionCall()
+	if err != nil {
+		return nil, err
+	}
+
+	tok, pos, _ := p.ScanIgnoreWhitespace()
+	if tok != scanner.OVER {
+		p.Unscan()
+
+		switch e.(type) {
+		case expr.RowNumberFunc, expr.RankFunc, expr.DenseRankFunc, *expr.LagFunc, *expr.LeadFunc:
+			return nil, &ParseError{Message: fmt.Sprintf("window function %v requires an OVER clause", e), Pos: pos}
+		}
+
+		return e, nil
+	}
+
+	if !expr.IsWindowFunction(e) {
+		return nil, &ParseError{Message: fmt.Sprintf("%v cannot be used as a window function", e), Pos: pos}
+	}
+
+	w, err := p.parseWindow()
+	if err != nil {
+		return nil, err
+	}
+
+	return &expr.WindowFunc{Func: e, Window: w}, nil
+}
+
+// parseWindow parses the definition of a window in the form:
+// ([PARTITION BY expr, ...] [ORDER BY expr [ASC|DESC] [NULLS FIRST|NULLS LAST], ...]).
+// This function assumes the OVER token has already been consumed.
+func (p *Parser) parseWindow() (expr.Window, error) {
+	var w expr.Window
+
+	// Parse required ( token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return w, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	// Parse optional PARTITION BY clause.
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.PARTITION {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.BY {
+			return w, newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
+		}
+
+		for {
+			e, _, err := p.ParseExpr()
+			if err != nil {
+				return w, err
+			}
+			w.PartitionBy = append(w.PartitionBy, e)
+
+			if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+				p.Unscan()
+				break
+			}
+		}
+	} else {
+		p.Unscan()
+	}
+
+	// Parse optional ORDER BY clause.
+	keys, err := p.parseOrderBy()
+	if err != nil {
+		return w, err
+	}
+	for _, k := range keys {
+		w.OrderBy = append(w.OrderBy, expr.OrderingTerm{
+			Expr:       k.Expr,
+			Desc:       k.Direction == scanner.DESC,
+			NullsFirst: k.NullsFirst,
+			NullsLast:  k.NullsLast,
+		})
+	}
+
+	// Parse required ) token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return w, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	return w, nil
+}
+
+// parseFunctionCall parses a function call.
 // a function is an identifier followed by a parenthesis,
 // an optional coma-separated list of expressions and a closing parenthesis.
-func (p *Parser) parseFunction() (expr.Expr, error) {
+func (p *Parser) parseFunctionCall() (expr.Expr, error) {
 	// Parse function name.
 	fname, err := p.parseIdent()
 	if err != nil {
@@ -516,8 +748,16 @@ func (p *Parser) parseFunction() (expr.Expr, error) {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
 	}
 
+	// Parse optional DISTINCT qualifier, e.g. COUNT(DISTINCT a).
+	var distinct bool
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.DISTINCT {
+		distinct = true
+	} else {
+		p.Unscan()
+	}
+
 	// Special case: If the function is COUNT, support the special case COUNT(*)
-	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok == scanner.MUL {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok == scanner.MUL && !distinct {
 		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
 			return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 		}
@@ -527,7 +767,7 @@ func (p *Parser) parseFunction() (expr.Expr, error) {
 	p.Unscan()
 
 	// Check if the function is called without arguments.
-	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.RPAREN {
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.RPAREN && !distinct {
 		return p.functions.GetFunc(fname)
 	}
 	p.Unscan()
@@ -543,18 +783,102 @@ func (p *Parser) parseFunction() (expr.Expr, error) {
 
 		exprs = append(exprs, e)
 
-		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+		tok, _, _ := p.ScanIgnoreWhitespace()
+		// Special case: EXTRACT(field FROM expr)
+		if tok == scanner.FROM && len(exprs) == 1 && strings.EqualFold(fname, "extract") {
+			continue
+		}
+		if tok != scanner.COMMA {
 			p.Unscan()
 			break
 		}
 	}
 
 	// Parse required ) token.
-	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.RPAREN {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 	}
 
-	return p.functions.GetFunc(fname, exprs...)
+	e, err := p.functions.GetFunc(fname, exprs...)
+	if err != nil || !distinct {
+		return e, err
+	}
+
+	ds, ok := e.(expr.DistinctSetter)
+	if !ok {
+		return nil, &ParseError{Message: fmt.Sprintf("DISTINCT is not supported by %s()", strings.ToUpper(fname)), Pos: pos}
+	}
+	ds.SetDistinct()
+
+	return e, nil
+}
+
+// parseCaseExpr parses a CASE expression in its simple form:
+// CASE expr WHEN expr THEN expr [WHEN expr THEN expr ...] [ELSE expr] END
+// or in its searched form:
+// CASE WHEN cond THEN expr [WHEN cond THEN expr ...] [ELSE expr] END
+// This function assumes the CASE token has already been consumed.
+func (p *Parser) parseCaseExpr() (expr.Expr, error) {
+	var c expr.CaseExpr
+	var err error
+
+	// Parse optional operand.
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.WHEN {
+		p.Unscan()
+
+		c.Operand, _, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.WHEN {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"WHEN"}, pos)
+		}
+	}
+
+	// Parse WHEN clauses, the first WHEN token has already been consumed.
+	for {
+		var w expr.WhenClause
+
+		w.Cond, _, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.THEN {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"THEN"}, pos)
+		}
+
+		w.Then, _, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Whens = append(c.Whens, w)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.WHEN {
+			p.Unscan()
+			break
+		}
+	}
+
+	// Parse optional ELSE clause.
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.ELSE {
+		c.Else, _, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.Unscan()
+	}
+
+	// Parse required END token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.END {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"WHEN", "ELSE", "END"}, pos)
+	}
+
+	return c, nil
 }
 
 // parseCastExpression parses a string of the form CAST(expr AS type).
@@ -592,10 +916,19 @@ func (p *Parser) parseCastExpression() (expr.Expr, error) {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"type"}, pos)
 	}
 
+	cf := expr.CastFunc{Expr: e, CastAs: tp}
+
+	if tp == document.DecimalValue {
+		cf.Precision, cf.Scale, err = p.parseDecimalModifiers()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse required ) token.
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 	}
 
-	return expr.CastFunc{Expr: e, CastAs: tp}, nil
+	return cf, nil
 }
@@ -130,6 +130,8 @@ func TestParserExpr(t *testing.T) {
 		{"IN", "age IN ages", expr.In(expr.Path(parsePath(t, "age")), expr.Path(parsePath(t, "ages"))), false},
 		{"IS", "age IS NULL", expr.Is(expr.Path(parsePath(t, "age")), expr.NullValue()), false},
 		{"IS NOT", "age IS NOT NULL", expr.IsNot(expr.Path(parsePath(t, "age")), expr.NullValue()), false},
+		{"=~", "name =~ '^fo+'", expr.Regex(expr.Path(parsePath(t, "name")), expr.TextValue("^fo+")), false},
+		{"!~", "name !~ ?", expr.NotRegex(expr.Path(parsePath(t, "name")), expr.PositionalParam(1)), false},
 		{"precedence", "4 > 1 + 2", expr.Gt(
 			expr.IntegerValue(4),
 			expr.Add(
@@ -159,7 +161,52 @@ func TestParserExpr(t *testing.T) {
 		{"pk() function", "pk()", &expr.PKFunc{}, false},
 		{"count(expr) function", "count(a)", &expr.CountFunc{Expr: expr.Path(parsePath(t, "a"))}, false},
 		{"count(*) function", "count(*)", &expr.CountFunc{Wildcard: true}, false},
+		{"count(DISTINCT expr) function", "count(DISTINCT a)", &expr.CountFunc{Expr: expr.Path(parsePath(t, "a")), Distinct: true}, false},
+		{"sum(DISTINCT expr) function", "SUM(DISTINCT a + 1)", &expr.SumFunc{Expr: expr.Add(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)), Distinct: true}, false},
+		{"array_agg function", "ARRAY_AGG(a)", &expr.ArrayAggFunc{Expr: expr.Path(parsePath(t, "a"))}, false},
+		{"string_agg(DISTINCT expr) function", "STRING_AGG(DISTINCT a, ',')", &expr.StringAggFunc{Expr: expr.Path(parsePath(t, "a")), Separator: expr.TextValue(","), Distinct: true}, false},
+		{"string_agg without separator", "STRING_AGG(a)", nil, true},
+		{"stddev function", "stddev(a)", &expr.StdDevFunc{Expr: expr.Path(parsePath(t, "a"))}, false},
+		{"variance function", "variance(a)", &expr.VarianceFunc{Expr: expr.Path(parsePath(t, "a"))}, false},
+		{"count(DISTINCT *) function", "count(DISTINCT *)", nil, true},
+		{"DISTINCT without argument", "count(DISTINCT)", nil, true},
+		{"DISTINCT with scalar function", "LOWER(DISTINCT a)", nil, true},
 		{"CAST", "CAST(a.b[1][0] AS TEXT)", expr.CastFunc{Expr: expr.Path(parsePath(t, "a.b[1][0]")), CastAs: document.TextValue}, false},
+		{"CAST AS DECIMAL", "CAST(a AS DECIMAL)", expr.CastFunc{Expr: expr.Path(parsePath(t, "a")), CastAs: document.DecimalValue}, false},
+		{"CAST AS NUMERIC with precision", "CAST(a AS NUMERIC(10, 2))", expr.CastFunc{Expr: expr.Path(parsePath(t, "a")), CastAs: document.DecimalValue, Precision: 10, Scale: 2}, false},
+		{"CAST AS DECIMAL with invalid precision", "CAST(a AS DECIMAL(1001))", nil, true},
+		{"CAST AS TIMESTAMP", "CAST(a AS TIMESTAMP)", expr.CastFunc{Expr: expr.Path(parsePath(t, "a")), CastAs: document.TimestampValue}, false},
+		{"NOW", "NOW()", expr.NowFunc{}, false},
+		{"EXTRACT", "EXTRACT(YEAR FROM a)", expr.ScalarFunc{Name: "EXTRACT", Args: []expr.Expr{expr.TextValue("year"), expr.Path(parsePath(t, "a"))}}, false},
+		{"interval", "a + INTERVAL '1 day 2 hours'", expr.Add(expr.Path(parsePath(t, "a")), expr.IntegerValue(93600000000)), false},
+		{"interval with months", "INTERVAL '1 month'", nil, true},
+		{"interval with unknown unit", "INTERVAL '1 fortnight'", nil, true},
+		{"interval without string", "INTERVAL 1", nil, true},
+		{"field named interval", "`interval` + 1", expr.Add(expr.Path(parsePath(t, "`interval`")), expr.IntegerValue(1)), false},
+		{"case", "CASE WHEN a > 1 THEN 'big' WHEN a IS NULL THEN NULL ELSE 'small' END",
+			expr.CaseExpr{Whens: []expr.WhenClause{
+				{Cond: expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)), Then: expr.TextValue("big")},
+				{Cond: expr.Is(expr.Path(parsePath(t, "a")), expr.NullValue()), Then: expr.NullValue()},
+			}, Else: expr.TextValue("small")}, false},
+		{"simple case", "CASE a + 1 WHEN 1 THEN 'one' END",
+			expr.CaseExpr{Operand: expr.Add(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)), Whens: []expr.WhenClause{
+				{Cond: expr.IntegerValue(1), Then: expr.TextValue("one")},
+			}}, false},
+		{"case without when", "CASE a ELSE 1 END", nil, true},
+		{"case without end", "CASE WHEN a THEN 1", nil, true},
+		{"window function", "ROW_NUMBER() OVER (PARTITION BY a, b ORDER BY c DESC NULLS LAST)",
+			&expr.WindowFunc{Func: expr.RowNumberFunc{}, Window: expr.Window{
+				PartitionBy: []expr.Expr{expr.Path(parsePath(t, "a")), expr.Path(parsePath(t, "b"))},
+				OrderBy:     []expr.OrderingTerm{{Expr: expr.Path(parsePath(t, "c")), Desc: true, NullsLast: true}},
+			}}, false},
+		{"window aggregate", "sum(a) OVER ()", &expr.WindowFunc{Func: &expr.SumFunc{Expr: expr.Path(parsePath(t, "a"))}}, false},
+		{"lag function", "LAG(a, 2, 0) OVER (ORDER BY b)",
+			&expr.WindowFunc{Func: &expr.LagFunc{Expr: expr.Path(parsePath(t, "a")), Offset: expr.IntegerValue(2), Default: expr.IntegerValue(0)}, Window: expr.Window{
+				OrderBy: []expr.OrderingTerm{{Expr: expr.Path(parsePath(t, "b"))}},
+			}}, false},
+		{"window function without over", "RANK()", nil, true},
+		{"window function with unclosed over", "RANK() OVER (ORDER BY a", nil, true},
+		{"over with a scalar function", "pk() OVER ()", nil, true},
 	}
 
 	for _, test := range tests {
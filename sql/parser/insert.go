@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -10,7 +12,7 @@ import (
 
 // parseInsertStatement parses an insert string and returns a Statement AST object.
 // This function assumes the INSERT token has already been consumed.
-func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
+func (p *Parser) parseInsertStatement() (query.Statement, error) {
 	var stmt query.InsertStmt
 	var err error
 
@@ -42,6 +44,12 @@ func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
 		stmt.FieldNames = fields
 	}
 
+	// Parse SELECT ...
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.SELECT {
+		return p.parseInsertSelect(stmt.TableName, stmt.FieldNames)
+	}
+	p.Unscan()
+
 	// Parse VALUES (v1, v2, v3)
 	values, err := p.parseValues(valueParser)
 	if err != nil {
@@ -59,9 +67,135 @@ func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
 	}
 
 	stmt.Values = values
+
+	// Parse ON CONFLICT clause
+	stmt.OnConflict, err = p.parseOnConflictClause()
+	if err != nil {
+		return stmt, err
+	}
+
+	// Parse RETURNING clause
+	returning, err := p.parseReturning()
+	if err != nil {
+		return stmt, err
+	}
+
+	// the inserted documents can only be returned by a tree.
+	if returning != nil {
+		t := planner.NewValuesInputNode(stmt.Values, stmt.FieldNames)
+		t = planner.NewInsertionNode(t, stmt.TableName, nil, stmt.OnConflict)
+		return planner.NewTree(planner.NewReturningNode(t, returning, stmt.TableName)), nil
+	}
+
 	return stmt, nil
 }
 
+// parseInsertSelect parses the SELECT statement of an INSERT ... SELECT statement
+// and returns a tree that inserts the selected documents in the table.
+// This function assumes the SELECT token has already been consumed.
+func (p *Parser) parseInsertSelect(tableName string, fieldNames []string) (*planner.Tree, error) {
+	t, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	oc, err := p.parseOnConflictClause()
+	if err != nil {
+		return nil, err
+	}
+
+	returning, err := p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+
+	n := planner.NewInsertionNode(t.Root, tableName, fieldNames, oc)
+	if returning != nil {
+		n = planner.NewReturningNode(n, returning, tableName)
+	}
+
+	return planner.NewTree(n), nil
+}
+
+// parseOnConflictClause parses the optional ON CONFLICT clause of an INSERT statement:
+//   ON CONFLICT [(path) | ON INDEX index_name] DO NOTHING
+//   ON CONFLICT [(path) | ON INDEX index_name] DO UPDATE SET path = expr, ...
+// CONFLICT, DO and NOTHING are not keywords, to allow using them as field names.
+func (p *Parser) parseOnConflictClause() (*query.OnConflict, error) {
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.ON {
+		p.Unscan()
+		return nil, nil
+	}
+
+	if err := p.parseNonReservedWord("CONFLICT"); err != nil {
+		return nil, err
+	}
+
+	var oc query.OnConflict
+	var err error
+
+	// Parse optional conflict target
+	switch tok, _, _ := p.ScanIgnoreWhitespace(); tok {
+	case scanner.LPAREN:
+		oc.Target.Path, err = p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+		}
+	case scanner.ON:
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.INDEX {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"INDEX"}, pos)
+		}
+
+		oc.Target.IndexName, err = p.parseIdent()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"index_name"}
+			return nil, pErr
+		}
+	default:
+		p.Unscan()
+	}
+
+	if err := p.parseNonReservedWord("DO"); err != nil {
+		return nil, err
+	}
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.IDENT && strings.EqualFold(lit, "NOTHING"):
+	case tok == scanner.UPDATE:
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SET {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SET"}, pos)
+		}
+
+		pairs, err := p.parseSetClause()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sp := range pairs {
+			oc.Set = append(oc.Set, query.OnConflictSetPair{Path: sp.path, Expr: sp.e})
+		}
+	default:
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"NOTHING", "UPDATE"}, pos)
+	}
+
+	return &oc, nil
+}
+
+// parseNonReservedWord parses an identifier that must be equal to word, regardless of the case.
+func (p *Parser) parseNonReservedWord(word string) error {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.IDENT || !strings.EqualFold(lit, word) {
+		return newParseError(scanner.Tokstr(tok, lit), []string{word}, pos)
+	}
+
+	return nil
+}
+
 // parseFieldList parses a list of fields in the form: (path, path, ...), if exists
 func (p *Parser) parseFieldList() ([]string, bool, error) {
 	// Parse ( token.
@@ -89,7 +223,7 @@ func (p *Parser) parseFieldList() ([]string, bool, error) {
 func (p *Parser) parseValues(valueParser func() (expr.Expr, error)) (expr.LiteralExprList, error) {
 	// Check if the VALUES token exists.
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.VALUES {
-		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"VALUES"}, pos)
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"VALUES", "SELECT"}, pos)
 	}
 
 	var valuesList expr.LiteralExprList
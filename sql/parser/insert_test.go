@@ -3,6 +3,8 @@ package parser
 import (
 	"testing"
 
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/stretchr/testify/require"
@@ -75,6 +77,120 @@ func TestParserInsert(t *testing.T) {
 			nil, true},
 		{"Values / Without fields / Wrong values", "INSERT INTO test VALUES {a: 1}, ('e', 'f')",
 			nil, true},
+		{"Select", "INSERT INTO test SELECT * FROM foo WHERE a > 1",
+			planner.NewTree(
+				planner.NewInsertionNode(
+					planner.NewProjectionNode(
+						planner.NewSelectionNode(
+							planner.NewTableInputNode("foo"),
+							expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)),
+						),
+						[]planner.ProjectedField{planner.Wildcard{}},
+						"foo",
+					),
+					"test",
+					nil,
+					nil,
+				)),
+			false},
+		{"Select / With fields", "INSERT INTO test (a, b) SELECT c, d FROM foo",
+			planner.NewTree(
+				planner.NewInsertionNode(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("foo"),
+						[]planner.ProjectedField{
+							planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "c")), ExprName: "c"},
+							planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "d")), ExprName: "d"},
+						},
+						"foo",
+					),
+					"test",
+					[]string{"a", "b"},
+					nil,
+				)),
+			false},
+		{"Select / Without select", "INSERT INTO test (a, b) * FROM foo",
+			nil, true},
+		{"On conflict / Do nothing", "INSERT INTO test (a, b) VALUES (1, 2) ON CONFLICT DO NOTHING",
+			query.InsertStmt{
+				TableName:  "test",
+				FieldNames: []string{"a", "b"},
+				Values: expr.LiteralExprList{
+					expr.LiteralExprList{expr.IntegerValue(1), expr.IntegerValue(2)},
+				},
+				OnConflict: &query.OnConflict{},
+			}, false},
+		{"On conflict / Path / Do update", "INSERT INTO test VALUES {a: 1} ON CONFLICT (a) DO UPDATE SET b = excluded.b, c = c + 1",
+			query.InsertStmt{
+				TableName: "test",
+				Values: expr.LiteralExprList{
+					expr.KVPairs{expr.KVPair{K: "a", V: expr.IntegerValue(1)}},
+				},
+				OnConflict: &query.OnConflict{
+					Target: database.ConflictTarget{Path: parsePath(t, "a")},
+					Set: []query.OnConflictSetPair{
+						{Path: parsePath(t, "b"), Expr: expr.Path(parsePath(t, "excluded.b"))},
+						{Path: parsePath(t, "c"), Expr: expr.Add(expr.Path(parsePath(t, "c")), expr.IntegerValue(1))},
+					},
+				},
+			}, false},
+		{"On conflict / Index / Do nothing", "INSERT INTO test SELECT * FROM foo ON CONFLICT ON INDEX idx_foo DO NOTHING",
+			planner.NewTree(
+				planner.NewInsertionNode(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("foo"),
+						[]planner.ProjectedField{planner.Wildcard{}},
+						"foo",
+					),
+					"test",
+					nil,
+					&query.OnConflict{Target: database.ConflictTarget{IndexName: "idx_foo"}},
+				)),
+			false},
+		{"On conflict / Without action", "INSERT INTO test VALUES {a: 1} ON CONFLICT", nil, true},
+		{"On conflict / Invalid action", "INSERT INTO test VALUES {a: 1} ON CONFLICT DO SOMETHING", nil, true},
+		{"On conflict / Do update without set", "INSERT INTO test VALUES {a: 1} ON CONFLICT DO UPDATE a = 1", nil, true},
+		{"Returning", "INSERT INTO test (a, b) VALUES (1, 2) ON CONFLICT DO NOTHING RETURNING *, pk()",
+			planner.NewTree(
+				planner.NewReturningNode(
+					planner.NewInsertionNode(
+						planner.NewValuesInputNode(
+							expr.LiteralExprList{
+								expr.LiteralExprList{expr.IntegerValue(1), expr.IntegerValue(2)},
+							},
+							[]string{"a", "b"},
+						),
+						"test",
+						nil,
+						&query.OnConflict{},
+					),
+					[]planner.ProjectedField{
+						planner.Wildcard{},
+						planner.ProjectedExpr{Expr: new(expr.PKFunc), ExprName: "pk()"},
+					},
+					"test",
+				)),
+			false},
+		{"Returning / Select", "INSERT INTO test SELECT * FROM foo RETURNING a AS b",
+			planner.NewTree(
+				planner.NewReturningNode(
+					planner.NewInsertionNode(
+						planner.NewProjectionNode(
+							planner.NewTableInputNode("foo"),
+							[]planner.ProjectedField{planner.Wildcard{}},
+							"foo",
+						),
+						"test",
+						nil,
+						nil,
+					),
+					[]planner.ProjectedField{
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "b"},
+					},
+					"test",
+				)),
+			false},
+		{"Returning / Without fields", "INSERT INTO test VALUES {a: 1} RETURNING", nil, true},
 	}
 
 	for _, test := range tests {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -19,6 +20,24 @@ type Parser struct {
 	namedParams   int
 	buf           *bytes.Buffer
 	functions     expr.Functions
+	// true if the parser uses the builtin functions only.
+	builtinFunctions bool
+
+	// captures record the raw text of the subqueries being parsed.
+	captures []*bytes.Buffer
+	// refs contains the names used to qualify paths in the statement being parsed.
+	// names used by subqueries to refer to the statement are set to true.
+	refs map[string]bool
+	// ctes contains the common table expressions of the statement being parsed.
+	ctes map[string]*cte
+	// views contains the names of the views being expanded,
+	// if the statement being parsed is the statement of a view.
+	views []string
+	// subqueries contains the subqueries of the statement being parsed.
+	subqueries []*expr.Subquery
+	// tables contains the names of the tables and views read by the statement
+	// being parsed, if it is the statement of a view being created.
+	tables []string
 }
 
 // NewParser returns a new instance of Parser.
@@ -28,11 +47,12 @@ func NewParser(r io.Reader) *Parser {
 
 // NewParserWithOptions returns a new instance of Parser using given Options.
 func NewParserWithOptions(r io.Reader, opts *Options) *Parser {
+	builtinFunctions := opts == nil
 	if opts == nil {
 		opts = defaultOptions()
 	}
 
-	return &Parser{s: scanner.NewBufScanner(r), functions: opts.Functions}
+	return &Parser{s: scanner.NewBufScanner(r), functions: opts.Functions, builtinFunctions: builtinFunctions}
 }
 
 // ParseQuery parses a query string and returns its AST representation.
@@ -72,6 +92,23 @@ func (p *Parser) ParseQuery() (query.Query, error) {
 
 // ParseStatement parses a Genji SQL string and returns a Statement AST object.
 func (p *Parser) ParseStatement() (query.Statement, error) {
+	parent := p.subqueries
+	p.subqueries = nil
+	defer func() { p.subqueries = parent }()
+
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	if t, ok := stmt.(*planner.Tree); ok {
+		t.Subqueries = p.subqueries
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseStatement() (query.Statement, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.ALTER:
@@ -82,6 +119,8 @@ func (p *Parser) ParseStatement() (query.Statement, error) {
 		return p.parseCommitStatement()
 	case scanner.SELECT:
 		return p.parseSelectStatement()
+	case scanner.WITH:
+		return p.parseWithStatement()
 	case scanner.DELETE:
 		return p.parseDeleteStatement()
 	case scanner.UPDATE:
@@ -94,6 +133,8 @@ func (p *Parser) ParseStatement() (query.Statement, error) {
 		return p.parseDropStatement()
 	case scanner.EXPLAIN:
 		return p.parseExplainStatement()
+	case scanner.REFRESH:
+		return p.parseRefreshStatement()
 	case scanner.REINDEX:
 		return p.parseReIndexStatement()
 	case scanner.ROLLBACK:
@@ -101,10 +142,21 @@ func (p *Parser) ParseStatement() (query.Statement, error) {
 	}
 
 	return nil, newParseError(scanner.Tokstr(tok, lit), []string{
-		"ALTER", "BEGIN", "COMMIT", "SELECT", "DELETE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "REINDEX", "ROLLBACK",
+		"ALTER", "BEGIN", "COMMIT", "SELECT", "WITH", "DELETE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "REFRESH", "REINDEX", "ROLLBACK",
 	}, pos)
 }
 
+// trackRefs returns the names used to qualify the paths
+// parsed while running fn.
+func (p *Parser) trackRefs(fn func() error) (map[string]bool, error) {
+	parent := p.refs
+	p.refs = make(map[string]bool)
+	defer func() { p.refs = parent }()
+
+	err := fn()
+	return p.refs, err
+}
+
 // parseCondition parses the "WHERE" clause of the query, if it exists.
 func (p *Parser) parseCondition() (expr.Expr, error) {
 	// Check if the WHERE token exists.
@@ -122,6 +174,17 @@ func (p *Parser) parseCondition() (expr.Expr, error) {
 	return expr, nil
 }
 
+// parseReturning parses the "RETURNING" clause of a write statement, if it exists.
+func (p *Parser) parseReturning() ([]planner.ProjectedField, error) {
+	// Check if the RETURNING token exists.
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.RETURNING {
+		p.Unscan()
+		return nil, nil
+	}
+
+	return p.parseResultFields()
+}
+
 // parsePathList parses a list of paths in the form: (path, path, ...), if exists
 func (p *Parser) parsePathList() ([]document.Path, error) {
 	// Parse ( token.
@@ -169,6 +232,9 @@ func (p *Parser) Scan() (tok scanner.Token, pos scanner.Pos, lit string) {
 	if p.buf != nil {
 		p.buf.WriteString(ti.Raw)
 	}
+	for _, c := range p.captures {
+		c.WriteString(ti.Raw)
+	}
 
 	tok, pos, lit = ti.Tok, ti.Pos, ti.Lit
 	return
@@ -187,10 +253,13 @@ func (p *Parser) ScanIgnoreWhitespace() (tok scanner.Token, pos scanner.Pos, lit
 
 // Unscan pushes the previously read token back onto the buffer.
 func (p *Parser) Unscan() {
+	ti := p.s.Curr()
 	if p.buf != nil {
-		ti := p.s.Curr()
 		p.buf.Truncate(p.buf.Len() - len(ti.Raw))
 	}
+	for _, c := range p.captures {
+		c.Truncate(c.Len() - len(ti.Raw))
+	}
 	p.s.Unscan()
 }
 
@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// parseRefreshStatement parses a refresh materialized view string and returns a Statement AST object.
+// This function assumes the REFRESH token has already been consumed.
+func (p *Parser) parseRefreshStatement() (query.RefreshViewStmt, error) {
+	var stmt query.RefreshViewStmt
+	var err error
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.MATERIALIZED {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"MATERIALIZED"}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.VIEW {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"VIEW"}, pos)
+	}
+
+	// Parse view name
+	stmt.ViewName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"view_name"}
+		return stmt, pErr
+	}
+
+	return stmt, nil
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/sql/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRefresh(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected query.Statement
+		errored  bool
+	}{
+		{"Basic", "REFRESH MATERIALIZED VIEW v", query.RefreshViewStmt{ViewName: "v"}, false},
+		{"No MATERIALIZED", "REFRESH VIEW v", nil, true},
+		{"No view name", "REFRESH MATERIALIZED VIEW", nil, true},
+		{"With extra", "REFRESH MATERIALIZED VIEW v v", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
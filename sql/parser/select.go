@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -11,61 +14,191 @@ import (
 // parseSelectStatement parses a select string and returns a Statement AST object.
 // This function assumes the SELECT token has already been consumed.
 func (p *Parser) parseSelectStatement() (*planner.Tree, error) {
+	cfg, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.ToTree()
+}
+
+// parseSelect parses a select string, optionally combined with other select strings
+// using UNION [ALL], INTERSECT or EXCEPT, and returns its configuration.
+// This function assumes the SELECT token has already been consumed.
+func (p *Parser) parseSelect() (*selectConfig, error) {
+	cfg, err := p.parseSimpleSelect()
+	if err != nil {
+		return nil, err
+	}
+
+	last := cfg
+	for {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.UNION && tok != scanner.INTERSECT && tok != scanner.EXCEPT {
+			p.Unscan()
+			break
+		}
+
+		// ORDER BY, LIMIT and OFFSET apply to the result of the compound statement,
+		// they can only be used after the last SELECT.
+		if last.OrderBy != nil || last.LimitExpr != nil || last.OffsetExpr != nil {
+			return nil, &ParseError{Message: fmt.Sprintf("ORDER BY, LIMIT and OFFSET must come after the last SELECT, found %s", scanner.Tokstr(tok, lit)), Pos: pos}
+		}
+
+		cc := compoundConfig{Operator: tok}
+
+		if tok == scanner.UNION {
+			if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.ALL {
+				cc.All = true
+			} else {
+				p.Unscan()
+			}
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+		}
+
+		cc.Select, err = p.parseSimpleSelect()
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Compound = append(cfg.Compound, cc)
+		cfg.Correlated = cfg.Correlated || cc.Select.Correlated
+		last = cc.Select
+	}
+
+	if last != cfg {
+		cfg.OrderBy, cfg.LimitExpr, cfg.OffsetExpr = last.OrderBy, last.LimitExpr, last.OffsetExpr
+		last.OrderBy, last.LimitExpr, last.OffsetExpr = nil, nil, nil
+	}
+
+	return cfg, nil
+}
+
+// parseSimpleSelect parses a single select string and returns its configuration.
+// This function assumes the SELECT token has already been consumed.
+func (p *Parser) parseSimpleSelect() (*selectConfig, error) {
 	var cfg selectConfig
+
+	refs, err := p.trackRefs(func() error {
+		return p.parseSelectClauses(&cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// within a subquery, names that don't refer to any of the tables of the query
+	// may refer to the tables of an outer query.
+	// otherwise, they are the first part of a nested path.
+	if p.refs != nil {
+		names := cfg.names()
+		for name := range refs {
+			if names[name] {
+				continue
+			}
+
+			cfg.Correlated = true
+			p.refs[name] = true
+		}
+	}
+
+	// documents must be named if subqueries refer to them using the name of the table,
+	// or if they can be used to refer to the documents of the outer query.
+	cfg.NamedTable = cfg.Correlated || (cfg.TableName != "" && refs[cfg.TableName])
+	cfg.parseView = p.viewParser()
+
+	return &cfg, nil
+}
+
+func (p *Parser) parseSelectClauses(cfg *selectConfig) error {
 	var err error
 
 	cfg.Distinct, err = p.parseDistinct()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Parse path list or query.Wildcard
 	cfg.ProjectionExprs, err = p.parseResultFields()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Parse "FROM".
 	var found bool
-	cfg.TableName, found, err = p.parseFrom()
+	cfg.TableName, cfg.TableSubquery, found, err = p.parseFrom()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if !found {
-		return cfg.ToTree()
+		return nil
+	}
+	if cfg.TableSubquery == nil {
+		cfg.TableCTE = p.lookupCTE(cfg.TableName)
+		if cfg.TableCTE == nil {
+			p.addTable(cfg.TableName)
+		}
+	}
+
+	// Parse optional table alias: "[AS] alias"
+	cfg.TableAlias, err = p.parseTableAlias()
+	if err != nil {
+		return err
+	}
+	if cfg.TableSubquery != nil && cfg.TableAlias == "" {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		p.Unscan()
+		return &ParseError{Message: "subquery in FROM must have an alias", Found: scanner.Tokstr(tok, lit), Pos: pos}
+	}
+
+	// Parse joins: "[INNER | LEFT [OUTER]] JOIN table_name [[AS] alias] ON expr"
+	cfg.Joins, err = p.parseJoins()
+	if err != nil {
+		return err
 	}
 
 	// Parse condition: "WHERE expr".
 	cfg.WhereExpr, err = p.parseCondition()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Parse group by: "GROUP BY expr"
-	cfg.GroupByExpr, err = p.parseGroupBy()
+	// Parse group by: "GROUP BY expr, ..."
+	cfg.GroupByExprs, err = p.parseGroupBy()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Parse order by: "ORDER BY path [ASC|DESC]?"
-	cfg.OrderBy, cfg.OrderByDirection, err = p.parseOrderBy()
+	// Parse having: "HAVING expr"
+	var havingPos scanner.Pos
+	cfg.HavingExpr, havingPos, err = p.parseHaving()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// without GROUP BY, HAVING filters the result of the aggregation
+	// of all the documents, which requires aggregate functions.
+	if cfg.HavingExpr != nil && len(cfg.GroupByExprs) == 0 && !cfg.aggregates() {
+		return &ParseError{Message: "HAVING requires GROUP BY or aggregate functions", Found: "HAVING", Pos: havingPos}
+	}
+
+	// Parse order by: "ORDER BY expr [ASC|DESC]? [NULLS FIRST|NULLS LAST]?, ..."
+	cfg.OrderBy, err = p.parseOrderBy()
+	if err != nil {
+		return err
 	}
 
 	// Parse limit: "LIMIT expr"
 	cfg.LimitExpr, err = p.parseLimit()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Parse offset: "OFFSET expr"
 	cfg.OffsetExpr, err = p.parseOffset()
-	if err != nil {
-		return nil, err
-	}
-
-	return cfg.ToTree()
+	return err
 }
 
 // parseResultFields parses the list of result fields.
@@ -136,24 +269,121 @@ func (p *Parser) parseDistinct() (bool, error) {
 	return true, nil
 }
 
-func (p *Parser) parseFrom() (string, bool, error) {
+// parseFrom parses the FROM clause, which is either followed by a table name
+// or by a subquery.
+func (p *Parser) parseFrom() (string, *planner.Tree, bool, error) {
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.FROM {
 		p.Unscan()
-		return "", false, nil
+		return "", nil, false, nil
+	}
+
+	// Parse subquery: "(SELECT ...)"
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.LPAREN {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+			return "", nil, true, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+		}
+
+		cfg, err := p.parseSelect()
+		if err != nil {
+			return "", nil, true, err
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+			return "", nil, true, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+		}
+
+		t, err := cfg.ToTree()
+		return "", t, true, err
 	}
+	p.Unscan()
 
 	// Parse table name
 	ident, err := p.parseIdent()
 	if err != nil {
 		pErr := err.(*ParseError)
 		pErr.Expected = []string{"table_name"}
-		return ident, true, pErr
+		return ident, nil, true, pErr
+	}
+
+	return ident, nil, true, nil
+}
+
+// parseTableAlias parses an optional table alias, preceded
+// or not by the AS keyword.
+func (p *Parser) parseTableAlias() (string, error) {
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.AS:
+		return p.parseIdent()
+	case scanner.IDENT:
+		return lit, nil
 	}
+	p.Unscan()
+
+	return "", nil
+}
+
+// parseJoins parses a list of optional join clauses.
+func (p *Parser) parseJoins() ([]joinConfig, error) {
+	var joins []joinConfig
+
+	for {
+		var jc joinConfig
+
+		tok, _, _ := p.ScanIgnoreWhitespace()
+		switch tok {
+		case scanner.JOIN:
+		case scanner.INNER:
+			if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.JOIN {
+				return nil, newParseError(scanner.Tokstr(tok, lit), []string{"JOIN"}, pos)
+			}
+		case scanner.LEFT:
+			jc.Type = planner.LeftJoin
+
+			if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.OUTER {
+				p.Unscan()
+			}
+			if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.JOIN {
+				return nil, newParseError(scanner.Tokstr(tok, lit), []string{"JOIN"}, pos)
+			}
+		default:
+			p.Unscan()
+			return joins, nil
+		}
+
+		// Parse table name
+		var err error
+		jc.TableName, err = p.parseIdent()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"table_name"}
+			return nil, pErr
+		}
+		jc.CTE = p.lookupCTE(jc.TableName)
+		if jc.CTE == nil {
+			p.addTable(jc.TableName)
+		}
+
+		jc.Alias, err = p.parseTableAlias()
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse join condition
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.ON {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"ON"}, pos)
+		}
 
-	return ident, true, nil
+		jc.On, _, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		joins = append(joins, jc)
+	}
 }
 
-func (p *Parser) parseGroupBy() (expr.Expr, error) {
+func (p *Parser) parseGroupBy() ([]expr.Expr, error) {
 	// parse GROUP token
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.GROUP {
 		p.Unscan()
@@ -165,36 +395,98 @@ func (p *Parser) parseGroupBy() (expr.Expr, error) {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
 	}
 
+	var exprs []expr.Expr
+	for {
+		e, _, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			return exprs, nil
+		}
+	}
+}
+
+func (p *Parser) parseHaving() (expr.Expr, scanner.Pos, error) {
+	// parse HAVING token
+	tok, pos, _ := p.ScanIgnoreWhitespace()
+	if tok != scanner.HAVING {
+		p.Unscan()
+		return nil, pos, nil
+	}
+
 	// parse expr
 	e, _, err := p.ParseExpr()
-	return e, err
+	return e, pos, err
 }
 
-func (p *Parser) parseOrderBy() (expr.Path, scanner.Token, error) {
+func (p *Parser) parseOrderBy() ([]planner.SortKey, error) {
 	// parse ORDER token
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.ORDER {
 		p.Unscan()
-		return nil, 0, nil
+		return nil, nil
 	}
 
 	// parse BY token
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.BY {
-		return nil, 0, newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
+	}
+
+	var keys []planner.SortKey
+	for {
+		key, err := p.parseSortKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			return keys, nil
+		}
 	}
+}
 
-	// parse path
-	path, err := p.parsePath()
+// parseSortKey parses an expression followed by an optional direction
+// and an optional NULLS FIRST or NULLS LAST clause.
+func (p *Parser) parseSortKey() (planner.SortKey, error) {
+	var key planner.SortKey
+	var err error
+
+	// parse expr
+	key.Expr, _, err = p.ParseExpr()
 	if err != nil {
-		return nil, 0, err
+		return key, err
 	}
 
 	// parse optional ASC or DESC
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.ASC || tok == scanner.DESC {
-		return expr.Path(path), tok, nil
+		key.Direction = tok
+	} else {
+		p.Unscan()
+	}
+
+	// parse optional NULLS FIRST or NULLS LAST.
+	// NULLS, FIRST and LAST are not keywords, to allow using them as field names.
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok != scanner.IDENT || !strings.EqualFold(lit, "NULLS") {
+		p.Unscan()
+		return key, nil
 	}
-	p.Unscan()
 
-	return expr.Path(path), 0, nil
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.IDENT && strings.EqualFold(lit, "FIRST"):
+		key.NullsFirst = true
+	case tok == scanner.IDENT && strings.EqualFold(lit, "LAST"):
+		key.NullsLast = true
+	default:
+		return key, newParseError(scanner.Tokstr(tok, lit), []string{"FIRST", "LAST"}, pos)
+	}
+
+	return key, nil
 }
 
 func (p *Parser) parseLimit() (expr.Expr, error) {
@@ -219,43 +511,269 @@ func (p *Parser) parseOffset() (expr.Expr, error) {
 	return e, err
 }
 
+// joinConfig holds the configuration of a JOIN clause.
+type joinConfig struct {
+	Type      planner.JoinType
+	TableName string
+	CTE       *cte
+	Alias     string
+	On        expr.Expr
+}
+
+// name returns the name used to qualify the documents of the joined table.
+func (jc joinConfig) name() string {
+	if jc.Alias != "" {
+		return jc.Alias
+	}
+
+	return jc.TableName
+}
+
 // SelectConfig holds SELECT configuration.
 type selectConfig struct {
-	TableName        string
-	Distinct         bool
-	WhereExpr        expr.Expr
-	GroupByExpr      expr.Expr
-	OrderBy          expr.Path
-	OrderByDirection scanner.Token
-	OffsetExpr       expr.Expr
-	LimitExpr        expr.Expr
-	ProjectionExprs  []planner.ProjectedField
+	TableName       string
+	TableSubquery   *planner.Tree
+	TableCTE        *cte
+	TableAlias      string
+	NamedTable      bool
+	Correlated      bool
+	Joins           []joinConfig
+	Distinct        bool
+	WhereExpr       expr.Expr
+	GroupByExprs    []expr.Expr
+	HavingExpr      expr.Expr
+	OrderBy         []planner.SortKey
+	OffsetExpr      expr.Expr
+	LimitExpr       expr.Expr
+	ProjectionExprs []planner.ProjectedField
+
+	// statements combined with this one, in order.
+	Compound []compoundConfig
+
+	// parses the statements of the views the tables may refer to.
+	parseView planner.ViewParser
 }
 
-// ToTree turns the statement into an expression tree.
-func (cfg selectConfig) ToTree() (*planner.Tree, error) {
-	var n planner.Node
+// compoundConfig holds the configuration of a SELECT statement
+// combined with the previous one using UNION, INTERSECT or EXCEPT.
+type compoundConfig struct {
+	Operator scanner.Token
+	All      bool
+	Select   *selectConfig
+}
 
-	if cfg.TableName != "" {
-		n = planner.NewTableInputNode(cfg.TableName)
+// name returns the name used to qualify the documents of the table.
+func (cfg selectConfig) name() string {
+	if cfg.TableAlias != "" {
+		return cfg.TableAlias
 	}
 
-	if cfg.WhereExpr != nil {
-		n = planner.NewSelectionNode(n, cfg.WhereExpr)
+	return cfg.TableName
+}
+
+// names returns the names used to qualify the documents
+// of every table of the query.
+func (cfg selectConfig) names() map[string]bool {
+	names := make(map[string]bool)
+	if cfg.name() != "" {
+		names[cfg.name()] = true
 	}
 
-	if cfg.GroupByExpr != nil {
-		n = planner.NewGroupingNode(n, cfg.GroupByExpr)
+	for _, jc := range cfg.Joins {
+		names[jc.name()] = true
 	}
 
-	n = planner.NewProjectionNode(n, cfg.ProjectionExprs, cfg.TableName)
+	return names
+}
 
-	if cfg.Distinct {
-		n = planner.NewDedupNode(n, cfg.TableName)
+// refersTo returns whether the table or one of the joined tables
+// of the statement is the given common table expression.
+func (cfg selectConfig) refersTo(c *cte) bool {
+	if cfg.TableCTE == c {
+		return true
+	}
+
+	for _, jc := range cfg.Joins {
+		if jc.CTE == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// workingTable returns the working table of the recursive common table expression
+// the statement refers to, if its tree is being built.
+func (cfg selectConfig) workingTable() *planner.WorkingTable {
+	if cfg.TableCTE != nil && cfg.TableCTE.working != nil {
+		return cfg.TableCTE.working
+	}
+
+	for _, jc := range cfg.Joins {
+		if jc.CTE != nil && jc.CTE.working != nil {
+			return jc.CTE.working
+		}
+	}
+
+	return nil
+}
+
+// sortKeys returns the keys of the ORDER BY clause. Keys that are also
+// projected expressions are replaced by the name of the projected field,
+// to avoid evaluating them twice.
+func (cfg selectConfig) sortKeys() []planner.SortKey {
+	keys := make([]planner.SortKey, len(cfg.OrderBy))
+	copy(keys, cfg.OrderBy)
+
+	for i, k := range keys {
+		if _, ok := k.Expr.(expr.Path); ok {
+			continue
+		}
+
+		for _, pf := range cfg.ProjectionExprs {
+			pe, ok := pf.(planner.ProjectedExpr)
+			if ok && expr.Equal(pe.Expr, k.Expr) {
+				keys[i].Expr = expr.Path{document.PathFragment{FieldName: pe.ExprName}}
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
+// groupedProjection returns the projected fields of a grouped statement.
+// Projected expressions that are also grouping expressions are replaced by
+// the name of the field holding the value of the group.
+func (cfg selectConfig) groupedProjection() []planner.ProjectedField {
+	fields := make([]planner.ProjectedField, len(cfg.ProjectionExprs))
+	copy(fields, cfg.ProjectionExprs)
+
+	for i, f := range fields {
+		pe, ok := f.(planner.ProjectedExpr)
+		if !ok {
+			continue
+		}
+
+		for _, e := range cfg.GroupByExprs {
+			if expr.Equal(pe.Expr, e) {
+				pe.Expr = expr.Path{document.PathFragment{FieldName: planner.GroupKeyName(e)}}
+				fields[i] = pe
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// aggregates returns whether the projected fields or the HAVING clause
+// use aggregate functions.
+func (cfg selectConfig) aggregates() bool {
+	exprs := []expr.Expr{cfg.HavingExpr}
+	for _, pf := range cfg.ProjectionExprs {
+		if pe, ok := pf.(planner.ProjectedExpr); ok {
+			exprs = append(exprs, pe.Expr)
+		}
+	}
+
+	var found bool
+	for _, e := range exprs {
+		expr.Walk(e, func(e expr.Expr) bool {
+			if _, ok := e.(planner.AggregatorBuilder); ok {
+				found = true
+			}
+			return !found
+		})
+	}
+
+	return found
+}
+
+// windowFuncs returns the window functions used by the projected fields
+// and by the ORDER BY clause. Window functions can't be used in other clauses.
+func (cfg selectConfig) windowFuncs() ([]*expr.WindowFunc, error) {
+	for _, e := range append([]expr.Expr{cfg.WhereExpr, cfg.HavingExpr}, cfg.GroupByExprs...) {
+		if len(collectWindowFuncs(nil, e)) > 0 {
+			return nil, errors.New("window functions can only be used in the projected fields and the ORDER BY clause")
+		}
+	}
+
+	var funcs []*expr.WindowFunc
+	for _, pf := range cfg.ProjectionExprs {
+		if pe, ok := pf.(planner.ProjectedExpr); ok {
+			funcs = collectWindowFuncs(funcs, pe.Expr)
+		}
+	}
+
+	// the ORDER BY clause of compound statements is applied to the
+	// result of the compound statement.
+	if len(cfg.Compound) == 0 {
+		for _, k := range cfg.OrderBy {
+			funcs = collectWindowFuncs(funcs, k.Expr)
+		}
+	}
+
+	if len(funcs) > 0 && len(cfg.GroupByExprs) > 0 {
+		return nil, errors.New("window functions cannot be used with GROUP BY")
+	}
+
+	return funcs, nil
+}
+
+// collectWindowFuncs appends the window functions found in e to funcs,
+// unless they are already present.
+func collectWindowFuncs(funcs []*expr.WindowFunc, e expr.Expr) []*expr.WindowFunc {
+	expr.Walk(e, func(e expr.Expr) bool {
+		w, ok := e.(*expr.WindowFunc)
+		if !ok {
+			return true
+		}
+
+		for _, f := range funcs {
+			if f.IsEqual(w) {
+				return false
+			}
+		}
+
+		funcs = append(funcs, w)
+		return false
+	})
+
+	return funcs
+}
+
+// ToTree turns the statement into an expression tree.
+func (cfg selectConfig) ToTree() (*planner.Tree, error) {
+	n, err := cfg.toNode()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cc := range cfg.Compound {
+		right, err := cc.Select.toNode()
+		if err != nil {
+			return nil, err
+		}
+
+		switch cc.Operator {
+		case scanner.UNION:
+			// the recursive term of a recursive common table expression.
+			if wt := cc.Select.workingTable(); wt != nil {
+				n = planner.NewRecursiveUnionNode(n, right, wt, cc.All)
+				break
+			}
+
+			n = planner.NewUnionNode(n, right, cc.All)
+		case scanner.INTERSECT:
+			n = planner.NewIntersectNode(n, right)
+		case scanner.EXCEPT:
+			n = planner.NewExceptNode(n, right)
+		}
 	}
 
 	if cfg.OrderBy != nil {
-		n = planner.NewSortNode(n, cfg.OrderBy, cfg.OrderByDirection)
+		n = planner.NewSortNode(n, cfg.sortKeys()...)
 	}
 
 	if cfg.OffsetExpr != nil {
@@ -296,3 +814,88 @@ func (cfg selectConfig) ToTree() (*planner.Tree, error) {
 
 	return &planner.Tree{Root: n}, nil
 }
+
+// toNode turns the statement into an expression tree, without the
+// compound statements, sorting, limit and offset.
+func (cfg selectConfig) toNode() (planner.Node, error) {
+	var n planner.Node
+
+	// projections of joined documents or of common table expressions
+	// are not bound to a single table.
+	tableName := cfg.TableName
+	if len(cfg.Joins) > 0 || cfg.TableCTE != nil {
+		tableName = ""
+	}
+
+	if cfg.TableName != "" || cfg.TableSubquery != nil {
+		switch {
+		case cfg.TableSubquery != nil:
+			n = planner.NewSubqueryInputNode(cfg.TableSubquery)
+		case cfg.TableCTE != nil:
+			var err error
+			n, err = cfg.TableCTE.toNode()
+			if err != nil {
+				return nil, err
+			}
+		default:
+			n = planner.NewTableOrViewInputNode(cfg.TableName, cfg.parseView)
+		}
+
+		// name the documents of the table if they can be referred to using
+		// an alias or if they are joined with other tables.
+		if cfg.TableAlias != "" || cfg.NamedTable || len(cfg.Joins) > 0 {
+			name := cfg.name()
+			names := map[string]bool{name: true}
+
+			n = planner.NewRenameNode(n, name)
+
+			for _, jc := range cfg.Joins {
+				if names[jc.name()] {
+					return nil, fmt.Errorf("table name %q specified more than once", jc.name())
+				}
+				names[jc.name()] = true
+
+				var right planner.Node = planner.NewTableOrViewInputNode(jc.TableName, cfg.parseView)
+				if jc.CTE != nil {
+					var err error
+					right, err = jc.CTE.toNode()
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				n = planner.NewJoinNode(n, planner.NewRenameNode(right, jc.name()), jc.Type, jc.On)
+			}
+		}
+	}
+
+	if cfg.WhereExpr != nil {
+		n = planner.NewSelectionNode(n, cfg.WhereExpr)
+	}
+
+	projection := cfg.ProjectionExprs
+	if len(cfg.GroupByExprs) > 0 {
+		n = planner.NewGroupingNode(n, cfg.GroupByExprs...)
+		projection = cfg.groupedProjection()
+	}
+
+	windows, err := cfg.windowFuncs()
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) > 0 {
+		n = planner.NewWindowNode(n, windows...)
+	}
+
+	n = planner.NewProjectionNode(n, projection, tableName)
+
+	if cfg.HavingExpr != nil {
+		n = planner.NewHavingNode(n, cfg.HavingExpr)
+	}
+
+	if cfg.Distinct {
+		n = planner.NewDedupNode(n, tableName)
+	}
+
+	return n, nil
+}
@@ -10,6 +10,39 @@ import (
 )
 
 func TestParserSelect(t *testing.T) {
+	// subqueries are listed by the tree of the statement, in the order they are parsed.
+	subqueries := []*expr.Subquery{
+		{Stmt: planner.NewTree(
+			planner.NewProjectionNode(
+				planner.NewTableInputNode("bar"),
+				[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "b")), ExprName: "b"}},
+				"bar",
+			))},
+		{Stmt: planner.NewTree(
+			planner.NewProjectionNode(
+				planner.NewTableInputNode("bar"),
+				[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "b")), ExprName: "b"}},
+				"bar",
+			))},
+		{
+			Stmt: planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewRenameNode(planner.NewTableInputNode("bar"), "bar"),
+						expr.Eq(expr.Path(parsePath(t, "bar.a")), expr.Path(parsePath(t, "foo.a"))),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"bar",
+				)),
+			Correlated: true,
+		},
+	}
+
+	withSubqueries := func(t *planner.Tree, subqueries ...*expr.Subquery) *planner.Tree {
+		t.Subqueries = subqueries
+		return t
+	}
+
 	tests := []struct {
 		name     string
 		s        string
@@ -130,6 +163,38 @@ func TestParserSelect(t *testing.T) {
 					"test",
 				)),
 			false},
+		{"WithGroupByMultipleExprs", "SELECT a + 1, COUNT(*) FROM test GROUP BY a + 1, b",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewGroupingNode(
+						planner.NewTableInputNode("test"),
+						expr.Add(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)),
+						expr.Path(parsePath(t, "b")),
+					),
+					[]planner.ProjectedField{
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "`a + 1`")), ExprName: "a + 1"},
+						planner.ProjectedExpr{Expr: &expr.CountFunc{Wildcard: true}, ExprName: "COUNT(*)"},
+					},
+					"test",
+				)),
+			false},
+		{"WithGroupByTrailingComma", "SELECT a FROM test GROUP BY a,", nil, true},
+		{"WithHaving", "SELECT a FROM test GROUP BY a HAVING a > 10",
+			planner.NewTree(
+				planner.NewHavingNode(
+					planner.NewProjectionNode(
+						planner.NewGroupingNode(
+							planner.NewTableInputNode("test"),
+							expr.Path(parsePath(t, "a")),
+						),
+						[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+						"test",
+					),
+					expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(10)),
+				)),
+			false},
+		{"WithHavingBeforeGroupBy", "SELECT a FROM test HAVING a > 10 GROUP BY a", nil, true},
+		{"WithHavingWithoutGroupBy", "SELECT a FROM test HAVING a > 10", nil, true},
 		{"WithOrderBy", "SELECT * FROM test WHERE age = 10 ORDER BY a.b.c",
 			planner.NewTree(
 				planner.NewSortNode(
@@ -141,8 +206,7 @@ func TestParserSelect(t *testing.T) {
 						[]planner.ProjectedField{planner.Wildcard{}},
 						"test",
 					),
-					expr.Path(parsePath(t, "a.b.c")),
-					scanner.ASC,
+					planner.SortKey{Expr: expr.Path(parsePath(t, "a.b.c")), Direction: scanner.ASC},
 				)),
 			false},
 		{"WithOrderBy ASC", "SELECT * FROM test WHERE age = 10 ORDER BY a.b.c ASC",
@@ -156,8 +220,7 @@ func TestParserSelect(t *testing.T) {
 						[]planner.ProjectedField{planner.Wildcard{}},
 						"test",
 					),
-					expr.Path(parsePath(t, "a.b.c")),
-					scanner.ASC,
+					planner.SortKey{Expr: expr.Path(parsePath(t, "a.b.c")), Direction: scanner.ASC},
 				)),
 			false},
 		{"WithOrderBy DESC", "SELECT * FROM test WHERE age = 10 ORDER BY a.b.c DESC",
@@ -171,10 +234,26 @@ func TestParserSelect(t *testing.T) {
 						[]planner.ProjectedField{planner.Wildcard{}},
 						"test",
 					),
-					expr.Path(parsePath(t, "a.b.c")),
-					scanner.DESC,
+					planner.SortKey{Expr: expr.Path(parsePath(t, "a.b.c")), Direction: scanner.DESC},
 				)),
 			false},
+		{"WithOrderBy multiple keys", "SELECT a + 1 AS x FROM test ORDER BY b DESC NULLS LAST, a + 1, c * 2 NULLS first",
+			planner.NewTree(
+				planner.NewSortNode(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("test"),
+						[]planner.ProjectedField{planner.ProjectedExpr{
+							Expr:     expr.Add(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)),
+							ExprName: "x",
+						}},
+						"test",
+					),
+					planner.SortKey{Expr: expr.Path(parsePath(t, "b")), Direction: scanner.DESC, NullsLast: true},
+					planner.SortKey{Expr: expr.Path(parsePath(t, "x")), Direction: scanner.ASC},
+					planner.SortKey{Expr: expr.Mul(expr.Path(parsePath(t, "c")), expr.IntegerValue(2)), Direction: scanner.ASC, NullsFirst: true},
+				)),
+			false},
+		{"WithOrderBy invalid NULLS", "SELECT * FROM test ORDER BY a NULLS", nil, true},
 		{"WithLimit", "SELECT * FROM test WHERE age = 10 LIMIT 20",
 			planner.NewTree(
 				planner.NewLimitNode(
@@ -220,6 +299,182 @@ func TestParserSelect(t *testing.T) {
 					10,
 				)),
 			false},
+		{"WithTableAlias", "SELECT t.a FROM test AS t",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewRenameNode(planner.NewTableInputNode("test"), "t"),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "t.a")), ExprName: "t.a"}},
+					"test",
+				)),
+			false},
+		{"WithJoin", "SELECT * FROM foo JOIN bar ON foo.a = bar.b",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewJoinNode(
+						planner.NewRenameNode(planner.NewTableInputNode("foo"), "foo"),
+						planner.NewRenameNode(planner.NewTableInputNode("bar"), "bar"),
+						planner.InnerJoin,
+						expr.Eq(expr.Path(parsePath(t, "foo.a")), expr.Path(parsePath(t, "bar.b"))),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"WithJoins", "SELECT * FROM foo f INNER JOIN bar AS b ON f.a = b.b LEFT OUTER JOIN baz z ON z.c = b.c WHERE f.a > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewJoinNode(
+							planner.NewJoinNode(
+								planner.NewRenameNode(planner.NewTableInputNode("foo"), "f"),
+								planner.NewRenameNode(planner.NewTableInputNode("bar"), "b"),
+								planner.InnerJoin,
+								expr.Eq(expr.Path(parsePath(t, "f.a")), expr.Path(parsePath(t, "b.b"))),
+							),
+							planner.NewRenameNode(planner.NewTableInputNode("baz"), "z"),
+							planner.LeftJoin,
+							expr.Eq(expr.Path(parsePath(t, "z.c")), expr.Path(parsePath(t, "b.c"))),
+						),
+						expr.Gt(expr.Path(parsePath(t, "f.a")), expr.IntegerValue(1)),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"WithSubquery", "SELECT (SELECT b FROM bar) FROM foo WHERE a IN (SELECT b FROM bar)",
+			withSubqueries(planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewTableInputNode("foo"),
+						expr.In(expr.Path(parsePath(t, "a")), subqueries[1]),
+					),
+					[]planner.ProjectedField{planner.ProjectedExpr{
+						Expr:     subqueries[0],
+						ExprName: "(SELECT b FROM bar)",
+					}},
+					"foo",
+				)), subqueries[:2]...),
+			false},
+		{"WithCorrelatedSubquery", "SELECT * FROM foo WHERE EXISTS (SELECT * FROM bar WHERE bar.a = foo.a)",
+			withSubqueries(planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewRenameNode(planner.NewTableInputNode("foo"), "foo"),
+						expr.Exists{Subquery: subqueries[2]},
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"foo",
+				)), subqueries[2]),
+			false},
+		{"WithSubqueryInFrom", "SELECT * FROM (SELECT a FROM foo) AS f WHERE f.a > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewRenameNode(
+							planner.NewSubqueryInputNode(planner.NewTree(
+								planner.NewProjectionNode(
+									planner.NewTableInputNode("foo"),
+									[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+									"foo",
+								))),
+							"f",
+						),
+						expr.Gt(expr.Path(parsePath(t, "f.a")), expr.IntegerValue(1)),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"WithSubqueryInFromWithoutAlias", "SELECT * FROM (SELECT a FROM foo)", nil, true},
+		{"WithUnclosedSubquery", "SELECT * FROM foo WHERE a IN (SELECT a FROM bar", nil, true},
+		{"WithUnion", "SELECT a FROM foo UNION ALL SELECT b FROM bar INTERSECT SELECT c FROM baz ORDER BY a LIMIT 10",
+			planner.NewTree(
+				planner.NewLimitNode(
+					planner.NewSortNode(
+						planner.NewIntersectNode(
+							planner.NewUnionNode(
+								planner.NewProjectionNode(
+									planner.NewTableInputNode("foo"),
+									[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+									"foo",
+								),
+								planner.NewProjectionNode(
+									planner.NewTableInputNode("bar"),
+									[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "b")), ExprName: "b"}},
+									"bar",
+								),
+								true,
+							),
+							planner.NewProjectionNode(
+								planner.NewTableInputNode("baz"),
+								[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "c")), ExprName: "c"}},
+								"baz",
+							),
+						),
+						planner.SortKey{Expr: expr.Path(parsePath(t, "a")), Direction: scanner.ASC},
+					),
+					10,
+				)),
+			false},
+		{"WithExcept", "SELECT a FROM foo EXCEPT SELECT a FROM bar",
+			planner.NewTree(
+				planner.NewExceptNode(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("foo"),
+						[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+						"foo",
+					),
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("bar"),
+						[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+						"bar",
+					),
+				)),
+			false},
+		{"WithCTE", "WITH bar AS (SELECT a FROM foo) SELECT a FROM bar",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewCTEInputNode("bar", planner.NewTree(
+						planner.NewProjectionNode(
+							planner.NewTableInputNode("foo"),
+							[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+							"foo",
+						),
+					)),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+					"",
+				)),
+			false},
+		{"WithRecursiveCTE", "WITH RECURSIVE bar AS (SELECT a FROM foo UNION SELECT a FROM bar) SELECT a FROM bar",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewCTEInputNode("bar", planner.NewTree(
+						planner.NewRecursiveUnionNode(
+							planner.NewProjectionNode(
+								planner.NewTableInputNode("foo"),
+								[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+								"foo",
+							),
+							planner.NewProjectionNode(
+								planner.NewWorkingTableInputNode(planner.NewWorkingTable("bar")),
+								[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+								"",
+							),
+							planner.NewWorkingTable("bar"),
+							false,
+						),
+					)),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+					"",
+				)),
+			false},
+		{"WithCTEWithoutSelect", "WITH bar AS (SELECT a FROM foo)", nil, true},
+		{"WithRecursiveCTEWithoutUnion", "WITH RECURSIVE bar AS (SELECT a FROM bar) SELECT a FROM bar", nil, true},
+		{"WithLimitBeforeUnion", "SELECT a FROM foo LIMIT 10 UNION SELECT a FROM bar", nil, true},
+		{"WithUnionWithoutSelect", "SELECT a FROM foo UNION a FROM bar", nil, true},
+		{"WithJoinWithoutCondition", "SELECT * FROM foo JOIN bar", nil, true},
+		{"WithLeftJoinWithoutJoin", "SELECT * FROM foo LEFT bar ON a = b", nil, true},
+		{"WithJoinOnSameTable", "SELECT * FROM foo JOIN foo ON a = b", nil, true},
 		{"WithOffsetThenLimit", "SELECT * FROM test WHERE age = 10 OFFSET 20 LIMIT 10", nil, true},
 		{"Invalid use of MIN() aggregator", "SELECT * FROM test LIMIT min(0)", nil, true},
 		{"Invalid use of COUNT() aggregator", "SELECT * FROM test OFFSET x(*)", nil, true},
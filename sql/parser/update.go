@@ -21,22 +21,34 @@ func (p *Parser) parseUpdateStatement() (*planner.Tree, error) {
 		return nil, pErr
 	}
 
-	// Parse clause: SET or UNSET.
-	tok, pos, lit := p.ScanIgnoreWhitespace()
-	switch tok {
-	case scanner.SET:
-		cfg.SetPairs, err = p.parseSetClause()
-	case scanner.UNSET:
-		cfg.UnsetFields, err = p.parseUnsetClause()
-	default:
-		err = newParseError(scanner.Tokstr(tok, lit), []string{"SET", "UNSET"}, pos)
-	}
+	refs, err := p.trackRefs(func() (err error) {
+		// Parse clause: SET or UNSET.
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		switch tok {
+		case scanner.SET:
+			cfg.SetPairs, err = p.parseSetClause()
+		case scanner.UNSET:
+			cfg.UnsetFields, err = p.parseUnsetClause()
+		default:
+			err = newParseError(scanner.Tokstr(tok, lit), []string{"SET", "UNSET"}, pos)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Parse condition: "WHERE EXPR".
+		cfg.WhereExpr, err = p.parseCondition()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse condition: "WHERE EXPR".
-	cfg.WhereExpr, err = p.parseCondition()
+	// documents are named after the table if subqueries refer to them.
+	cfg.NamedTable = refs[cfg.TableName]
+
+	// Parse "RETURNING expr, ...".
+	cfg.Returning, err = p.parseReturning()
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +125,8 @@ func (p *Parser) parseUnsetClause() ([]string, error) {
 
 // UpdateConfig holds UPDATE configuration.
 type updateConfig struct {
-	TableName string
+	TableName  string
+	NamedTable bool
 
 	// SetPairs is used along with the Set clause. It holds
 	// each path with its corresponding value that
@@ -125,6 +138,10 @@ type updateConfig struct {
 	UnsetFields []string
 
 	WhereExpr expr.Expr
+
+	// Returning lists the expressions evaluated
+	// against each updated document, if any.
+	Returning []planner.ProjectedField
 }
 
 type updateSetPair struct {
@@ -136,6 +153,10 @@ type updateSetPair struct {
 func (cfg updateConfig) ToTree() *planner.Tree {
 	t := planner.NewTableInputNode(cfg.TableName)
 
+	if cfg.NamedTable {
+		t = planner.NewRenameNode(t, cfg.TableName)
+	}
+
 	if cfg.WhereExpr != nil {
 		t = planner.NewSelectionNode(t, cfg.WhereExpr)
 	}
@@ -152,5 +173,9 @@ func (cfg updateConfig) ToTree() *planner.Tree {
 
 	t = planner.NewReplacementNode(t, cfg.TableName)
 
+	if cfg.Returning != nil {
+		t = planner.NewReturningNode(t, cfg.Returning, cfg.TableName)
+	}
+
 	return &planner.Tree{Root: t}
 }
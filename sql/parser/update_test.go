@@ -117,6 +117,26 @@ func TestParserUpdate(t *testing.T) {
 					"test",
 				)),
 			false},
+		{"SET/With returning", "UPDATE test SET a = 1 WHERE age = 10 RETURNING a, age",
+			planner.NewTree(
+				planner.NewReturningNode(
+					planner.NewReplacementNode(
+						planner.NewSetNode(
+							planner.NewSelectionNode(
+								planner.NewTableInputNode("test"),
+								expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)),
+							),
+							parsePath(t, "a"), expr.IntegerValue(1),
+						),
+						"test",
+					),
+					[]planner.ProjectedField{
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"},
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "age")), ExprName: "age"},
+					},
+					"test",
+				)),
+			false},
 		{"Trailing comma", "UPDATE test SET a = 1, WHERE age = 10", nil, true},
 		{"No SET", "UPDATE test WHERE age = 10", nil, true},
 		{"No pair", "UPDATE test SET WHERE age = 10", nil, true},
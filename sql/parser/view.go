@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// parseCreateViewStatement parses a create view string and returns a Statement AST object.
+// This function assumes the CREATE VIEW or CREATE MATERIALIZED VIEW tokens have already been consumed.
+func (p *Parser) parseCreateViewStatement(materialized bool) (query.CreateViewStmt, error) {
+	stmt := query.CreateViewStmt{Materialized: materialized}
+	var err error
+
+	// Parse IF NOT EXISTS
+	stmt.IfNotExists, err = p.parseIfNotExists()
+	if err != nil {
+		return stmt, err
+	}
+
+	// Parse view name
+	stmt.ViewName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"view_name"}
+		return stmt, pErr
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.AS {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"AS"}, pos)
+	}
+
+	// the raw text of the statement is stored and parsed
+	// every time the view is used.
+	var capture bytes.Buffer
+	p.captures = append(p.captures, &capture)
+	params := p.orderedParams + p.namedParams
+	p.tables = []string{}
+
+	_, err = p.parseViewStatement()
+
+	p.captures = p.captures[:len(p.captures)-1]
+	if len(p.tables) > 0 {
+		stmt.Dependencies = p.tables
+	}
+	p.tables = nil
+	if err != nil {
+		return stmt, err
+	}
+
+	if p.orderedParams+p.namedParams != params {
+		return stmt, errors.New("views cannot use parameters")
+	}
+
+	stmt.Query = strings.TrimSpace(capture.String())
+	return stmt, nil
+}
+
+// parseViewStatement parses the SELECT statement of a view,
+// optionally preceded by common table expressions.
+func (p *Parser) parseViewStatement() (*planner.Tree, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.SELECT:
+		return p.parseSelectStatement()
+	case scanner.WITH:
+		return p.parseWithStatement()
+	}
+
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT", "WITH"}, pos)
+}
+
+// addTable records the name of a table or a view read by the statement
+// of the view being created.
+func (p *Parser) addTable(name string) {
+	if p.tables == nil {
+		return
+	}
+
+	for _, t := range p.tables {
+		if t == name {
+			return
+		}
+	}
+
+	p.tables = append(p.tables, name)
+}
+
+func init() {
+	planner.DefaultViewParser = NewViewParser(expr.NewFunctions())
+}
+
+// NewViewParser returns a planner.ViewParser which parses
+// the statements of the views using the given functions.
+func NewViewParser(functions expr.Functions) planner.ViewParser {
+	return func(viewName, q string) (*planner.Tree, error) {
+		return parseView(functions, nil, viewName, q)
+	}
+}
+
+// viewParser returns a function that parses the statements of the views referred to
+// by the statement being parsed, using the same functions as p.
+// It returns nil if planner.DefaultViewParser can be used instead.
+func (p *Parser) viewParser() planner.ViewParser {
+	if p.builtinFunctions && len(p.views) == 0 {
+		return nil
+	}
+
+	functions, views := p.functions, p.views
+	return func(viewName, q string) (*planner.Tree, error) {
+		return parseView(functions, views, viewName, q)
+	}
+}
+
+// parseView parses the statement q of a view, using the given functions.
+// views contains the names of the views being expanded, which
+// the statement must not refer to.
+func parseView(functions expr.Functions, views []string, viewName, q string) (*planner.Tree, error) {
+	for _, name := range views {
+		if name == viewName {
+			return nil, fmt.Errorf("view %q refers to itself", viewName)
+		}
+	}
+
+	p := NewParserWithOptions(strings.NewReader(q), &Options{Functions: functions})
+	p.views = append(views[:len(views):len(views)], viewName)
+
+	t, err := p.parseViewStatement()
+	if err != nil {
+		return nil, fmt.Errorf("invalid statement for view %q: %w", viewName, err)
+	}
+	t.Subqueries = p.subqueries
+
+	return t, nil
+}
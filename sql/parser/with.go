@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// parseWithStatement parses a list of common table expressions followed by
+// a select statement and returns a Statement AST object.
+// This function assumes the WITH token has already been consumed.
+func (p *Parser) parseWithStatement() (*planner.Tree, error) {
+	p.ctes = make(map[string]*cte)
+	defer func() { p.ctes = nil }()
+
+	// Parse optional RECURSIVE token
+	recursive := true
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.RECURSIVE {
+		p.Unscan()
+		recursive = false
+	}
+
+	for {
+		err := p.parseCTE(recursive)
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+	}
+
+	return p.parseSelectStatement()
+}
+
+// parseCTE parses a common table expression in the form: name AS (SELECT ...).
+// If recursive is true, the select statement can refer to the common table expression.
+func (p *Parser) parseCTE(recursive bool) error {
+	name, err := p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"table_name"}
+		return pErr
+	}
+
+	if _, ok := p.ctes[name]; ok {
+		return fmt.Errorf("common table expression name %q specified more than once", name)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.AS {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"AS"}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+	}
+
+	// a recursive common table expression is visible
+	// from its own statement.
+	c := cte{name: name}
+	if recursive {
+		p.ctes[name] = &c
+	}
+
+	cfg, err := p.parseSelect()
+	if err != nil {
+		return err
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	if c.recursive {
+		if len(cfg.Compound) != 1 || cfg.Compound[0].Operator != scanner.UNION {
+			return fmt.Errorf("recursive common table expression %q must be of the form: SELECT ... UNION [ALL] SELECT ...", name)
+		}
+
+		if cfg.refersTo(&c) {
+			return fmt.Errorf("recursive reference to %q must only appear in the second SELECT", name)
+		}
+	}
+
+	c.cfg = cfg
+	p.ctes[name] = &c
+	return nil
+}
+
+// lookupCTE returns the common table expression with the given name, if any.
+func (p *Parser) lookupCTE(name string) *cte {
+	c, ok := p.ctes[name]
+	if !ok {
+		return nil
+	}
+
+	// the statement of c is being parsed.
+	if c.cfg == nil {
+		c.recursive = true
+	}
+
+	return c
+}
+
+// cte holds the configuration of a common table expression.
+type cte struct {
+	name string
+	// nil while the statement is being parsed.
+	cfg *selectConfig
+	// true if the statement refers to the common table expression itself.
+	recursive bool
+	// documents of the previous iteration, set while the tree
+	// of a recursive common table expression is being built.
+	working *planner.WorkingTable
+}
+
+// toNode returns an input node reading the documents of the common table expression.
+// The statement is turned into a new tree every time it is referred to.
+func (c *cte) toNode() (planner.Node, error) {
+	if c.working != nil {
+		return planner.NewWorkingTableInputNode(c.working), nil
+	}
+
+	if c.cfg == nil {
+		return nil, fmt.Errorf("recursive reference to %q must appear in the FROM clause of the second SELECT", c.name)
+	}
+
+	if c.recursive {
+		c.working = planner.NewWorkingTable(c.name)
+		defer func() { c.working = nil }()
+	}
+
+	t, err := c.cfg.ToTree()
+	if err != nil {
+		return nil, err
+	}
+
+	return planner.NewCTEInputNode(c.name, t), nil
+}
@@ -1,13 +1,28 @@
 package planner
 
 import (
+	"errors"
+
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/sql/query/expr"
 )
 
 // Bind updates every node that refers to a database ressource.
+// Table input nodes that refer to a view are replaced by the tree of the view.
+// The subqueries of the tree are reset, so that they run again with the new
+// transaction and parameters.
 func Bind(t *Tree, tx *database.Transaction, params []expr.Param) error {
+	for _, sq := range t.Subqueries {
+		sq.Reset()
+	}
+
 	if t.Root != nil {
+		var err error
+		t.Root, err = expandView(t.Root, tx)
+		if err != nil {
+			return err
+		}
+
 		return bindNode(t.Root, tx, params)
 	}
 
@@ -23,14 +38,26 @@ func bindNode(n Node, tx *database.Transaction, params []expr.Param) error {
 	}
 
 	if n.Left() != nil {
-		err = bindNode(n.Left(), tx, params)
+		left, err := expandView(n.Left(), tx)
+		if err != nil {
+			return err
+		}
+		n.SetLeft(left)
+
+		err = bindNode(left, tx, params)
 		if err != nil {
 			return err
 		}
 	}
 
 	if n.Right() != nil {
-		err = bindNode(n.Right(), tx, params)
+		right, err := expandView(n.Right(), tx)
+		if err != nil {
+			return err
+		}
+		n.SetRight(right)
+
+		err = bindNode(right, tx, params)
 		if err != nil {
 			return err
 		}
@@ -38,3 +65,74 @@ func bindNode(n Node, tx *database.Transaction, params []expr.Param) error {
 
 	return nil
 }
+
+// expandView returns an input node reading the documents of the view n refers to,
+// if n is a table input node and there is no table with that name.
+// Otherwise it returns n.
+func expandView(n Node, tx *database.Transaction) (Node, error) {
+	tn, ok := n.(*tableInputNode)
+	if !ok {
+		return n, nil
+	}
+
+	parseView := tn.parseView
+	if parseView == nil {
+		parseView = DefaultViewParser
+	}
+	if parseView == nil {
+		return n, nil
+	}
+
+	cfg, err := getView(tx, tn.tableName)
+	if err != nil || cfg == nil {
+		return n, err
+	}
+
+	t, err := parseView(cfg.ViewName, cfg.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &subqueryInputNode{
+		node: node{
+			op: Input,
+		},
+		tree: t,
+		name: cfg.ViewName,
+		view: true,
+	}, nil
+}
+
+// getView returns the view with the given name, or nil if there is a table
+// or nothing with that name.
+func getView(tx *database.Transaction, name string) (*database.ViewConfig, error) {
+	_, err := tx.GetTable(name)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, database.ErrTableNotFound) {
+		return nil, err
+	}
+
+	cfg, err := tx.GetView(name)
+	if err == database.ErrViewNotFound {
+		return nil, nil
+	}
+
+	return cfg, err
+}
+
+// getTable returns the table with the given name, or nil if it is a view.
+func getTable(tx *database.Transaction, name string) (*database.Table, error) {
+	table, err := tx.GetTable(name)
+	if !errors.Is(err, database.ErrTableNotFound) {
+		return table, err
+	}
+
+	_, verr := tx.GetView(name)
+	if verr == nil {
+		return nil, nil
+	}
+
+	return nil, err
+}
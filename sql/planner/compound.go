@@ -0,0 +1,119 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+type setOperationNode struct {
+	node
+
+	// if true, duplicate documents are kept.
+	all bool
+}
+
+var _ operationNode = (*setOperationNode)(nil)
+
+// NewUnionNode creates a node that returns the documents of the left stream
+// followed by the documents of the right stream.
+// Duplicate documents are removed unless all is true.
+func NewUnionNode(left, right Node, all bool) Node {
+	return newSetOperationNode(Union, left, right, all)
+}
+
+// NewIntersectNode creates a node that returns the distinct documents of the left stream
+// that are also returned by the right stream.
+func NewIntersectNode(left, right Node) Node {
+	return newSetOperationNode(Intersect, left, right, false)
+}
+
+// NewExceptNode creates a node that returns the distinct documents of the left stream
+// that are not returned by the right stream.
+func NewExceptNode(left, right Node) Node {
+	return newSetOperationNode(Except, left, right, false)
+}
+
+func newSetOperationNode(op Operation, left, right Node, all bool) Node {
+	return &setOperationNode{
+		node: node{
+			op:    op,
+			left:  left,
+			right: right,
+		},
+		all: all,
+	}
+}
+
+func (n *setOperationNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	return
+}
+
+func (n *setOperationNode) toStream(st document.Stream) (document.Stream, error) {
+	right, err := nodeToStream(n.right)
+	if err != nil {
+		return st, err
+	}
+
+	if n.op == Union {
+		st = document.NewStream(st).Append(right)
+		if n.all {
+			return st, nil
+		}
+
+		return st.Filter(newDocumentHashSet(nil).Filter), nil
+	}
+
+	// the right stream is read entirely before reading the left one,
+	// to avoid having more than one iterator opened at the same time.
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		rset := newDocumentHashSet(nil)
+		err := right.Iterate(func(d document.Document) error {
+			_, err := rset.Filter(d)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		seen := newDocumentHashSet(nil)
+		return st.Iterate(func(d document.Document) error {
+			ok, err := rset.Contains(d)
+			if err != nil {
+				return err
+			}
+
+			// INTERSECT keeps the documents found in the right stream,
+			// EXCEPT keeps the other ones.
+			if ok != (n.op == Intersect) {
+				return nil
+			}
+
+			ok, err = seen.Filter(d)
+			if err != nil || !ok {
+				return err
+			}
+
+			return fn(d)
+		})
+	})), nil
+}
+
+func (n *setOperationNode) String() string {
+	var op string
+	switch n.op {
+	case Union:
+		op = "∪"
+		if n.all {
+			op = "⊎"
+		}
+	case Intersect:
+		op = "∩"
+	case Except:
+		op = "−"
+	}
+
+	return fmt.Sprintf("%s(%s)", op, nodeToString(n.right))
+}
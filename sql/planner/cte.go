@@ -0,0 +1,215 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A WorkingTable holds the documents produced by the last iteration
+// of a recursive common table expression.
+type WorkingTable struct {
+	name string
+	docs []document.Document
+}
+
+// NewWorkingTable creates an empty working table.
+func NewWorkingTable(name string) *WorkingTable {
+	return &WorkingTable{name: name}
+}
+
+// Iterate goes through the documents of the working table.
+func (wt *WorkingTable) Iterate(fn func(d document.Document) error) error {
+	for _, d := range wt.docs {
+		err := fn(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type workingTableInputNode struct {
+	node
+
+	wt *WorkingTable
+}
+
+var _ inputNode = (*workingTableInputNode)(nil)
+
+// NewWorkingTableInputNode creates an input node that reads the documents
+// of the working table. It is used by the recursive term of a recursive
+// common table expression to refer to itself.
+func NewWorkingTableInputNode(wt *WorkingTable) Node {
+	return &workingTableInputNode{
+		node: node{
+			op: Input,
+		},
+		wt: wt,
+	}
+}
+
+func (n *workingTableInputNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	return nil
+}
+
+func (n *workingTableInputNode) String() string {
+	return fmt.Sprintf("WorkingTable(%s)", n.wt.name)
+}
+
+func (n *workingTableInputNode) buildStream() (document.Stream, error) {
+	return document.NewStream(n.wt), nil
+}
+
+type recursiveUnionNode struct {
+	node
+
+	wt *WorkingTable
+	// if true, duplicate documents are kept.
+	all bool
+}
+
+var _ operationNode = (*recursiveUnionNode)(nil)
+
+// NewRecursiveUnionNode creates a node that returns the documents of the left stream,
+// then evaluates the right stream repeatedly, until it stops returning new documents.
+// Before each evaluation, the working table is filled with the documents
+// returned by the previous one.
+// Duplicate documents are removed unless all is true.
+func NewRecursiveUnionNode(left, right Node, wt *WorkingTable, all bool) Node {
+	return &recursiveUnionNode{
+		node: node{
+			op:    RecursiveUnion,
+			left:  left,
+			right: right,
+		},
+		wt:  wt,
+		all: all,
+	}
+}
+
+func (n *recursiveUnionNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	return
+}
+
+func (n *recursiveUnionNode) toStream(st document.Stream) (document.Stream, error) {
+	right, err := nodeToStream(n.right)
+	if err != nil {
+		return st, err
+	}
+
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		defer func() { n.wt.docs = nil }()
+
+		var seen *documentHashSet
+		if !n.all {
+			seen = newDocumentHashSet(nil)
+		}
+
+		// the fields of the recursive select are named after
+		// the fields of the initial select, by position.
+		columns := n.columns()
+
+		// collect copies the documents of the stream that were not seen before.
+		// the stream is read entirely before returning its documents,
+		// to avoid having more than one iterator opened at the same time.
+		collect := func(st document.Stream, rename bool) ([]document.Document, error) {
+			var docs []document.Document
+			err := st.Iterate(func(d document.Document) error {
+				fb := new(document.FieldBuffer)
+				var err error
+				if rename && columns != nil {
+					fb, err = renameFields(d, columns)
+				} else {
+					err = fb.Copy(d)
+				}
+				if err != nil {
+					return err
+				}
+
+				if seen != nil {
+					ok, err := seen.Filter(fb)
+					if err != nil || !ok {
+						return err
+					}
+				}
+
+				docs = append(docs, fb)
+				return nil
+			})
+			return docs, err
+		}
+
+		docs, err := collect(st, false)
+		for err == nil && len(docs) > 0 {
+			for _, d := range docs {
+				err = fn(d)
+				if err != nil {
+					return err
+				}
+			}
+
+			n.wt.docs = docs
+			docs, err = collect(right, true)
+		}
+
+		return err
+	})), nil
+}
+
+// columns returns the names of the fields projected by the initial select,
+// or nil if they are only known when the documents are read.
+func (n *recursiveUnionNode) columns() []string {
+	for ln := n.left; ln != nil; ln = ln.Left() {
+		pn, ok := ln.(*ProjectionNode)
+		if !ok {
+			continue
+		}
+
+		names := make([]string, len(pn.Expressions))
+		for i, f := range pn.Expressions {
+			if _, ok := f.(Wildcard); ok {
+				return nil
+			}
+			names[i] = f.Name()
+		}
+
+		return names
+	}
+
+	return nil
+}
+
+// renameFields returns a copy of d whose fields are named after columns, in order.
+func renameFields(d document.Document, columns []string) (*document.FieldBuffer, error) {
+	var cp document.FieldBuffer
+	err := cp.Copy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.Len() != len(columns) {
+		return nil, fmt.Errorf("the recursive select returns %d fields, the initial select returns %d", cp.Len(), len(columns))
+	}
+
+	var fb document.FieldBuffer
+	var i int
+	err = cp.Iterate(func(_ string, v document.Value) error {
+		fb.Add(columns[i], v)
+		i++
+		return nil
+	})
+	return &fb, err
+}
+
+func (n *recursiveUnionNode) String() string {
+	op := "∪"
+	if n.all {
+		op = "⊎"
+	}
+
+	return fmt.Sprintf("Recursive%s(%s)", op, nodeToString(n.right))
+}
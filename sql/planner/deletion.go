@@ -17,6 +17,7 @@ type deletionNode struct {
 
 	tableName string
 	table     *database.Table
+	returning bool
 }
 
 var _ operationNode = (*deletionNode)(nil)
@@ -49,6 +50,7 @@ func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 	st = st.Limit(deleteBufferSize)
 
 	keys := make([][]byte, deleteBufferSize)
+	var deleted []document.Document
 
 	for {
 		var i int
@@ -61,6 +63,14 @@ func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 			// copy the key and reuse the buffer
 			keys[i] = append(keys[i][0:0], k.Key()...)
 			i++
+
+			if n.returning {
+				dk, err := copyDocumentWithKey(d, k.Key())
+				if err != nil {
+					return err
+				}
+				deleted = append(deleted, dk)
+			}
 			return nil
 		})
 		if err != nil {
@@ -81,9 +91,17 @@ func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 		}
 	}
 
+	if n.returning {
+		return document.NewStream(document.NewIterator(deleted...)), nil
+	}
+
 	return document.Stream{}, nil
 }
 
+func (n *deletionNode) setReturning() {
+	n.returning = true
+}
+
 func (n *deletionNode) String() string {
 	return fmt.Sprintf("Delete(%s)", n.tableName)
 }
@@ -24,8 +24,12 @@ func NewDedupNode(n Node, tableName string) Node {
 }
 
 func (n *dedupNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
-	table, err := tx.GetTable(n.tableName)
-	if err != nil {
+	if n.tableName == "" {
+		return
+	}
+
+	table, err := getTable(tx, n.tableName)
+	if err != nil || table == nil {
 		return
 	}
 
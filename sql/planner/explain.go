@@ -19,7 +19,8 @@ type ExplainStmt struct {
 // Run analyses the inner statement and displays its execution plan.
 // If the statement is a tree, Bind and Optimize will be called prior to
 // displaying all the operations.
-// Explain currently only works on SELECT, UPDATE and DELETE statements.
+// Explain currently only works on SELECT, UPDATE, DELETE, INSERT ... SELECT
+// and INSERT ... RETURNING statements.
 func (s *ExplainStmt) Run(tx *database.Transaction, params []expr.Param) (query.Result, error) {
 	switch t := s.Statement.(type) {
 	case *Tree:
@@ -36,7 +37,7 @@ func (s *ExplainStmt) Run(tx *database.Transaction, params []expr.Param) (query.
 		return s.createResult(t.String())
 	}
 
-	return query.Result{}, errors.New("EXPLAIN only works on SELECT, UPDATE AND DELETE statements")
+	return query.Result{}, errors.New("EXPLAIN only works on SELECT, UPDATE, DELETE, INSERT ... SELECT AND INSERT ... RETURNING statements")
 }
 
 func (s *ExplainStmt) createResult(text string) (query.Result, error) {
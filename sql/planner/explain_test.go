@@ -25,12 +25,28 @@ func TestExplainStmt(t *testing.T) {
 		{"EXPLAIN SELECT a + 1 FROM test WHERE a > 10 AND b > 20 AND c > 30", false, `"Index(idx_b) -> σ(cond: c > 30) -> σ(cond: a > 10) -> ∏(a + 1)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 30 ORDER BY a DESC LIMIT 10 OFFSET 20", false, `"Table(test) -> σ(cond: c > 30) -> ∏(a + 1) -> Sort(a DESC) -> Offset(20) -> Limit(10)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 30 GROUP BY b ORDER BY a DESC LIMIT 10 OFFSET 20", false, `"Table(test) -> σ(cond: c > 30) -> G(b) -> ∏(a + 1) -> Sort(a DESC) -> Offset(20) -> Limit(10)"`},
+		{"EXPLAIN SELECT a, b, COUNT(*) FROM test GROUP BY a, b", false, `"Table(test) -> G(a, b) -> ∏(a, b, COUNT(*))"`},
+		{"EXPLAIN SELECT COUNT(*) FROM test GROUP BY a HAVING COUNT(*) > 1 AND MAX(b) < 10", false, `"Table(test) -> G(a) -> ∏(COUNT(*)) -> Having(COUNT(*) > 1 AND MAX(b) < 10)"`},
+		{"EXPLAIN SELECT a FROM test UNION ALL SELECT a FROM test WHERE a = 10 EXCEPT SELECT b FROM test", false, `"Table(test) -> ∏(a) -> ⊎(Index(idx_a) -> ∏(a)) -> −(Table(test) -> ∏(b))"`},
+		{"EXPLAIN SELECT a FROM test ORDER BY a DESC, b NULLS LAST", false, `"Table(test) -> ∏(a) -> Sort(a DESC, b ASC NULLS LAST)"`},
+		{"EXPLAIN SELECT * FROM test AS t WHERE t.a > 10", false, `"Table(test) -> ρ(t) -> σ(cond: t.a > 10) -> ∏(*)"`},
+		{"EXPLAIN SELECT * FROM test t1 JOIN test t2 ON t1.a = t2.c", false, `"Table(test) -> ρ(t1) -> ⋈(Table(test) -> ρ(t2), cond: t1.a = t2.c) -> ∏(*)"`},
+		{"EXPLAIN SELECT * FROM test t1 LEFT JOIN test t2 ON t1.c = t2.a WHERE t1.a > 10", false, `"Table(test) -> ρ(t1) -> ⟕(Table(test) -> ρ(t2), cond: t1.c = t2.a, index: idx_a) -> σ(cond: t1.a > 10) -> ∏(*)"`},
+		{"EXPLAIN SELECT * FROM test t1 JOIN test t2 ON t2.b = t1.c", false, `"Table(test) -> ρ(t1) -> ⋈(Table(test) -> ρ(t2), cond: t2.b = t1.c, index: idx_b) -> ∏(*)"`},
+		{"EXPLAIN SELECT * FROM (SELECT a FROM test WHERE a = 10) AS s", false, `"Subquery(Index(idx_a) -> ∏(a)) -> ρ(s) -> ∏(*)"`},
+		{"EXPLAIN WITH RECURSIVE s AS (SELECT a FROM test WHERE a = 10 UNION SELECT t.a FROM s JOIN test t ON t.b = s.a) SELECT a FROM s", false, `"CTE(s: Index(idx_a) -> ∏(a) -> Recursive∪(WorkingTable(s) -> ρ(s) -> ⋈(Table(test) -> ρ(t), cond: t.b = s.a, index: idx_b) -> ∏(t.a))) -> ∏(a)"`},
+		{"EXPLAIN SELECT a, RANK() OVER (PARTITION BY b ORDER BY c DESC) FROM test WHERE a > 10", false, `"Index(idx_a) -> Window(RANK() OVER (PARTITION BY b ORDER BY c DESC)) -> ∏(a, RANK() OVER (PARTITION BY b ORDER BY c DESC))"`},
+		{"EXPLAIN SELECT a FROM test WHERE b IN (SELECT c FROM test)", false, `"Table(test) -> σ(cond: b IN (Table(test) -> ∏(c))) -> ∏(a)"`},
+		{"EXPLAIN SELECT * FROM v WHERE b > 10", false, `"View(v: Index(idx_a) -> ∏(a, b)) -> σ(cond: b > 10) -> ∏(*)"`},
 		{"EXPLAIN UPDATE test SET a = 10", false, `"Table(test) -> Set(a = 10) -> Replace(test)"`},
 		{"EXPLAIN UPDATE test SET a = 10 WHERE c > 10", false, `"Table(test) -> σ(cond: c > 10) -> Set(a = 10) -> Replace(test)"`},
 		{"EXPLAIN UPDATE test SET a = 10 WHERE a > 10", false, `"Index(idx_a) -> Set(a = 10) -> Replace(test)"`},
 		{"EXPLAIN DELETE FROM test", false, `"Table(test) -> Delete(test)"`},
 		{"EXPLAIN DELETE FROM test WHERE c > 10", false, `"Table(test) -> σ(cond: c > 10) -> Delete(test)"`},
 		{"EXPLAIN DELETE FROM test WHERE a > 10", false, `"Index(idx_a) -> Delete(test)"`},
+		{"EXPLAIN INSERT INTO test (a, b) SELECT c, d FROM test WHERE a > 10", false, `"Index(idx_a) -> ∏(c, d) -> Insert(test, a, b)"`},
+		{"EXPLAIN INSERT INTO test (a, b) VALUES (1, 2), (3, 4) RETURNING a", false, `"Values([1, 2], [3, 4]) -> Insert(test) -> ∏(a)"`},
+		{"EXPLAIN DELETE FROM test WHERE a > 10 RETURNING *", false, `"Index(idx_a) -> Delete(test) -> ∏(*)"`},
 	}
 
 	for _, test := range tests {
@@ -44,6 +60,7 @@ func TestExplainStmt(t *testing.T) {
 			err = db.Exec(`
 						CREATE INDEX idx_a ON test (a);
 						CREATE UNIQUE INDEX idx_b ON test (b);
+						CREATE VIEW v AS SELECT a, b FROM test WHERE a = 10;
 					`)
 			require.NoError(t, err)
 
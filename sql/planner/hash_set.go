@@ -7,9 +7,12 @@ import (
 	"github.com/genjidb/genji/document"
 )
 
+// documentHashSet stores copies of the documents it is given,
+// grouped by hash. Documents sharing a hash are compared field by field,
+// so that the set doesn't depend on the uniqueness of the hashes.
 type documentHashSet struct {
 	hash hash.Hash64
-	set  map[uint64]struct{}
+	set  map[uint64][]document.Document
 }
 
 func newDocumentHashSet(hash hash.Hash64) *documentHashSet {
@@ -19,7 +22,7 @@ func newDocumentHashSet(hash hash.Hash64) *documentHashSet {
 
 	return &documentHashSet{
 		hash: hash,
-		set:  map[uint64]struct{}{},
+		set:  map[uint64][]document.Document{},
 	}
 }
 
@@ -39,7 +42,7 @@ func (s documentHashSet) generateKey(d document.Document) (uint64, error) {
 			return 0, err
 		}
 
-		err = enc.Encode(value)
+		err = enc.Encode(normalizeNumber(value))
 		if err != nil {
 			return 0, err
 		}
@@ -48,17 +51,150 @@ func (s documentHashSet) generateKey(d document.Document) (uint64, error) {
 	return s.hash.Sum64(), nil
 }
 
-func (s documentHashSet) Filter(d document.Document) (bool, error) {
+// normalizeNumber converts integers to doubles when the conversion is exact,
+// so that integers and doubles that are equal have the same key.
+func normalizeNumber(v document.Value) document.Value {
+	if v.Type != document.IntegerValue {
+		return v
+	}
+
+	i := v.V.(int64)
+	f := float64(i)
+	// 2^63 cannot be converted back to an int64.
+	if f >= 1<<63 || int64(f) != i {
+		return v
+	}
+
+	return document.NewDoubleValue(f)
+}
+
+// lookup returns the key of d and whether a document equal to d was added to the set.
+func (s documentHashSet) lookup(d document.Document) (uint64, bool, error) {
 	k, err := s.generateKey(d)
 	if err != nil {
+		return 0, false, err
+	}
+
+	for _, other := range s.set[k] {
+		ok, err := documentsEqual(d, other)
+		if err != nil || ok {
+			return k, ok, err
+		}
+	}
+
+	return k, false, nil
+}
+
+func (s documentHashSet) Filter(d document.Document) (bool, error) {
+	k, ok, err := s.lookup(d)
+	if err != nil || ok {
 		return false, err
 	}
 
-	_, ok := s.set[k]
-	if ok {
+	var fb document.FieldBuffer
+	err = fb.Copy(d)
+	if err != nil {
+		return false, err
+	}
+
+	s.set[k] = append(s.set[k], &fb)
+	return true, nil
+}
+
+// Contains returns whether a document equal to d was added to the set.
+func (s documentHashSet) Contains(d document.Document) (bool, error) {
+	_, ok, err := s.lookup(d)
+	return ok, err
+}
+
+// documentsEqual returns whether a and b have the same fields
+// and whether the values of these fields are equal.
+func documentsEqual(a, b document.Document) (bool, error) {
+	af, err := document.Fields(a)
+	if err != nil {
+		return false, err
+	}
+	bf, err := document.Fields(b)
+	if err != nil {
+		return false, err
+	}
+
+	if len(af) != len(bf) {
 		return false, nil
 	}
 
-	s.set[k] = struct{}{}
+	for i := range af {
+		if af[i] != bf[i] {
+			return false, nil
+		}
+
+		av, err := a.GetByField(af[i])
+		if err != nil {
+			return false, err
+		}
+		bv, err := b.GetByField(bf[i])
+		if err != nil {
+			return false, err
+		}
+
+		ok, err := valuesEqual(av, bv)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func valuesEqual(a, b document.Value) (bool, error) {
+	a, b = normalizeNumber(a), normalizeNumber(b)
+	if a.Type != b.Type {
+		return false, nil
+	}
+
+	switch a.Type {
+	case document.DocumentValue:
+		return documentsEqual(a.V.(document.Document), b.V.(document.Document))
+	case document.ArrayValue:
+		return arraysEqual(a.V.(document.Array), b.V.(document.Array))
+	case document.DoubleValue:
+		// NaN values are considered equal to each other, like their keys.
+		x, y := a.V.(float64), b.V.(float64)
+		return x == y || (x != x && y != y), nil
+	}
+
+	return a.IsEqual(b)
+}
+
+func arraysEqual(a, b document.Array) (bool, error) {
+	al, err := document.ArrayLength(a)
+	if err != nil {
+		return false, err
+	}
+	bl, err := document.ArrayLength(b)
+	if err != nil {
+		return false, err
+	}
+
+	if al != bl {
+		return false, nil
+	}
+
+	for i := 0; i < al; i++ {
+		av, err := a.GetByIndex(i)
+		if err != nil {
+			return false, err
+		}
+		bv, err := b.GetByIndex(i)
+		if err != nil {
+			return false, err
+		}
+
+		ok, err := valuesEqual(av, bv)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
 	return true, nil
 }
@@ -0,0 +1,51 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+// constantHash returns the same hash for every input.
+type constantHash struct{}
+
+func (constantHash) Write(p []byte) (int, error) { return len(p), nil }
+func (constantHash) Sum(b []byte) []byte         { return b }
+func (constantHash) Reset()                      {}
+func (constantHash) Size() int                   { return 8 }
+func (constantHash) BlockSize() int              { return 1 }
+func (constantHash) Sum64() uint64               { return 0 }
+
+func TestDocumentHashSet(t *testing.T) {
+	doc := func(v document.Value) document.Document {
+		return document.NewFieldBuffer().Add("a", v)
+	}
+
+	s := newDocumentHashSet(constantHash{})
+
+	ok, err := s.Filter(doc(document.NewIntegerValue(1)))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// documents with the same hash are compared.
+	ok, err = s.Filter(doc(document.NewIntegerValue(2)))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = s.Filter(doc(document.NewDoubleValue(1)))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = s.Contains(doc(document.NewIntegerValue(3)))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = s.Contains(document.NewFieldBuffer().Add("a", document.NewIntegerValue(2)).Add("b", document.NewNullValue()))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = s.Contains(doc(document.NewIntegerValue(2)))
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,81 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+type havingNode struct {
+	node
+
+	cond expr.Expr
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ operationNode = (*havingNode)(nil)
+
+// NewHavingNode creates a node that filters the documents returned by a projection
+// that satisfy the condition. The condition can use the projected fields,
+// as well as aggregate functions. If n is a ProjectionNode, aggregate functions
+// that are not projected are computed by it but are not part of the projected documents.
+func NewHavingNode(n Node, cond expr.Expr) Node {
+	if pn, ok := n.(*ProjectionNode); ok {
+		expr.Walk(cond, func(e expr.Expr) bool {
+			builder, ok := e.(AggregatorBuilder)
+			if !ok {
+				return true
+			}
+
+			pn.addAggregator(e, builder)
+			return false
+		})
+	}
+
+	return &havingNode{
+		node: node{
+			op:   Having,
+			left: n,
+		},
+		cond: cond,
+	}
+}
+
+func (n *havingNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *havingNode) toStream(st document.Stream) (document.Stream, error) {
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	var pd projectedDocument
+
+	return st.Filter(func(d document.Document) (bool, error) {
+		stack.Document = d
+		if dm, ok := d.(*documentMask); ok {
+			pd.projected = d
+			pd.original = dm.d
+			stack.Document = &pd
+		}
+
+		v, err := n.cond.Eval(stack)
+		if err != nil {
+			return false, err
+		}
+
+		return v.IsTruthy()
+	}), nil
+}
+
+func (n *havingNode) String() string {
+	return fmt.Sprintf("Having(%s)", n.cond)
+}
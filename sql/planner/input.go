@@ -17,12 +17,24 @@ type tableInputNode struct {
 	table     *database.Table
 	tx        *database.Transaction
 	params    []expr.Param
+
+	// parses the statement of the view the node refers to, if any.
+	// if nil, DefaultViewParser is used.
+	parseView ViewParser
 }
 
 var _ inputNode = (*tableInputNode)(nil)
 
+// A ViewParser parses the statement of a view and returns its tree.
+type ViewParser func(viewName, q string) (*Tree, error)
+
+// DefaultViewParser is the ViewParser used by table input nodes created without one.
+// It is set by the parser package.
+var DefaultViewParser ViewParser
+
 // NewTableInputNode creates an input node that can be used to read documents
-// from a table.
+// from a table. If there is no table with that name but there is a view, the node
+// is replaced by the tree of the view when the tree is bound.
 func NewTableInputNode(tableName string) Node {
 	return &tableInputNode{
 		node: node{
@@ -32,6 +44,18 @@ func NewTableInputNode(tableName string) Node {
 	}
 }
 
+// NewTableOrViewInputNode creates a table input node which uses parseView
+// to parse the statement of the view it refers to, if any.
+func NewTableOrViewInputNode(name string, parseView ViewParser) Node {
+	return &tableInputNode{
+		node: node{
+			op: Input,
+		},
+		tableName: name,
+		parseView: parseView,
+	}
+}
+
 func (n *tableInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
 	n.tx = tx
 	n.params = params
@@ -47,6 +71,146 @@ func (n *tableInputNode) buildStream() (document.Stream, error) {
 	return document.NewStream(n.table), nil
 }
 
+type subqueryInputNode struct {
+	node
+
+	tree *Tree
+	// name of the common table expression or of the view, if any.
+	name string
+	view bool
+}
+
+var _ inputNode = (*subqueryInputNode)(nil)
+
+// NewSubqueryInputNode creates an input node that reads the documents
+// returned by the given tree.
+func NewSubqueryInputNode(t *Tree) Node {
+	return &subqueryInputNode{
+		node: node{
+			op: Input,
+		},
+		tree: t,
+	}
+}
+
+// NewCTEInputNode creates an input node that reads the documents
+// returned by the tree of the given common table expression.
+func NewCTEInputNode(name string, t *Tree) Node {
+	return &subqueryInputNode{
+		node: node{
+			op: Input,
+		},
+		tree: t,
+		name: name,
+	}
+}
+
+func (n *subqueryInputNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	return n.tree.bindAndOptimize(tx, params)
+}
+
+func (n *subqueryInputNode) String() string {
+	if n.view {
+		return fmt.Sprintf("View(%s: %s)", n.name, n.tree)
+	}
+
+	if n.name != "" {
+		return fmt.Sprintf("CTE(%s: %s)", n.name, n.tree)
+	}
+
+	return fmt.Sprintf("Subquery(%s)", n.tree)
+}
+
+func (n *subqueryInputNode) buildStream() (document.Stream, error) {
+	if n.tree.Root == nil {
+		return document.NewStream(document.NewIterator()), nil
+	}
+
+	res, err := n.tree.execute()
+	return res.Stream, err
+}
+
+type valuesInputNode struct {
+	node
+
+	values     expr.LiteralExprList
+	fieldNames []string
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ inputNode = (*valuesInputNode)(nil)
+
+// NewValuesInputNode creates an input node that evaluates each expression
+// and returns the resulting documents. If fieldNames is not empty, each expression must
+// evaluate to a list of values, which are assigned to the fields of a new document, in order.
+// Otherwise, each expression must evaluate to a document.
+func NewValuesInputNode(values expr.LiteralExprList, fieldNames []string) Node {
+	return &valuesInputNode{
+		node: node{
+			op: Input,
+		},
+		values:     values,
+		fieldNames: fieldNames,
+	}
+}
+
+func (n *valuesInputNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	n.tx = tx
+	n.params = params
+	return nil
+}
+
+func (n *valuesInputNode) buildStream() (document.Stream, error) {
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		stack := expr.EvalStack{
+			Tx:     n.tx,
+			Params: n.params,
+		}
+
+		for _, e := range n.values {
+			v, err := e.Eval(stack)
+			if err != nil {
+				return err
+			}
+
+			if len(n.fieldNames) == 0 {
+				if v.Type != document.DocumentValue {
+					return fmt.Errorf("expected document, got %s", v.Type)
+				}
+
+				err = fn(v.V.(document.Document))
+			} else {
+				if v.Type != document.ArrayValue {
+					return fmt.Errorf("expected array, got %s", v.Type)
+				}
+
+				var fb document.FieldBuffer
+				err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+					fb.Add(n.fieldNames[i], v)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+
+				err = fn(&fb)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})), nil
+}
+
+func (n *valuesInputNode) String() string {
+	s := n.values.String()
+	return fmt.Sprintf("Values(%s)", s[1:len(s)-1])
+}
+
 type indexInputNode struct {
 	node
 
@@ -82,14 +246,16 @@ func NewIndexInputNode(tableName, indexName string, iop IndexIteratorOperator, p
 }
 
 func (n *indexInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
-	if n.table == nil {
+	// the table and the index are bound to the transaction,
+	// they must be fetched again if the node is reused by another one.
+	if n.table == nil || n.tx != tx {
 		n.table, err = tx.GetTable(n.tableName)
 		if err != nil {
 			return
 		}
 	}
 
-	if n.index == nil {
+	if n.index == nil || n.tx != tx {
 		n.index, err = tx.GetIndex(n.indexName)
 		if err != nil {
 			return
@@ -108,8 +274,9 @@ func (n *indexInputNode) Bind(tx *database.Transaction, params []expr.Param) (er
 		return
 	}
 
-	// if the indexed field has no constraint and the filter is an int, cast that int to a double.
-	if n.evaluatedFilter.Type == document.IntegerValue {
+	// if the indexed field has no constraint and the filter is an int or a decimal,
+	// cast it to a double.
+	if n.evaluatedFilter.Type == document.IntegerValue || n.evaluatedFilter.Type == document.DecimalValue {
 		info, err := n.table.Info()
 		if err != nil {
 			return err
@@ -130,6 +297,20 @@ func (n *indexInputNode) Bind(tx *database.Transaction, params []expr.Param) (er
 			}
 		}
 	}
+
+	// if the index only contains timestamps and the filter is a text, try to cast that text to a timestamp.
+	if n.evaluatedFilter.Type == document.TextValue && n.index.Type == document.TimestampValue {
+		if v, err := n.evaluatedFilter.CastAsTimestamp(); err == nil {
+			n.evaluatedFilter = v
+		}
+	}
+
+	// if the index only contains decimals and the filter is a number, cast that number to a decimal.
+	if n.evaluatedFilter.Type.IsNumber() && n.index.Type == document.DecimalValue {
+		if v, err := n.evaluatedFilter.CastAsDecimal(); err == nil {
+			n.evaluatedFilter = v
+		}
+	}
 	return
 }
 
@@ -0,0 +1,177 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+type insertionNode struct {
+	node
+
+	tableName  string
+	fieldNames []string
+	onConflict *query.OnConflict
+	returning  bool
+
+	table  *database.Table
+	tx     *database.Transaction
+	params []expr.Param
+
+	// number of documents inserted by the last run and key of the last one,
+	// reported in the result of the tree.
+	rowsAffected  int64
+	lastInsertKey []byte
+}
+
+var _ operationNode = (*insertionNode)(nil)
+
+// NewInsertionNode creates a node that inserts every document of a stream
+// in the given table. If fieldNames is not empty, the fields of each document
+// are renamed after it, in order. If onConflict is not nil, it determines
+// what to do with documents that conflict with existing ones.
+func NewInsertionNode(n Node, tableName string, fieldNames []string, onConflict *query.OnConflict) Node {
+	return &insertionNode{
+		node: node{
+			op:   Insertion,
+			left: n,
+		},
+		tableName:  tableName,
+		fieldNames: fieldNames,
+		onConflict: onConflict,
+	}
+}
+
+func (n *insertionNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	n.table, err = tx.GetTable(n.tableName)
+	return
+}
+
+// toStream copies every document of the stream before inserting them.
+// The stream may read from the table documents are inserted into,
+// and most engines don't support writing to a store while iterating over it.
+// Each document is validated against the constraints of the table
+// and indexed by table.Insert.
+func (n *insertionNode) toStream(st document.Stream) (document.Stream, error) {
+	var docs []*document.FieldBuffer
+
+	err := st.Iterate(func(d document.Document) error {
+		var fb document.FieldBuffer
+
+		var err error
+		if len(n.fieldNames) > 0 {
+			err = n.renameFields(&fb, d)
+		} else {
+			err = fb.Copy(d)
+		}
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, &fb)
+		return nil
+	})
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	n.rowsAffected = 0
+	n.lastInsertKey = nil
+
+	var inserted []document.Document
+	for _, d := range docs {
+		key, err := n.onConflict.Insert(n.table, d, stack)
+		if err != nil {
+			return document.Stream{}, err
+		}
+
+		// ignored documents are neither counted nor returned.
+		if key == nil {
+			continue
+		}
+
+		n.rowsAffected++
+		n.lastInsertKey = key
+
+		if !n.returning {
+			continue
+		}
+
+		stored, err := n.table.GetDocument(key)
+		if err != nil {
+			return document.Stream{}, err
+		}
+
+		stored, err = copyDocumentWithKey(stored, key)
+		if err != nil {
+			return document.Stream{}, err
+		}
+		inserted = append(inserted, stored)
+	}
+
+	if n.returning {
+		return document.NewStream(document.NewIterator(inserted...)), nil
+	}
+
+	return document.Stream{}, nil
+}
+
+// findInsertionNode returns the insertion node of the tree whose root is n, if any.
+// The insertion node is either the root of the tree or the node below RETURNING.
+func findInsertionNode(n Node) *insertionNode {
+	for ; n != nil; n = n.Left() {
+		if in, ok := n.(*insertionNode); ok {
+			return in
+		}
+		if n.Operation() != Projection {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (n *insertionNode) setReturning() {
+	n.returning = true
+}
+
+// renameFields copies the values of d to fb, using the field names of the node.
+func (n *insertionNode) renameFields(fb *document.FieldBuffer, d document.Document) error {
+	var row document.FieldBuffer
+	var i int
+	err := d.Iterate(func(field string, v document.Value) error {
+		if i < len(n.fieldNames) {
+			row.Add(n.fieldNames[i], v)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if i != len(n.fieldNames) {
+		return fmt.Errorf("%d values for %d fields", i, len(n.fieldNames))
+	}
+
+	return fb.Copy(&row)
+}
+
+func (n *insertionNode) String() string {
+	if len(n.fieldNames) == 0 {
+		return fmt.Sprintf("Insert(%s)", n.tableName)
+	}
+
+	return fmt.Sprintf("Insert(%s, %s)", n.tableName, strings.Join(n.fieldNames, ", "))
+}
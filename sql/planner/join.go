@@ -0,0 +1,515 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A JoinType determines how documents without a match are treated by a join.
+type JoinType int
+
+const (
+	// InnerJoin only keeps pairs of documents that satisfy the join condition.
+	InnerJoin JoinType = iota
+	// LeftJoin keeps every document of the left stream, even if no document
+	// of the right stream satisfies the join condition.
+	LeftJoin
+)
+
+type renameNode struct {
+	node
+
+	name string
+
+	// document of the outer query, set when the tree
+	// is run as a correlated subquery.
+	outer document.Document
+}
+
+var _ operationNode = (*renameNode)(nil)
+
+// NewRenameNode creates a node that names every document of the stream,
+// allowing expressions to select them using qualified paths (i.e. name.field).
+func NewRenameNode(n Node, name string) Node {
+	return &renameNode{
+		node: node{
+			op:   Rename,
+			left: n,
+		},
+		name: name,
+	}
+}
+
+func (n *renameNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	return nil
+}
+
+func (n *renameNode) toStream(st document.Stream) (document.Stream, error) {
+	var jd joinedDocument
+
+	return st.Map(func(d document.Document) (document.Document, error) {
+		jd.names = append(jd.names[:0], n.name)
+		jd.docs = append(jd.docs[:0], d)
+		jd.outer = n.outer
+
+		return &jd, nil
+	}), nil
+}
+
+func (n *renameNode) String() string {
+	return fmt.Sprintf("ρ(%s)", n.name)
+}
+
+type joinNode struct {
+	node
+
+	joinType JoinType
+	cond     expr.Expr
+
+	tx     *database.Transaction
+	params []expr.Param
+
+	// if set, documents of the right stream are read using
+	// this index instead of being loaded in memory.
+	// the index is looked up using the value returned by
+	// the evaluation of outerExpr on every left document.
+	index     *database.Index
+	table     *database.Table
+	tableInfo *database.TableInfo
+	iop       IndexIteratorOperator
+	outerExpr expr.Expr
+	rightName string
+}
+
+var _ operationNode = (*joinNode)(nil)
+
+// NewJoinNode creates a node that combines every document of the left stream
+// with every document of the right stream that satisfies the condition.
+func NewJoinNode(left, right Node, joinType JoinType, cond expr.Expr) Node {
+	return &joinNode{
+		node: node{
+			op:    Join,
+			left:  left,
+			right: right,
+		},
+		joinType: joinType,
+		cond:     cond,
+	}
+}
+
+func (n *joinNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *joinNode) toStream(st document.Stream) (document.Stream, error) {
+	if n.index != nil {
+		return n.indexJoin(st)
+	}
+
+	return n.nestedLoopJoin(st)
+}
+
+// nestedLoopJoin loads the right stream in memory then compares
+// each of its documents with every document of the left stream.
+func (n *joinNode) nestedLoopJoin(st document.Stream) (document.Stream, error) {
+	right, err := nodeToStream(n.right)
+	if err != nil {
+		return st, err
+	}
+
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		var rdocs []*joinedDocument
+		err := right.Iterate(func(d document.Document) error {
+			jd, err := copyJoinedDocument(d)
+			if err != nil {
+				return err
+			}
+
+			rdocs = append(rdocs, jd)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		rightNames := joinAliases(n.right)
+
+		var jd joinedDocument
+		stack := expr.EvalStack{
+			Tx:       n.tx,
+			Params:   n.params,
+			Document: &jd,
+		}
+
+		return st.Iterate(func(d document.Document) error {
+			var matched bool
+
+			for _, rd := range rdocs {
+				jd.reset(d)
+				jd.append(rd.names, rd.docs...)
+
+				ok, err := n.match(stack)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+
+				matched = true
+				err = fn(&jd)
+				if err != nil {
+					return err
+				}
+			}
+
+			if !matched && n.joinType == LeftJoin {
+				jd.reset(d)
+				jd.append(rightNames, make([]document.Document, len(rightNames))...)
+				return fn(&jd)
+			}
+
+			return nil
+		})
+	})), nil
+}
+
+// indexJoin loads the left stream in memory, then uses the index
+// to find the documents of the right table matching each of them.
+// The left stream is read entirely before reading the index, to avoid
+// having more than one iterator opened at the same time.
+func (n *joinNode) indexJoin(st document.Stream) (document.Stream, error) {
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		var ldocs []*joinedDocument
+		err := st.Iterate(func(d document.Document) error {
+			jd, err := copyJoinedDocument(d)
+			if err != nil {
+				return err
+			}
+
+			ldocs = append(ldocs, jd)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var jd joinedDocument
+		stack := expr.EvalStack{
+			Tx:     n.tx,
+			Params: n.params,
+		}
+
+		for _, ld := range ldocs {
+			stack.Document = ld
+			v, err := n.outerExpr.Eval(stack)
+			if err != nil {
+				return err
+			}
+
+			var matched bool
+
+			v, ok := n.convertIndexValue(v)
+			if ok {
+				stack.Document = &jd
+				err = n.iop.IterateIndex(n.index, n.table, v, func(d document.Document) error {
+					jd.reset(ld)
+					jd.append([]string{n.rightName}, d)
+
+					ok, err := n.match(stack)
+					if err != nil || !ok {
+						return err
+					}
+
+					matched = true
+					return fn(&jd)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if !matched && n.joinType == LeftJoin {
+				jd.reset(ld)
+				jd.append([]string{n.rightName}, nil)
+				err = fn(&jd)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})), nil
+}
+
+// convertIndexValue converts v to the type of the values stored in the index.
+// It returns false if v cannot match any value of the index.
+func (n *joinNode) convertIndexValue(v document.Value) (document.Value, bool) {
+	if v.Type == document.NullValue {
+		return v, false
+	}
+
+	if n.index.Type != 0 {
+		v, err := v.CastAs(n.index.Type)
+		return v, err == nil
+	}
+
+	// if the indexed field has no constraint and the value is an int or a decimal,
+	// cast it to a double.
+	if v.Type == document.IntegerValue || v.Type == document.DecimalValue {
+		for _, fc := range n.tableInfo.FieldConstraints {
+			if fc.Path.IsEqual(n.index.Opts.Path) && fc.Type != 0 {
+				return v, true
+			}
+		}
+
+		v, err := v.CastAsDouble()
+		return v, err == nil
+	}
+
+	return v, true
+}
+
+func (n *joinNode) match(stack expr.EvalStack) (bool, error) {
+	v, err := n.cond.Eval(stack)
+	if err != nil {
+		return false, err
+	}
+
+	return v.IsTruthy()
+}
+
+func (n *joinNode) String() string {
+	op := "⋈"
+	if n.joinType == LeftJoin {
+		op = "⟕"
+	}
+
+	if n.index != nil {
+		return fmt.Sprintf("%s(%s, cond: %s, index: %s)", op, nodeToString(n.right), n.cond, n.index.Opts.IndexName)
+	}
+
+	return fmt.Sprintf("%s(%s, cond: %s)", op, nodeToString(n.right), n.cond)
+}
+
+// joinAliases returns the names given to the documents streamed by n.
+func joinAliases(n Node) []string {
+	switch t := n.(type) {
+	case *renameNode:
+		return []string{t.name}
+	case *joinNode:
+		return append(joinAliases(t.left), joinAliases(t.right)...)
+	}
+
+	if n.Left() != nil {
+		return joinAliases(n.Left())
+	}
+
+	return nil
+}
+
+// A joinedDocument is a document made of one or more named documents.
+// Paths qualified with the name of a document refer to the fields of that document,
+// other paths are looked up in every document, in order. A named document can also
+// be selected by using its name as a field, if no document contains that field.
+// Documents can be nil, in which case they are considered as NULL.
+// If the joinedDocument belongs to a subquery, the named documents of the outer
+// query can also be selected by name.
+type joinedDocument struct {
+	names []string
+	docs  []document.Document
+	outer document.Document
+}
+
+var _ document.Document = (*joinedDocument)(nil)
+var _ document.NamedDocumentGetter = (*joinedDocument)(nil)
+
+// reset the content of the document with the content of d.
+func (j *joinedDocument) reset(d document.Document) {
+	j.names = j.names[:0]
+	j.docs = j.docs[:0]
+	j.outer = nil
+
+	if jd, ok := d.(*joinedDocument); ok {
+		j.append(jd.names, jd.docs...)
+		j.outer = jd.outer
+		return
+	}
+
+	j.append([]string{""}, d)
+}
+
+func (j *joinedDocument) append(names []string, docs ...document.Document) {
+	j.names = append(j.names, names...)
+	j.docs = append(j.docs, docs...)
+}
+
+// GetByField returns the value of the first document containing the field.
+// Otherwise, it returns the document named after the field, if any.
+func (j *joinedDocument) GetByField(field string) (document.Value, error) {
+	for _, d := range j.docs {
+		if d == nil {
+			continue
+		}
+
+		v, err := d.GetByField(field)
+		if err == document.ErrFieldNotFound {
+			continue
+		}
+
+		return v, err
+	}
+
+	if v, ok := j.GetNamedDocument(field); ok {
+		return v, nil
+	}
+
+	return document.Value{}, document.ErrFieldNotFound
+}
+
+// GetNamedDocument implements the document.NamedDocumentGetter interface.
+// It looks for the document in this document, then in the documents of the outer queries.
+func (j *joinedDocument) GetNamedDocument(name string) (document.Value, bool) {
+	return getOuterNamedDocument(j, name)
+}
+
+func (j *joinedDocument) getNamedDocument(name string) (document.Value, bool) {
+	for i := range j.names {
+		if j.names[i] != name {
+			continue
+		}
+
+		if j.docs[i] == nil {
+			return document.NewNullValue(), true
+		}
+
+		return document.NewDocumentValue(j.docs[i]), true
+	}
+
+	return document.Value{}, false
+}
+
+// getOuterNamedDocument looks for a document with the given name in d and,
+// if d belongs to a subquery, in the documents of the outer queries.
+// Only named documents are returned, to make sure a subquery only refers
+// to its outer queries explicitly.
+func getOuterNamedDocument(d document.Document, name string) (document.Value, bool) {
+	for d != nil {
+		jd, ok := d.(*joinedDocument)
+		if !ok {
+			break
+		}
+
+		if v, ok := jd.getNamedDocument(name); ok {
+			return v, true
+		}
+
+		d = jd.outer
+	}
+
+	return document.Value{}, false
+}
+
+// Iterate goes through the fields of every document.
+// If more than one document contains the same field, the field is
+// qualified with the name of each document, e.g. a.id and b.id.
+func (j *joinedDocument) Iterate(fn func(field string, value document.Value) error) error {
+	if len(j.docs) == 1 {
+		if j.docs[0] == nil {
+			return nil
+		}
+
+		return j.docs[0].Iterate(fn)
+	}
+
+	counts := make(map[string]int)
+	for _, d := range j.docs {
+		if d == nil {
+			continue
+		}
+
+		err := d.Iterate(func(field string, _ document.Value) error {
+			counts[field]++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, d := range j.docs {
+		if d == nil {
+			continue
+		}
+
+		err := d.Iterate(func(field string, value document.Value) error {
+			if counts[field] > 1 {
+				if j.names[i] == "" {
+					return fmt.Errorf("duplicate field %q", field)
+				}
+
+				field = j.names[i] + "." + field
+			}
+
+			return fn(field, value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Key returns the key of the underlying document if there is
+// only one.
+func (j *joinedDocument) Key() []byte {
+	if len(j.docs) != 1 {
+		return nil
+	}
+
+	if k, ok := j.docs[0].(document.Keyer); ok {
+		return k.Key()
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (j *joinedDocument) MarshalJSON() ([]byte, error) {
+	return document.MarshalJSON(j)
+}
+
+// copyJoinedDocument deep copies d into a new joinedDocument.
+func copyJoinedDocument(d document.Document) (*joinedDocument, error) {
+	var src joinedDocument
+	src.reset(d)
+
+	jd := joinedDocument{
+		names: make([]string, len(src.names)),
+		docs:  make([]document.Document, len(src.docs)),
+		outer: src.outer,
+	}
+	copy(jd.names, src.names)
+
+	for i, d := range src.docs {
+		if d == nil {
+			continue
+		}
+
+		var fb document.FieldBuffer
+		err := fb.Copy(d)
+		if err != nil {
+			return nil, err
+		}
+		jd.docs[i] = &fb
+	}
+
+	return &jd, nil
+}
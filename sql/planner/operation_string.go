@@ -19,11 +19,21 @@ func _() {
 	_ = x[Sort-8]
 	_ = x[Set-9]
 	_ = x[Unset-10]
+	_ = x[Group-11]
+	_ = x[Dedup-12]
+	_ = x[Join-13]
+	_ = x[Having-14]
+	_ = x[Union-15]
+	_ = x[Intersect-16]
+	_ = x[Except-17]
+	_ = x[RecursiveUnion-18]
+	_ = x[Window-19]
+	_ = x[Insertion-20]
 }
 
-const _Operation_name = "InputSelectionProjectionRenameDeletionReplacementLimitSkipSortSetUnset"
+const _Operation_name = "InputSelectionProjectionRenameDeletionReplacementLimitSkipSortSetUnsetGroupDedupJoinHavingUnionIntersectExceptRecursiveUnionWindowInsertion"
 
-var _Operation_index = [...]uint8{0, 5, 14, 24, 30, 38, 49, 54, 58, 62, 65, 70}
+var _Operation_index = [...]uint8{0, 5, 14, 24, 30, 38, 49, 54, 58, 62, 65, 70, 75, 80, 84, 90, 95, 104, 110, 124, 130, 139}
 
 func (i Operation) String() string {
 	if i < 0 || i >= Operation(len(_Operation_index)-1) {
@@ -13,6 +13,7 @@ var optimizerRules = []func(t *Tree) (*Tree, error){
 	RemoveUnnecessarySelectionNodesRule,
 	RemoveUnnecessaryDedupNodeRule,
 	UseIndexBasedOnSelectionNodeRule,
+	UseIndexForJoinRule,
 }
 
 // Optimize takes a tree, applies a list of optimization rules
@@ -22,6 +23,19 @@ var optimizerRules = []func(t *Tree) (*Tree, error){
 func Optimize(t *Tree) (*Tree, error) {
 	var err error
 
+	// the right side of set operations is a statement on its own
+	// and is optimized separately.
+	for n := t.Root; n != nil; n = n.Left() {
+		switch n.Operation() {
+		case Union, Intersect, Except, RecursiveUnion:
+			rt, err := Optimize(&Tree{Root: n.Right()})
+			if err != nil {
+				return nil, err
+			}
+			n.SetRight(rt.Root)
+		}
+	}
+
 	for _, rule := range optimizerRules {
 		t, err = rule(t)
 		if err != nil {
@@ -109,13 +123,24 @@ func splitANDExpr(cond expr.Expr) (exprs []expr.Expr) {
 // Examples:
 //   3 + 4 --> 7
 //   3 + 1 > 10 - a --> 4 > 10 - a
+// It also compiles the literal patterns of regular expression operators.
 func PrecalculateExprRule(t *Tree) (*Tree, error) {
 	n := t.Root
 
 	for n != nil {
-		if n.Operation() == Selection {
+		switch n.Operation() {
+		case Selection:
 			sn := n.(*selectionNode)
 			sn.cond = precalculateExpr(sn.cond)
+			if err := expr.PrecompileRegex(sn.cond); err != nil {
+				return nil, err
+			}
+		case Join:
+			jn := n.(*joinNode)
+			jn.cond = precalculateExpr(jn.cond)
+			if err := expr.PrecompileRegex(jn.cond); err != nil {
+				return nil, err
+			}
 		}
 
 		n = n.Left()
@@ -257,18 +282,11 @@ func RemoveUnnecessaryDedupNodeRule(t *Tree) (*Tree, error) {
 
 	for n != nil {
 		if n.Operation() == Dedup {
-			d, ok := n.(*dedupNode)
-			if !ok {
-				continue
-			}
-
-			pn, ok := d.left.(*ProjectionNode)
-			if !ok {
-				continue
-			}
+			d := n.(*dedupNode)
 
 			// if the projection is unique, we remove the node from the tree
-			if isProjectionUnique(d.indexes, pn) {
+			pn, ok := d.left.(*ProjectionNode)
+			if ok && isProjectionUnique(d.indexes, pn) {
 				if prev != nil {
 					prev.SetLeft(n.Left())
 				} else {
@@ -285,6 +303,10 @@ func RemoveUnnecessaryDedupNodeRule(t *Tree) (*Tree, error) {
 }
 
 func isProjectionUnique(indexes map[string]database.Index, pn *ProjectionNode) bool {
+	if pn.info == nil {
+		return false
+	}
+
 	pk := pn.info.GetPrimaryKey()
 	for _, field := range pn.Expressions {
 		e, ok := field.(ProjectedExpr)
@@ -317,6 +339,7 @@ func isProjectionUnique(indexes map[string]database.Index, pn *ProjectionNode) b
 // - one of its operands is a path expression that is indexed
 // - the other operand is a literal value or a parameter
 // If found, it will replace the input node by an indexInputNode using this index.
+// The rule is not applied to trees containing joins.
 func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 	n := t.Root
 	var prev Node
@@ -324,6 +347,10 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 
 	// first we lookup for the input node
 	for n != nil {
+		if n.Operation() == Join {
+			return t, nil
+		}
+
 		if n.Operation() == Input {
 			inputNode = n
 			break
@@ -336,9 +363,13 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 		return t, nil
 	}
 
-	// then we get the table indexes. here we will assume that at this point
-	// inputNodes can only be instances of tableInputNode.
-	inpn := inputNode.(*tableInputNode)
+	// then we get the table indexes. only table input nodes
+	// can be replaced by an index.
+	inpn, ok := inputNode.(*tableInputNode)
+	if !ok {
+		return t, nil
+	}
+
 	indexes, err := inpn.table.Indexes()
 	if err != nil {
 		return nil, err
@@ -492,3 +523,109 @@ func isLiteralOrParam(e expr.Expr) (ok bool) {
 
 	return false
 }
+
+// UseIndexForJoinRule looks for join nodes whose condition is an equality operator
+// between a path of the right table that is indexed, and a path of one of the
+// documents of the left stream.
+// If found, the join node will read the right table using that index
+// for every document of the left stream, instead of comparing them with every
+// document of the right table.
+// Example:
+//   SELECT * FROM a JOIN b ON a.x = b.y
+// If b.y is indexed, every document of a will be used to look up
+// the documents of b whose y field equals a.x.
+func UseIndexForJoinRule(t *Tree) (*Tree, error) {
+	n := t.Root
+
+	for n != nil {
+		if n.Operation() == Join {
+			err := useIndexForJoin(n.(*joinNode))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		n = n.Left()
+	}
+
+	return t, nil
+}
+
+func useIndexForJoin(jn *joinNode) error {
+	// the right side of the join must be a named table
+	rn, ok := jn.right.(*renameNode)
+	if !ok {
+		return nil
+	}
+	tn, ok := rn.left.(*tableInputNode)
+	if !ok {
+		return nil
+	}
+
+	op, ok := jn.cond.(expr.Operator)
+	if !ok || op.Token() != scanner.EQ {
+		return nil
+	}
+	iop, ok := op.(IndexIteratorOperator)
+	if !ok {
+		return nil
+	}
+
+	lp, leftIsPath := op.LeftHand().(expr.Path)
+	rp, rightIsPath := op.RightHand().(expr.Path)
+	if !leftIsPath || !rightIsPath {
+		return nil
+	}
+
+	// one of the paths must select a field of the right table,
+	// the other one must select a field of one of the documents of the left stream.
+	leftAliases := joinAliases(jn.left)
+	var inner, outer expr.Path
+	switch {
+	case isQualifiedBy(rp, rn.name) && isQualifiedByAny(lp, leftAliases):
+		inner, outer = rp, lp
+	case isQualifiedBy(lp, rn.name) && isQualifiedByAny(rp, leftAliases):
+		inner, outer = lp, rp
+	default:
+		return nil
+	}
+
+	indexes, err := tn.table.Indexes()
+	if err != nil {
+		return err
+	}
+
+	idx, ok := indexes[document.Path(inner[1:]).String()]
+	if !ok {
+		return nil
+	}
+
+	info, err := tn.table.Info()
+	if err != nil {
+		return err
+	}
+
+	jn.index = &idx
+	jn.table = tn.table
+	jn.tableInfo = info
+	jn.iop = iop
+	jn.outerExpr = outer
+	jn.rightName = rn.name
+	return nil
+}
+
+// isQualifiedBy returns true if the first fragment of p
+// is the given name and is followed by at least another fragment.
+func isQualifiedBy(p expr.Path, name string) bool {
+	return len(p) > 1 && p[0].FieldName == name
+}
+
+func isQualifiedByAny(p expr.Path, names []string) bool {
+	for _, name := range names {
+		if isQualifiedBy(p, name) {
+			return true
+		}
+	}
+
+	return false
+}
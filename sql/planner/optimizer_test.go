@@ -166,6 +166,11 @@ func TestPrecalculateExprRule(t *testing.T) {
 				Add("b", document.NewDoubleValue(-39)),
 			)),
 		},
+		{
+			"regex operator: a =~ 'fo+' -> a =~ 'fo+'",
+			expr.Regex(expr.Path{document.PathFragment{FieldName: "a"}}, expr.TextValue("fo+")),
+			expr.Regex(expr.Path{document.PathFragment{FieldName: "a"}}, expr.TextValue("fo+")),
+		},
 	}
 
 	for _, test := range tests {
@@ -175,6 +180,12 @@ func TestPrecalculateExprRule(t *testing.T) {
 			require.Equal(t, planner.NewTree(planner.NewSelectionNode(planner.NewTableInputNode("foo"), test.expected)).String(), res.String())
 		})
 	}
+
+	t.Run("invalid regex pattern", func(t *testing.T) {
+		e := expr.NotRegex(expr.Path{document.PathFragment{FieldName: "a"}}, expr.TextValue("fo("))
+		_, err := planner.PrecalculateExprRule(planner.NewTree(planner.NewSelectionNode(planner.NewTableInputNode("foo"), e)))
+		require.Error(t, err)
+	})
 }
 
 func TestRemoveUnnecessarySelectionNodesRule(t *testing.T) {
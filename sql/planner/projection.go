@@ -19,8 +19,13 @@ type ProjectionNode struct {
 	Expressions []ProjectedField
 	tableName   string
 
-	info *database.TableInfo
-	tx   *database.Transaction
+	// aggregators that are not projected but whose
+	// result is used by the following nodes.
+	aggregators []AggregatorBuilder
+
+	info   *database.TableInfo
+	tx     *database.Transaction
+	params []expr.Param
 }
 
 var _ operationNode = (*ProjectionNode)(nil)
@@ -40,12 +45,13 @@ func NewProjectionNode(n Node, expressions []ProjectedField, tableName string) N
 // Bind database resources to this node.
 func (n *ProjectionNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
 	n.tx = tx
+	n.params = params
 	if n.tableName == "" {
 		return
 	}
 
-	table, err := tx.GetTable(n.tableName)
-	if err != nil {
+	table, err := getTable(tx, n.tableName)
+	if err != nil || table == nil {
 		return err
 	}
 
@@ -76,6 +82,17 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 		}
 	}
 
+	for _, builder := range n.aggregators {
+		aggBuilders = append(aggBuilders, builder)
+	}
+
+	// grouped documents are aggregated if there are aggregate functions
+	// or if they are not projected as a whole, and the values of the
+	// grouping expressions are added to the aggregated documents.
+	if gn, ok := n.left.(*GroupingNode); ok && (len(aggBuilders) > 0 || !n.hasWildcard()) {
+		aggBuilders = append([]document.AggregatorBuilder{groupKeyBuilder{exprs: gn.Exprs}}, aggBuilders...)
+	}
+
 	if len(aggBuilders) > 0 {
 		st = st.Aggregate(aggBuilders...)
 	}
@@ -83,6 +100,8 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 	if st.IsEmpty() {
 		d := documentMask{
 			resultFields: n.Expressions,
+			tx:           n.tx,
+			params:       n.params,
 		}
 		var fb document.FieldBuffer
 		err := fb.ScanDocument(d)
@@ -95,6 +114,8 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 		var dm documentMask
 		st = st.Map(func(d document.Document) (document.Document, error) {
 			dm.info = n.info
+			dm.tx = n.tx
+			dm.params = n.params
 			dm.d = d
 			dm.resultFields = n.Expressions
 
@@ -105,6 +126,36 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 	return st, nil
 }
 
+// hasWildcard returns whether one of the projected fields is a wildcard.
+func (n *ProjectionNode) hasWildcard() bool {
+	for _, e := range n.Expressions {
+		if _, ok := e.(Wildcard); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addAggregator makes sure the aggregate function e is computed by the projection.
+// If e is already projected, the builder is aliased with the name of the projected field.
+func (n *ProjectionNode) addAggregator(e expr.Expr, builder AggregatorBuilder) {
+	for _, f := range n.Expressions {
+		if pe, ok := f.(ProjectedExpr); ok && expr.Equal(pe.Expr, e) {
+			builder.SetAlias(pe.ExprName)
+			return
+		}
+	}
+
+	for _, agg := range n.aggregators {
+		if expr.Equal(agg.(expr.Expr), e) {
+			return
+		}
+	}
+
+	n.aggregators = append(n.aggregators, builder)
+}
+
 func (n *ProjectionNode) String() string {
 	var b strings.Builder
 
@@ -120,6 +171,8 @@ func (n *ProjectionNode) String() string {
 
 type documentMask struct {
 	info         *database.TableInfo
+	tx           *database.Transaction
+	params       []expr.Param
 	d            document.Document
 	resultFields []ProjectedField
 }
@@ -129,13 +182,19 @@ var _ document.Document = documentMask{}
 func (r documentMask) GetByField(field string) (v document.Value, err error) {
 	for _, rf := range r.resultFields {
 		if rf.Name() == field || rf.Name() == "*" {
-			v, err = r.d.GetByField(field)
-			if err != document.ErrFieldNotFound {
-				return
+			// fields that are not computed by the projection
+			// are read from the underlying document.
+			if pe, ok := rf.(ProjectedExpr); !ok || isPathTo(pe.Expr, field) {
+				v, err = r.d.GetByField(field)
+				if err != document.ErrFieldNotFound {
+					return
+				}
 			}
 
 			stack := expr.EvalStack{
+				Tx:       r.tx,
 				Document: r.d,
+				Params:   r.params,
 				Info:     r.info,
 			}
 			var found bool
@@ -157,9 +216,17 @@ func (r documentMask) GetByField(field string) (v document.Value, err error) {
 	return
 }
 
+// isPathTo returns whether e is a path to the given top level field.
+func isPathTo(e expr.Expr, field string) bool {
+	p, ok := e.(expr.Path)
+	return ok && len(p) == 1 && p[0].FieldName == field
+}
+
 func (r documentMask) Iterate(fn func(field string, value document.Value) error) error {
 	stack := expr.EvalStack{
+		Tx:       r.tx,
 		Document: r.d,
+		Params:   r.params,
 		Info:     r.info,
 	}
 
@@ -178,6 +245,26 @@ func (r documentMask) MarshalJSON() ([]byte, error) {
 	return document.MarshalJSON(r)
 }
 
+// A projectedDocument looks up fields in the projected document then,
+// if they are not found, in the document the projection is based on.
+type projectedDocument struct {
+	projected document.Document
+	original  document.Document
+}
+
+func (p *projectedDocument) GetByField(field string) (document.Value, error) {
+	v, err := p.projected.GetByField(field)
+	if err != document.ErrFieldNotFound {
+		return v, err
+	}
+
+	return p.original.GetByField(field)
+}
+
+func (p *projectedDocument) Iterate(fn func(field string, value document.Value) error) error {
+	return p.projected.Iterate(fn)
+}
+
 // A ProjectedField is a field that will be part of the projected document that will be returned at the end of a Select statement.
 type ProjectedField interface {
 	Iterate(stack expr.EvalStack, fn func(field string, value document.Value) error) error
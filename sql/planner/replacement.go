@@ -20,6 +20,7 @@ type replacementNode struct {
 	tableName string
 	table     *database.Table
 	codec     encoding.Codec
+	returning bool
 }
 
 var _ operationNode = (*replacementNode)(nil)
@@ -58,6 +59,7 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 
 	keys := make([][]byte, replaceBufferSize)
 	docs := make([]document.FieldBuffer, replaceBufferSize)
+	var replaced []document.Document
 
 	var err error
 	for {
@@ -87,6 +89,19 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 			if err != nil {
 				return document.Stream{}, err
 			}
+
+			if n.returning {
+				d, err := n.table.GetDocument(keys[j])
+				if err != nil {
+					return document.Stream{}, err
+				}
+
+				d, err = copyDocumentWithKey(d, keys[j])
+				if err != nil {
+					return document.Stream{}, err
+				}
+				replaced = append(replaced, d)
+			}
 		}
 
 		if i < replaceBufferSize {
@@ -96,9 +111,17 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 		rit.curKey = keys[i-1]
 	}
 
+	if err == nil && n.returning {
+		return document.NewStream(document.NewIterator(replaced...)), nil
+	}
+
 	return document.Stream{}, err
 }
 
+func (n *replacementNode) setReturning() {
+	n.returning = true
+}
+
 func (n *replacementNode) String() string {
 	return fmt.Sprintf("Replace(%s)", n.tableName)
 }
@@ -0,0 +1,45 @@
+package planner
+
+import (
+	"github.com/genjidb/genji/document"
+)
+
+// A writerNode is a node that writes the documents of a stream to a table.
+// By default, it returns an empty stream.
+type writerNode interface {
+	operationNode
+
+	// setReturning makes the node return the documents it wrote.
+	setReturning()
+}
+
+var (
+	_ writerNode = (*insertionNode)(nil)
+	_ writerNode = (*replacementNode)(nil)
+	_ writerNode = (*deletionNode)(nil)
+)
+
+// NewReturningNode creates a node that projects the documents written by n,
+// which must be an insertion, replacement or deletion node.
+// Inserted and replaced documents are returned as they are stored in the table,
+// deleted documents as they were before being deleted.
+func NewReturningNode(n Node, expressions []ProjectedField, tableName string) Node {
+	n.(writerNode).setReturning()
+
+	return NewProjectionNode(n, expressions, tableName)
+}
+
+// copyDocumentWithKey returns a copy of d that can be read
+// after the underlying buffers are reused or modified.
+func copyDocumentWithKey(d document.Document, key []byte) (document.Document, error) {
+	var fb document.FieldBuffer
+	err := fb.Copy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encodedDocumentWithKey{
+		Document: &fb,
+		key:      append([]byte(nil), key...),
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"container/heap"
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
@@ -11,20 +12,54 @@ import (
 	"github.com/genjidb/genji/sql/scanner"
 )
 
+// A SortKey is an expression used to sort a stream,
+// along with the direction of the sort.
+type SortKey struct {
+	Expr expr.Expr
+	// Direction is either scanner.ASC or scanner.DESC.
+	Direction scanner.Token
+	// NullsFirst and NullsLast determine where NULL values are placed,
+	// regardless of the direction. If none is set, NULL values
+	// are considered smaller than any other value.
+	NullsFirst bool
+	NullsLast  bool
+}
+
+func (k SortKey) String() string {
+	dir := "ASC"
+	if k.Direction == scanner.DESC {
+		dir = "DESC"
+	}
+
+	switch {
+	case k.NullsFirst:
+		return fmt.Sprintf("%s %s NULLS FIRST", k.Expr, dir)
+	case k.NullsLast:
+		return fmt.Sprintf("%s %s NULLS LAST", k.Expr, dir)
+	}
+
+	return fmt.Sprintf("%s %s", k.Expr, dir)
+}
+
 type sortNode struct {
 	node
 
-	sortField expr.Path
-	direction scanner.Token
+	keys []SortKey
+
+	tx     *database.Transaction
+	params []expr.Param
 }
 
 var _ operationNode = (*sortNode)(nil)
 
-// NewSortNode creates a node that sorts a stream according to a given
-// document path and a sort direction.
-func NewSortNode(n Node, sortField expr.Path, direction scanner.Token) Node {
-	if direction == 0 {
-		direction = scanner.ASC
+// NewSortNode creates a node that sorts a stream according to a list of keys.
+// Documents are sorted using the first key, then the following keys are
+// used to sort documents that are equal for the previous ones.
+func NewSortNode(n Node, keys ...SortKey) Node {
+	for i := range keys {
+		if keys[i].Direction == 0 {
+			keys[i].Direction = scanner.ASC
+		}
 	}
 
 	return &sortNode{
@@ -32,36 +67,43 @@ func NewSortNode(n Node, sortField expr.Path, direction scanner.Token) Node {
 			op:   Sort,
 			left: n,
 		},
-		sortField: sortField,
-		direction: direction,
+		keys: keys,
 	}
 }
 
 func (n *sortNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
 	return
 }
 
 func (n *sortNode) toStream(st document.Stream) (document.Stream, error) {
 	return document.NewStream(&sortIterator{
-		st:        st,
-		sortField: n.sortField,
-		direction: n.direction,
+		st:     st,
+		keys:   n.keys,
+		tx:     n.tx,
+		params: n.params,
 	}), nil
 }
 
 func (n *sortNode) String() string {
-	dir := "ASC"
-	if n.direction == scanner.DESC {
-		dir = "DESC"
+	var b strings.Builder
+
+	for i, k := range n.keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k.String())
 	}
 
-	return fmt.Sprintf("Sort(%s %s)", n.sortField, dir)
+	return fmt.Sprintf("Sort(%s)", b.String())
 }
 
 type sortIterator struct {
-	st        document.Stream
-	sortField expr.Path
-	direction scanner.Token
+	st     document.Stream
+	keys   []SortKey
+	tx     *database.Transaction
+	params []expr.Param
 }
 
 func (it *sortIterator) Iterate(fn func(d document.Document) error) error {
@@ -84,64 +126,49 @@ func (it *sortIterator) Iterate(fn func(d document.Document) error) error {
 // sortStream operates a partial sort on the iterator using a heap.
 // This ensures a O(k+n log n) time complexity, where k is the sum of
 // OFFSET + LIMIT clauses, if provided, otherwise k = n.
-// If the sorting is in ascending order, a min-heap will be used
-// otherwise a max-heap will be used instead.
+// Each document is associated with a composite key, made of the encoded
+// value of every sort key, which are compared one after the other.
 // Once the heap is filled entirely with the content of the table a stream is returned.
-// During iteration, the stream will pop the k-smallest or k-largest elements, depending on
-// the chosen sorting order (ASC or DESC).
+// During iteration, the stream will pop the k-smallest elements, according to
+// the direction of each key.
 // This function is not memory efficient as it's loading the entire stream in memory before
-// returning the k-smallest or k-largest elements.
+// returning the k-smallest elements.
 func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
-	path := document.Path(it.sortField)
-
-	var h heap.Interface
-	if it.direction == scanner.ASC {
-		h = new(minHeap)
-	} else {
-		h = new(maxHeap)
-	}
+	h := &sortHeap{keys: it.keys}
 
 	heap.Init(h)
 
+	stack := expr.EvalStack{
+		Tx:     it.tx,
+		Params: it.params,
+	}
+
 	return h, st.Iterate(func(d document.Document) error {
-		// It is possible to sort by any projected field
-		// or field of the original document.
-		v, err := path.GetValue(d)
-		if err != nil && err != document.ErrFieldNotFound {
-			return err
+		node := heapNode{
+			values: make([][]byte, len(it.keys)),
 		}
 
-		// If a field is not found in the projected fields
-		// Look for fields in the original document.
-		if err == document.ErrFieldNotFound {
-			if dm, ok := d.(*documentMask); ok {
-				v, err = path.GetValue(dm.d)
-				if err != nil && err != document.ErrFieldNotFound {
-					return err
-				}
-				if err == document.ErrFieldNotFound {
-					v = document.NewNullValue()
-				}
-			} else {
-				v = document.NewNullValue()
+		for i, k := range it.keys {
+			v, err := it.evalKey(stack, k.Expr, d)
+			if err != nil {
+				return err
 			}
-		}
 
-		// We need to make sure sort behaviour
-		// if the same with or without indexes.
-		// To achieve that, the value must be encoded using the same method
-		// as what the index package would do.
-		var buf bytes.Buffer
+			// We need to make sure sort behaviour
+			// if the same with or without indexes.
+			// To achieve that, the value must be encoded using the same method
+			// as what the index package would do.
+			var buf bytes.Buffer
 
-		err = document.NewValueEncoder(&buf).Encode(v)
-		if err != nil {
-			return err
-		}
+			err = document.NewValueEncoder(&buf).Encode(v)
+			if err != nil {
+				return err
+			}
 
-		node := heapNode{
-			value: buf.Bytes(),
+			node.values[i] = buf.Bytes()
 		}
-		err = node.data.Copy(d)
+
+		err := node.data.Copy(d)
 		if err != nil {
 			return err
 		}
@@ -152,33 +179,116 @@ func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
 	})
 }
 
+// evalKey evaluates the sort key on the given document.
+func (it *sortIterator) evalKey(stack expr.EvalStack, e expr.Expr, d document.Document) (document.Value, error) {
+	dm, isMask := d.(*documentMask)
+
+	// It is possible to sort by any projected field
+	// or field of the original document.
+	if p, ok := e.(expr.Path); ok {
+		path := document.Path(p)
+
+		v, err := path.GetValue(d)
+		if err != document.ErrFieldNotFound {
+			return v, err
+		}
+
+		// If a field is not found in the projected fields
+		// Look for fields in the original document.
+		if isMask {
+			v, err = path.GetValue(dm.d)
+			if err != document.ErrFieldNotFound {
+				return v, err
+			}
+		}
+
+		return document.NewNullValue(), nil
+	}
+
+	// Other expressions are evaluated on the projected fields and
+	// the fields of the original document.
+	if isMask {
+		stack.Document = &projectedDocument{projected: d, original: dm.d}
+	} else {
+		stack.Document = d
+	}
+
+	return e.Eval(stack)
+}
+
 type heapNode struct {
-	value []byte
-	data  document.FieldBuffer
+	values [][]byte
+	data   document.FieldBuffer
 }
 
-type minHeap []heapNode
+// sortHeap is a min-heap that compares nodes
+// using the direction of each key.
+type sortHeap struct {
+	nodes []heapNode
+	keys  []SortKey
+}
 
-func (h minHeap) Len() int           { return len(h) }
-func (h minHeap) Less(i, j int) bool { return bytes.Compare(h[i].value, h[j].value) < 0 }
-func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h sortHeap) Len() int      { return len(h.nodes) }
+func (h sortHeap) Swap(i, j int) { h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i] }
 
-func (h *minHeap) Push(x interface{}) {
-	*h = append(*h, x.(heapNode))
+func (h sortHeap) Less(i, j int) bool {
+	return compareSortValues(h.keys, h.nodes[i].values, h.nodes[j].values) < 0
 }
 
-func (h *minHeap) Pop() interface{} {
-	old := *h
+func (h *sortHeap) Push(x interface{}) {
+	h.nodes = append(h.nodes, x.(heapNode))
+}
+
+func (h *sortHeap) Pop() interface{} {
+	old := h.nodes
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.nodes = old[0 : n-1]
 	return x
 }
 
-type maxHeap struct {
-	minHeap
+// compareSortValues compares two lists of encoded values, one key after the other,
+// using the direction of each key. It returns a negative number if a must be placed
+// before b, a positive number if a must be placed after b, or 0 if they are equal.
+func compareSortValues(keys []SortKey, a, b [][]byte) int {
+	for k, key := range keys {
+		if key.NullsFirst || key.NullsLast {
+			an, bn := isEncodedNull(a[k]), isEncodedNull(b[k])
+			if an != bn {
+				if an == key.NullsFirst {
+					return -1
+				}
+				return 1
+			}
+		}
+
+		cmp := bytes.Compare(a[k], b[k])
+		if cmp == 0 {
+			continue
+		}
+
+		if key.Direction == scanner.DESC {
+			return -cmp
+		}
+
+		return cmp
+	}
+
+	return 0
+}
+
+// equalSortValues returns true if both lists of encoded values are equal.
+func equalSortValues(a, b [][]byte) bool {
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
-func (h maxHeap) Less(i, j int) bool {
-	return bytes.Compare(h.minHeap[i].value, h.minHeap[j].value) > 0
+// isEncodedNull returns true if the value was encoded from a NULL value.
+func isEncodedNull(v []byte) bool {
+	return len(v) == 1 && v[0] == byte(document.NullValue)
 }
@@ -7,6 +7,7 @@ package planner
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
@@ -24,7 +25,7 @@ const (
 	Selection
 	// Projection (∏) is an operation that selects a list of fields from each document of a stream.
 	Projection
-	// Rename (ρ) is an operation that renames a path from each document of a stream.
+	// Rename (ρ) is an operation that gives a name to each document of a stream.
 	Rename
 	// Deletion is an operation that removes all of the documents of a stream from their respective table.
 	Deletion
@@ -35,7 +36,7 @@ const (
 	Limit
 	// Skip is an operation that ignores a certain number of documents.
 	Skip
-	// Sort is an operation that sorts a stream of document according to a given path and a direction.
+	// Sort is an operation that sorts a stream of document according to a list of expressions and directions.
 	Sort
 	// Set is an operation that adds a value or replaces at a given path for every document of the stream.
 	Set
@@ -45,12 +46,44 @@ const (
 	Group
 	// Dedup is an operation that removes duplicate documents from a stream
 	Dedup
+	// Join (⋈) is an operation that combines the documents of two streams
+	// that satisfy a given condition.
+	Join
+	// Having is an operation that filters the documents of a projection
+	// that satisfy a given condition.
+	Having
+	// Union (∪) is an operation that returns the documents of two streams.
+	Union
+	// Intersect (∩) is an operation that returns the documents found in both streams.
+	Intersect
+	// Except (−) is an operation that returns the documents of a stream
+	// that are not found in another stream.
+	Except
+	// RecursiveUnion is an operation that returns the documents of a stream,
+	// followed by the documents of another stream evaluated repeatedly
+	// until it stops returning new documents.
+	RecursiveUnion
+	// Window is an operation that computes the value of window functions
+	// for each document of a stream.
+	Window
+	// Insertion is an operation that inserts every document of a stream in a table.
+	Insertion
 )
 
 // A Tree describes the flow of a stream of documents.
 // Each node will manipulate the stream using relational algebra operations.
 type Tree struct {
 	Root Node
+	// Subqueries used by the expressions of the tree, at any depth.
+	// They are reset every time the tree is bound.
+	Subqueries []*expr.Subquery
+
+	// transaction the tree is bound to when run as a subquery,
+	// nil if the tree must be bound again.
+	tx        *database.Transaction
+	optimized bool
+	// if set to true, the tree is only optimized the first time it is run.
+	prepared bool
 }
 
 // NewTree creates a new tree with n as root.
@@ -58,9 +91,27 @@ func NewTree(n Node) *Tree {
 	return &Tree{Root: n}
 }
 
+// Prepare marks the tree as reusable: it will be optimized the first time it is run
+// and only bound to the transaction and the parameters on the following runs.
+// Binding the tree resets its subqueries, which are run again on every execution.
+// The optimized tree depends on the schema of the database, a prepared tree must not
+// be run anymore once a table or an index it uses has been modified.
+func (t *Tree) Prepare() {
+	t.prepared = true
+}
+
 // Run implements the query.Statement interface.
 // It binds the tree to the database resources and executes it.
 func (t *Tree) Run(tx *database.Transaction, params []expr.Param) (query.Result, error) {
+	if t.prepared {
+		err := t.bindAndOptimize(tx, params)
+		if err != nil {
+			return query.Result{}, err
+		}
+
+		return t.execute()
+	}
+
 	err := Bind(t, tx, params)
 	if err != nil {
 		return query.Result{}, err
@@ -74,10 +125,83 @@ func (t *Tree) Run(tx *database.Transaction, params []expr.Param) (query.Result,
 	return t.execute()
 }
 
+// Stream implements the expr.SubqueryStatement interface.
+// The tree is optimized the first time it is run and is bound
+// to the transaction and the parameters of the stack the first time
+// it is run after being reset.
+// If the stack contains a document, it is considered as the document of the outer
+// query and the documents named in that query can be referred to by the expressions of the tree.
+func (t *Tree) Stream(stack expr.EvalStack) (document.Stream, error) {
+	if t.tx != stack.Tx {
+		err := t.bindAndOptimize(stack.Tx, stack.Params)
+		if err != nil {
+			return document.Stream{}, err
+		}
+
+		t.tx = stack.Tx
+	}
+
+	if t.Root == nil {
+		return document.Stream{}, nil
+	}
+
+	setOuterDocument(t.Root, stack.Document)
+
+	res, err := t.execute()
+	return res.Stream, err
+}
+
+// Reset implements the expr.SubqueryStatement interface.
+func (t *Tree) Reset() {
+	t.tx = nil
+}
+
+func (t *Tree) bindAndOptimize(tx *database.Transaction, params []expr.Param) error {
+	err := Bind(t, tx, params)
+	if err != nil {
+		return err
+	}
+
+	if t.optimized {
+		return nil
+	}
+
+	ot, err := Optimize(t)
+	if err != nil {
+		return err
+	}
+
+	t.Root = ot.Root
+	t.optimized = true
+	return nil
+}
+
+// setOuterDocument gives access to the outer document to every
+// named document of the tree.
+func setOuterDocument(n Node, d document.Document) {
+	if rn, ok := n.(*renameNode); ok {
+		rn.outer = d
+	}
+
+	if n.Left() != nil {
+		setOuterDocument(n.Left(), d)
+	}
+
+	if n.Right() != nil {
+		setOuterDocument(n.Right(), d)
+	}
+}
+
 func (t *Tree) execute() (query.Result, error) {
 	var st document.Stream
 	var err error
 
+	// the optimizer removes every node of trees
+	// that cannot return any document.
+	if t.Root == nil {
+		return query.Result{}, nil
+	}
+
 	if t.Root.Left() != nil {
 		st, err = nodeToStream(t.Root.Left())
 		if err != nil {
@@ -90,9 +214,18 @@ func (t *Tree) execute() (query.Result, error) {
 		return query.Result{}, err
 	}
 
-	return query.Result{
+	res := query.Result{
 		Stream: st,
-	}, nil
+	}
+
+	// documents are inserted when the stream is created,
+	// report them like INSERT ... VALUES does.
+	if in := findInsertionNode(t.Root); in != nil {
+		res.RowsAffected = in.rowsAffected
+		res.LastInsertKey = in.lastInsertKey
+	}
+
+	return res, nil
 }
 
 func (t *Tree) String() string {
@@ -441,24 +574,25 @@ func (n *unsetNode) String() string {
 }
 
 // A GroupingNode is a node that groups documents by value.
+// If it groups by more than one expression, the group is the array of their values.
 type GroupingNode struct {
 	node
 
 	Tx     *database.Transaction
 	Params []expr.Param
-	Expr   expr.Expr
+	Exprs  []expr.Expr
 }
 
 var _ operationNode = (*GroupingNode)(nil)
 
 // NewGroupingNode creates a GroupingNode.
-func NewGroupingNode(n Node, e expr.Expr) Node {
+func NewGroupingNode(n Node, exprs ...expr.Expr) Node {
 	return &GroupingNode{
 		node: node{
 			op:   Group,
 			left: n,
 		},
-		Expr: e,
+		Exprs: exprs,
 	}
 }
 
@@ -469,18 +603,84 @@ func (n *GroupingNode) Bind(tx *database.Transaction, params []expr.Param) (err
 	return
 }
 
-// toStream uses the GroupBy stream operation. It evaluates Expr for every field and returns
+// toStream uses the GroupBy stream operation. It evaluates Exprs for every document and returns
 // the result.
 func (n *GroupingNode) toStream(st document.Stream) (document.Stream, error) {
 	return st.GroupBy(func(d document.Document) (document.Value, error) {
-		return n.Expr.Eval(expr.EvalStack{
+		stack := expr.EvalStack{
 			Tx:       n.Tx,
 			Params:   n.Params,
 			Document: d,
-		})
+		}
+
+		if len(n.Exprs) == 1 {
+			return n.Exprs[0].Eval(stack)
+		}
+
+		vb := make(document.ValueBuffer, len(n.Exprs))
+		for i, e := range n.Exprs {
+			v, err := e.Eval(stack)
+			if err != nil && err != document.ErrFieldNotFound {
+				return v, err
+			}
+			if err == document.ErrFieldNotFound {
+				v = document.NewNullValue()
+			}
+
+			vb[i] = v
+		}
+
+		return document.NewArrayValue(vb), nil
 	}), nil
 }
 
+// GroupKeyName returns the name of the field holding the value of
+// the grouping expression e in the aggregated documents.
+func GroupKeyName(e expr.Expr) string {
+	return fmt.Sprintf("%v", e)
+}
+
+// groupKeyBuilder builds aggregators that add the values
+// of the grouping expressions to the aggregated documents.
+type groupKeyBuilder struct {
+	exprs []expr.Expr
+}
+
+func (b groupKeyBuilder) NewAggregator(group document.Value) document.Aggregator {
+	return &groupKeyAggregator{exprs: b.exprs, group: group}
+}
+
+type groupKeyAggregator struct {
+	exprs []expr.Expr
+	group document.Value
+}
+
+func (a *groupKeyAggregator) Add(d document.Document) error {
+	return nil
+}
+
+// Aggregate adds one field per grouping expression, named after the expression.
+func (a *groupKeyAggregator) Aggregate(fb *document.FieldBuffer) error {
+	if len(a.exprs) == 1 {
+		fb.Add(GroupKeyName(a.exprs[0]), a.group)
+		return nil
+	}
+
+	return a.group.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		fb.Add(GroupKeyName(a.exprs[i]), v)
+		return nil
+	})
+}
+
 func (n *GroupingNode) String() string {
-	return fmt.Sprintf("G(%s)", n.Expr)
+	var b strings.Builder
+
+	for i, e := range n.Exprs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%v", e)
+	}
+
+	return fmt.Sprintf("G(%s)", b.String())
 }
@@ -0,0 +1,702 @@
+package planner
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// NewViewMaintainer returns a database.ViewMaintainer which runs the statements
+// of the materialized views, parsed using parseView.
+// Views aggregating the documents of a single table, optionally grouped, are maintained
+// incrementally: every time a document of the table is modified, the documents of the view
+// are updated by adding or removing its contribution to COUNT and SUM aggregates.
+// Grouped views using other aggregates, or HAVING, are updated by computing the groups of the
+// old and the new document again, which is cheap if the grouping expressions are indexed.
+// The other views, including views aggregating the whole table with other aggregates,
+// are only updated when they are refreshed.
+func NewViewMaintainer(parseView ViewParser) database.ViewMaintainer {
+	return &viewMaintainer{
+		parseView: parseView,
+		views:     make(map[string]analyzedView),
+	}
+}
+
+type viewMaintainer struct {
+	parseView ViewParser
+
+	// analysis of the statements of the views, by view name.
+	views map[string]analyzedView
+	mu    sync.Mutex
+}
+
+type analyzedView struct {
+	query string
+	// nil if the view doesn't aggregate the documents of a single table.
+	g *groupedView
+}
+
+// analyze returns the analysis of the statement of the view.
+// Statements are parsed and analyzed once, then the analysis is reused
+// as long as the statement of the view doesn't change.
+// The returned groupedView must not be run.
+func (m *viewMaintainer) analyze(cfg *database.ViewConfig) (*groupedView, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.views[cfg.ViewName]; ok && a.query == cfg.Query {
+		return a.g, nil
+	}
+
+	t, err := m.parseView(cfg.ViewName, cfg.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGroupedView(t)
+	m.views[cfg.ViewName] = analyzedView{query: cfg.Query, g: g}
+	return g, nil
+}
+
+// SourceTable implements the database.ViewMaintainer interface.
+func (m *viewMaintainer) SourceTable(tx *database.Transaction, cfg *database.ViewConfig) (string, error) {
+	g, err := m.analyze(cfg)
+	if err != nil || g == nil || !g.maintained() {
+		return "", err
+	}
+
+	// the documents of regular views are not stored,
+	// they cannot be modified directly.
+	_, err = tx.GetTable(g.input.tableName)
+	if errors.Is(err, database.ErrTableNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return g.input.tableName, nil
+}
+
+// Refresh implements the database.ViewMaintainer interface.
+func (m *viewMaintainer) Refresh(tx *database.Transaction, cfg *database.ViewConfig, v *database.MaterializedView) error {
+	g, err := m.analyze(cfg)
+	if err != nil {
+		return err
+	}
+
+	t, err := m.parseView(cfg.ViewName, cfg.Query)
+	if err != nil {
+		return err
+	}
+
+	err = v.DeleteAll()
+	if err != nil {
+		return err
+	}
+
+	return insertResult(tx, t, g, v)
+}
+
+// Update implements the database.ViewMaintainer interface.
+func (m *viewMaintainer) Update(tx *database.Transaction, cfg *database.ViewConfig, v *database.MaterializedView, old, d document.Document) error {
+	g, err := m.analyze(cfg)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return m.Refresh(tx, cfg, v)
+	}
+
+	// groups whose document must be computed again.
+	var groups []document.Value
+	var keys [][]byte
+
+	for i, doc := range []document.Document{old, d} {
+		if doc == nil {
+			continue
+		}
+
+		ok, err := g.matches(tx, doc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		group, err := g.group(tx, doc)
+		if err != nil {
+			return err
+		}
+
+		key, err := groupKey(group)
+		if err != nil {
+			return err
+		}
+
+		if g.additive {
+			sign := int64(1)
+			if i == 0 {
+				sign = -1
+			}
+
+			ok, err := g.apply(v, key, group, doc, sign)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+		}
+
+		if len(keys) == 0 || !bytes.Equal(keys[0], key) {
+			groups = append(groups, group)
+			keys = append(keys, key)
+		}
+	}
+
+	for i, group := range groups {
+		err = m.computeGroup(tx, cfg, g, v, keys[i], group)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// computeGroup replaces the document of the view belonging to the given group
+// by running the statement of the view on the documents of the group only.
+func (m *viewMaintainer) computeGroup(tx *database.Transaction, cfg *database.ViewConfig, g *groupedView, v *database.MaterializedView, key []byte, group document.Value) error {
+	// views aggregating the whole table have only one group.
+	if g.grouping == nil {
+		return m.Refresh(tx, cfg, v)
+	}
+
+	err := v.Delete(key)
+	if err != nil && err != database.ErrDocumentNotFound {
+		return err
+	}
+
+	// the tree is modified when it is run, it must be parsed again.
+	t, err := m.parseView(cfg.ViewName, cfg.Query)
+	if err != nil {
+		return err
+	}
+
+	tg := newGroupedView(t)
+	if tg == nil {
+		return errors.New("unexpected statement")
+	}
+
+	// only select the documents of the group.
+	var cond expr.Expr
+	for i, e := range tg.grouping.Exprs {
+		gv, err := g.groupValue(group, i)
+		if err != nil {
+			return err
+		}
+
+		var c expr.Expr
+		if gv.Type == document.NullValue {
+			c = expr.Is(e, expr.LiteralValue(gv))
+		} else {
+			c = expr.Eq(e, expr.LiteralValue(gv))
+		}
+
+		if cond == nil {
+			cond = c
+		} else {
+			cond = expr.And(cond, c)
+		}
+	}
+	tg.grouping.SetLeft(NewSelectionNode(tg.grouping.Left(), cond))
+
+	return insertResult(tx, t, g, v)
+}
+
+// insertResult runs the tree and inserts the documents it returns in the view.
+// If the view is grouped, the documents are stored under the key of their group.
+func insertResult(tx *database.Transaction, t *Tree, g *groupedView, v *database.MaterializedView) error {
+	res, err := t.Run(tx, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	return res.Iterate(func(d document.Document) error {
+		if g == nil {
+			return v.Insert(d)
+		}
+
+		group, err := g.viewGroup(d)
+		if err != nil {
+			return err
+		}
+
+		key, err := groupKey(group)
+		if err != nil {
+			return err
+		}
+
+		return v.Put(key, d)
+	})
+}
+
+// groupKey returns the key of the document of the view holding the given group.
+// Groups are encoded the same way as when documents are aggregated.
+func groupKey(group document.Value) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := document.NewValueEncoder(&buf).Encode(group)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// groupedView is the statement of a view which aggregates
+// the documents of a single table.
+type groupedView struct {
+	input *tableInputNode
+	// nil if the whole table is aggregated.
+	grouping *GroupingNode
+	// conditions of the WHERE clause.
+	conds []expr.Expr
+	// fields of the documents of the view.
+	columns []viewColumn
+	// whether the documents of the view can be updated by adding or removing
+	// the contribution of each document.
+	additive bool
+	// index of the COUNT(*) column, or -1.
+	countAll int
+}
+
+// maintained reports whether the view can be updated every time a document
+// of the table is modified. Views aggregating the whole table cannot be
+// recomputed one group at a time: unless the contribution of each document
+// can be added or removed, they would be computed again for every document
+// written by a statement.
+func (g *groupedView) maintained() bool {
+	return g.grouping != nil || (g.additive && g.countAll >= 0)
+}
+
+// viewColumn is a field of the documents of a grouped view.
+type viewColumn struct {
+	name string
+	// index of the grouping expression whose value is projected, or -1.
+	group int
+	// aggregate function computing the field, if any.
+	agg AggregatorBuilder
+	// index of the COUNT column of the argument of a SUM column, or -1.
+	count int
+}
+
+// newGroupedView returns a groupedView if the tree is made of a table input node,
+// optionally followed by selection nodes, then optionally by a grouping node,
+// then by a projection node and optionally a having node. The grouping expressions must
+// all be projected, the documents must be aggregated and the expressions of the statement
+// must not use subqueries.
+// Otherwise it returns nil.
+func newGroupedView(t *Tree) *groupedView {
+	n := t.Root
+	var exprs []expr.Expr
+
+	hn, having := n.(*havingNode)
+	if having {
+		exprs = append(exprs, hn.cond)
+		n = hn.Left()
+	}
+
+	pn, ok := n.(*ProjectionNode)
+	if !ok || pn.hasWildcard() {
+		return nil
+	}
+
+	g := groupedView{countAll: -1}
+	n = pn.Left()
+	if gn, ok := n.(*GroupingNode); ok {
+		g.grouping = gn
+		exprs = append(exprs, gn.Exprs...)
+		n = gn.Left()
+	}
+
+	for {
+		sn, ok := n.(*selectionNode)
+		if !ok {
+			break
+		}
+
+		g.conds = append(g.conds, sn.cond)
+		n = sn.Left()
+	}
+	exprs = append(exprs, g.conds...)
+
+	g.input, ok = n.(*tableInputNode)
+	if !ok {
+		return nil
+	}
+
+	var aggregated bool
+	g.additive = !having
+	for _, pf := range pn.Expressions {
+		pe, ok := pf.(ProjectedExpr)
+		if !ok {
+			return nil
+		}
+		exprs = append(exprs, pe.Expr)
+
+		c := viewColumn{name: pe.Name(), group: -1, count: -1}
+		if p, ok := pe.Expr.(expr.Path); ok && g.grouping != nil && len(p) == 1 {
+			for i, e := range g.grouping.Exprs {
+				if p[0].FieldName == GroupKeyName(e) {
+					c.group = i
+					break
+				}
+			}
+		}
+
+		if c.group < 0 {
+			c.agg, _ = pe.Expr.(AggregatorBuilder)
+			aggregated = aggregated || c.agg != nil
+
+			switch f := pe.Expr.(type) {
+			case *expr.CountFunc:
+				if f.Wildcard && !f.Distinct && g.countAll < 0 {
+					g.countAll = len(g.columns)
+				}
+				g.additive = g.additive && !f.Distinct
+			case *expr.SumFunc:
+				g.additive = g.additive && !f.Distinct
+			default:
+				g.additive = false
+			}
+		}
+
+		g.columns = append(g.columns, c)
+	}
+
+	if g.grouping == nil && !aggregated {
+		return nil
+	}
+
+	for i := range g.grouping.exprs() {
+		if g.column(i) < 0 {
+			return nil
+		}
+	}
+
+	// SUM columns need the number of summed values to know when they become NULL.
+	for i, c := range g.columns {
+		sum, ok := c.agg.(*expr.SumFunc)
+		if !ok {
+			continue
+		}
+
+		for j, cc := range g.columns {
+			count, ok := cc.agg.(*expr.CountFunc)
+			if ok && !count.Wildcard && !count.Distinct && expr.Equal(count.Expr, sum.Expr) {
+				g.columns[i].count = j
+				break
+			}
+		}
+	}
+
+	for _, e := range exprs {
+		var subquery bool
+		expr.Walk(e, func(e expr.Expr) bool {
+			if _, ok := e.(*expr.Subquery); ok {
+				subquery = true
+			}
+			return !subquery
+		})
+
+		if subquery {
+			return nil
+		}
+	}
+
+	return &g
+}
+
+// exprs returns the grouping expressions, if any.
+func (n *GroupingNode) exprs() []expr.Expr {
+	if n == nil {
+		return nil
+	}
+
+	return n.Exprs
+}
+
+// column returns the index of the column holding the value
+// of the i-th grouping expression, or -1.
+func (g *groupedView) column(i int) int {
+	for j, c := range g.columns {
+		if c.group == i {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// matches returns whether d satisfies the WHERE clause of the statement.
+func (g *groupedView) matches(tx *database.Transaction, d document.Document) (bool, error) {
+	for _, cond := range g.conds {
+		v, err := cond.Eval(expr.EvalStack{Tx: tx, Document: d})
+		if err != nil {
+			return false, err
+		}
+
+		ok, err := v.IsTruthy()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// group returns the group of d, computed the same way as the grouping node.
+// If the whole table is aggregated, the group is NULL.
+func (g *groupedView) group(tx *database.Transaction, d document.Document) (document.Value, error) {
+	exprs := g.grouping.exprs()
+	vb := make(document.ValueBuffer, len(exprs))
+	for i, e := range exprs {
+		v, err := e.Eval(expr.EvalStack{Tx: tx, Document: d})
+		if err == document.ErrFieldNotFound {
+			v = document.NewNullValue()
+		} else if err != nil {
+			return v, err
+		}
+
+		vb[i] = v
+	}
+
+	return g.makeGroup(vb), nil
+}
+
+// viewGroup returns the group of a document of the view.
+func (g *groupedView) viewGroup(d document.Document) (document.Value, error) {
+	exprs := g.grouping.exprs()
+	vb := make(document.ValueBuffer, len(exprs))
+	for i := range exprs {
+		v, err := d.GetByField(g.columns[g.column(i)].name)
+		if err == document.ErrFieldNotFound {
+			v = document.NewNullValue()
+		} else if err != nil {
+			return v, err
+		}
+
+		vb[i] = v
+	}
+
+	return g.makeGroup(vb), nil
+}
+
+// makeGroup returns the group made of the values of the grouping expressions.
+func (g *groupedView) makeGroup(vb document.ValueBuffer) document.Value {
+	switch len(vb) {
+	case 0:
+		return document.NewNullValue()
+	case 1:
+		return vb[0]
+	}
+
+	return document.NewArrayValue(vb)
+}
+
+// groupValue returns the value of the i-th grouping expression in the group.
+func (g *groupedView) groupValue(group document.Value, i int) (document.Value, error) {
+	if len(g.grouping.exprs()) == 1 {
+		return group, nil
+	}
+
+	return group.V.(document.Array).GetByIndex(i)
+}
+
+// apply adds the contribution of d to the document of its group, or removes it
+// if sign is negative. It returns false if the contribution cannot be applied exactly
+// and the document of the group must be computed again.
+func (g *groupedView) apply(v *database.MaterializedView, key []byte, group document.Value, d document.Document, sign int64) (bool, error) {
+	cur, err := v.Get(key)
+	if err == database.ErrDocumentNotFound {
+		// the group is not in the view yet.
+		if sign < 0 {
+			return false, nil
+		}
+		cur = nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// removing a document requires knowing whether the group becomes empty.
+	if sign < 0 && g.countAll < 0 {
+		return false, nil
+	}
+
+	values := make([]document.Value, len(g.columns))
+	for i, c := range g.columns {
+		if c.group >= 0 {
+			values[i], err = g.groupValue(group, c.group)
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		delta, err := contribution(c.agg, group, d)
+		if err != nil {
+			return false, err
+		}
+
+		if cur == nil {
+			values[i] = delta
+			continue
+		}
+
+		old, err := cur.GetByField(c.name)
+		if err != nil {
+			return false, err
+		}
+
+		var ok bool
+		values[i], ok = addValues(old, delta, sign)
+		if !ok {
+			return false, nil
+		}
+	}
+
+	if sign < 0 {
+		if isZero(values[g.countAll]) {
+			return true, v.Delete(key)
+		}
+
+		// a sum of no values is NULL. Without the count of the values,
+		// only a sum which is not zero is known to have values left.
+		for i, c := range g.columns {
+			if _, ok := c.agg.(*expr.SumFunc); !ok || values[i].Type == document.NullValue {
+				continue
+			}
+			if c.count >= 0 {
+				if isZero(values[c.count]) {
+					values[i] = document.NewNullValue()
+				}
+				continue
+			}
+			if isZero(values[i]) {
+				return false, nil
+			}
+		}
+	}
+
+	fb := document.NewFieldBuffer()
+	for i, c := range g.columns {
+		fb.Add(c.name, values[i])
+	}
+
+	return true, v.Put(key, fb)
+}
+
+// contribution returns the result of the aggregate function for d alone.
+func contribution(agg AggregatorBuilder, group document.Value, d document.Document) (document.Value, error) {
+	a := agg.NewAggregator(group)
+	err := a.Add(d)
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	var fb document.FieldBuffer
+	err = a.Aggregate(&fb)
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	var v document.Value
+	err = fb.Iterate(func(_ string, value document.Value) error {
+		v = value
+		return nil
+	})
+	return v, err
+}
+
+// maxExactDouble is the largest integer up to which all integers are
+// exactly represented by a double.
+const maxExactDouble = 1 << 53
+
+// addValues returns a + b, or a - b if sign is negative, for the values of COUNT and SUM
+// aggregates. It returns false if the result would not be exactly the one obtained by
+// aggregating the documents again: doubles are not associative and removing decimals
+// can change the scale of the result.
+// Integers stored without a type constraint are converted to doubles, so doubles
+// holding integers are added exactly, as long as they are represented exactly.
+func addValues(a, b document.Value, sign int64) (document.Value, bool) {
+	switch {
+	case b.Type == document.NullValue:
+		return a, true
+	case a.Type == document.NullValue:
+		return b, sign > 0
+	case a.Type == document.IntegerValue && b.Type == document.IntegerValue:
+		// sums of integers wrap around the same way when aggregated.
+		return document.NewIntegerValue(a.V.(int64) + sign*b.V.(int64)), true
+	case a.Type == document.DoubleValue || b.Type == document.DoubleValue:
+		x, ok := exactInteger(a)
+		if !ok {
+			return a, false
+		}
+		y, ok := exactInteger(b)
+		if !ok {
+			return a, false
+		}
+
+		r := x + sign*y
+		if r > maxExactDouble || r < -maxExactDouble {
+			return a, false
+		}
+		return document.NewDoubleValue(float64(r)), true
+	case sign < 0:
+		return a, false
+	}
+
+	x, err := a.CastAsDecimal()
+	if err != nil {
+		return a, false
+	}
+	y, err := b.CastAsDecimal()
+	if err != nil {
+		return a, false
+	}
+
+	return document.NewDecimalValue(x.V.(document.Decimal).Add(y.V.(document.Decimal))), true
+}
+
+// exactInteger returns the value of an integer, or of a double holding an integer
+// that is represented exactly.
+func exactInteger(v document.Value) (int64, bool) {
+	switch v.Type {
+	case document.IntegerValue:
+		x := v.V.(int64)
+		return x, x <= maxExactDouble && x >= -maxExactDouble
+	case document.DoubleValue:
+		f := v.V.(float64)
+		if f != math.Trunc(f) || f > maxExactDouble || f < -maxExactDouble {
+			return 0, false
+		}
+		return int64(f), true
+	}
+
+	return 0, false
+}
+
+// isZero returns whether the value of a COUNT aggregate is zero.
+func isZero(v document.Value) bool {
+	zero, _ := v.IsZeroValue()
+	return zero
+}
@@ -0,0 +1,332 @@
+package planner
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+type windowNode struct {
+	node
+
+	funcs []*expr.WindowFunc
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ operationNode = (*windowNode)(nil)
+
+// NewWindowNode creates a node that computes the value of every window function
+// for each document of the stream. The values are stored in the documents
+// and can be read by evaluating the window functions.
+func NewWindowNode(n Node, funcs ...*expr.WindowFunc) Node {
+	return &windowNode{
+		node: node{
+			op:   Window,
+			left: n,
+		},
+		funcs: funcs,
+	}
+}
+
+func (n *windowNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+// toStream loads the entire stream in memory, then computes the window functions
+// one after the other. Documents are returned in the order of the stream.
+func (n *windowNode) toStream(st document.Stream) (document.Stream, error) {
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		var rows []*windowRow
+		err := st.Iterate(func(d document.Document) error {
+			jd, err := copyJoinedDocument(d)
+			if err != nil {
+				return err
+			}
+
+			rows = append(rows, &windowRow{doc: jd})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, f := range n.funcs {
+			err = n.computeFunc(f, rows)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, r := range rows {
+			err = fn(r)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})), nil
+}
+
+// computeFunc sorts the documents by partition, then according to the ORDER BY clause
+// of the window, and computes the value of f for each partition.
+func (n *windowNode) computeFunc(f *expr.WindowFunc, rows []*windowRow) error {
+	keys := make([]SortKey, 0, len(f.Window.PartitionBy)+len(f.Window.OrderBy))
+	for _, e := range f.Window.PartitionBy {
+		keys = append(keys, SortKey{Expr: e, Direction: scanner.ASC})
+	}
+	for _, t := range f.Window.OrderBy {
+		k := SortKey{Expr: t.Expr, Direction: scanner.ASC, NullsFirst: t.NullsFirst, NullsLast: t.NullsLast}
+		if t.Desc {
+			k.Direction = scanner.DESC
+		}
+		keys = append(keys, k)
+	}
+
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	for _, r := range rows {
+		stack.Document = r
+		r.keys = r.keys[:0]
+
+		for _, k := range keys {
+			v, err := k.Expr.Eval(stack)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			err = document.NewValueEncoder(&buf).Encode(v)
+			if err != nil {
+				return err
+			}
+
+			r.keys = append(r.keys, buf.Bytes())
+		}
+	}
+
+	sorted := make([]*windowRow, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareSortValues(keys, sorted[i].keys, sorted[j].keys) < 0
+	})
+
+	np := len(f.Window.PartitionBy)
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && equalSortValues(sorted[start].keys[:np], sorted[end].keys[:np]) {
+			end++
+		}
+
+		err := n.computePartition(f, sorted[start:end], np)
+		if err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// computePartition computes the value of f for each document of a partition.
+// The first np keys of each document are the values of the PARTITION BY clause,
+// the other ones are the values of the ORDER BY clause.
+func (n *windowNode) computePartition(f *expr.WindowFunc, rows []*windowRow, np int) error {
+	name := f.String()
+
+	// documents with the same values for the ORDER BY clause are peers.
+	isPeer := func(i, j int) bool {
+		return equalSortValues(rows[i].keys[np:], rows[j].keys[np:])
+	}
+
+	switch t := f.Func.(type) {
+	case expr.RowNumberFunc:
+		for i, r := range rows {
+			r.values.Add(name, document.NewIntegerValue(int64(i+1)))
+		}
+	case expr.RankFunc, expr.DenseRankFunc:
+		var rank, denseRank int64
+		for i, r := range rows {
+			if i == 0 || !isPeer(i-1, i) {
+				rank = int64(i + 1)
+				denseRank++
+			}
+
+			if _, ok := t.(expr.RankFunc); ok {
+				r.values.Add(name, document.NewIntegerValue(rank))
+			} else {
+				r.values.Add(name, document.NewIntegerValue(denseRank))
+			}
+		}
+	case *expr.LagFunc:
+		return n.computeOffset(name, rows, t.Expr, t.Offset, t.Default, -1)
+	case *expr.LeadFunc:
+		return n.computeOffset(name, rows, t.Expr, t.Offset, t.Default, 1)
+	case document.AggregatorBuilder:
+		// without ORDER BY, the aggregate is computed over the entire partition.
+		// otherwise, it is computed from the start of the partition
+		// to the last peer of the document.
+		agg := t.NewAggregator(document.NewNullValue())
+		for i := 0; i < len(rows); {
+			j := len(rows)
+			if len(f.Window.OrderBy) > 0 {
+				j = i + 1
+				for j < len(rows) && isPeer(i, j) {
+					j++
+				}
+			}
+
+			for _, r := range rows[i:j] {
+				err := agg.Add(r)
+				if err != nil {
+					return err
+				}
+			}
+
+			v, err := aggregatedValue(agg)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range rows[i:j] {
+				r.values.Add(name, v)
+			}
+
+			i = j
+		}
+	default:
+		return fmt.Errorf("%v cannot be used as a window function", f.Func)
+	}
+
+	return nil
+}
+
+// computeOffset evaluates e on the document found at the given offset from each document
+// of the partition, multiplied by dir. If there is no such document, def is evaluated instead.
+func (n *windowNode) computeOffset(name string, rows []*windowRow, e, offset, def expr.Expr, dir int) error {
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	off := 1
+	if offset != nil {
+		v, err := offset.Eval(stack)
+		if err != nil {
+			return err
+		}
+
+		if !v.Type.IsNumber() {
+			return fmt.Errorf("offset of window function must evaluate to a number, got %q", v.Type)
+		}
+
+		v, err = v.CastAsInteger()
+		if err != nil {
+			return err
+		}
+		off = int(v.V.(int64))
+	}
+
+	for i, r := range rows {
+		v := document.NewNullValue()
+
+		var err error
+		switch k := i + dir*off; {
+		case k >= 0 && k < len(rows):
+			stack.Document = rows[k]
+			v, err = e.Eval(stack)
+		case def != nil:
+			stack.Document = r
+			v, err = def.Eval(stack)
+		}
+		if err != nil {
+			return err
+		}
+
+		r.values.Add(name, v)
+	}
+
+	return nil
+}
+
+// aggregatedValue returns the value computed by the aggregator.
+func aggregatedValue(agg document.Aggregator) (document.Value, error) {
+	var fb document.FieldBuffer
+	err := agg.Aggregate(&fb)
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	v := document.NewNullValue()
+	err = fb.Iterate(func(field string, value document.Value) error {
+		v = value
+		return nil
+	})
+	return v, err
+}
+
+func (n *windowNode) String() string {
+	var b strings.Builder
+
+	for i, f := range n.funcs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(f.String())
+	}
+
+	return fmt.Sprintf("Window(%s)", b.String())
+}
+
+// A windowRow is a document of the stream along with the values
+// of the window functions computed for it.
+type windowRow struct {
+	doc    document.Document
+	values document.FieldBuffer
+
+	// encoded values of the partition and order keys
+	// of the window function being computed.
+	keys [][]byte
+}
+
+var _ document.Document = (*windowRow)(nil)
+
+// GetByField returns the value of the window function named after the field, if any.
+// Otherwise, it returns the value of the field of the document.
+func (r *windowRow) GetByField(field string) (document.Value, error) {
+	v, err := r.values.GetByField(field)
+	if err != document.ErrFieldNotFound {
+		return v, err
+	}
+
+	return r.doc.GetByField(field)
+}
+
+// Iterate goes through the fields of the document, without the values
+// of the window functions.
+func (r *windowRow) Iterate(fn func(field string, value document.Value) error) error {
+	return r.doc.Iterate(fn)
+}
+
+// Key returns the key of the document, if any.
+func (r *windowRow) Key() []byte {
+	if k, ok := r.doc.(document.Keyer); ok {
+		return k.Key()
+	}
+
+	return nil
+}
@@ -86,3 +86,46 @@ func (stmt CreateIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Re
 
 	return res, err
 }
+
+// CreateViewStmt is a DSL that allows creating a full CREATE VIEW statement.
+type CreateViewStmt struct {
+	ViewName    string
+	IfNotExists bool
+	// SELECT statement of the view.
+	Query string
+	// if true, the documents returned by the statement are stored.
+	Materialized bool
+	// names of the tables and views read by the statement.
+	Dependencies []string
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt CreateViewStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the Create view statement in the given transaction.
+// It implements the Statement interface.
+func (stmt CreateViewStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.ViewName == "" {
+		return res, errors.New("missing view name")
+	}
+
+	if stmt.Query == "" {
+		return res, errors.New("missing query")
+	}
+
+	err := tx.CreateView(database.ViewConfig{
+		ViewName:     stmt.ViewName,
+		Query:        stmt.Query,
+		Materialized: stmt.Materialized,
+		Dependencies: stmt.Dependencies,
+	})
+	if stmt.IfNotExists && err == database.ErrViewAlreadyExists {
+		err = nil
+	}
+
+	return res, err
+}
@@ -1,6 +1,7 @@
 package query_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/genjidb/genji"
@@ -235,3 +236,78 @@ func TestCreateIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateView(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		fails bool
+	}{
+		{"Basic", "CREATE VIEW v AS SELECT a FROM test", false},
+		{"If not exists", "CREATE VIEW IF NOT EXISTS v AS SELECT a FROM test", false},
+		{"With", "CREATE VIEW v AS WITH s AS (SELECT a FROM test) SELECT a FROM s", false},
+		{"Table with same name", "CREATE VIEW test AS SELECT 1", true},
+		{"Internal prefix", "CREATE VIEW __genji_v AS SELECT 1", true},
+		{"Params", "CREATE VIEW v AS SELECT a FROM test WHERE a > ?", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec("CREATE TABLE test")
+			require.NoError(t, err)
+
+			err = db.Exec(test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test;
+			INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+			CREATE VIEW v AS SELECT a, b FROM test WHERE a > 1;
+			CREATE VIEW w AS SELECT b FROM v WHERE a < 3;
+		`)
+		require.NoError(t, err)
+
+		// the view already exists
+		err = db.Exec("CREATE VIEW v AS SELECT 1")
+		require.True(t, errors.Is(err, database.ErrViewAlreadyExists))
+		err = db.Exec("CREATE VIEW IF NOT EXISTS v AS SELECT 1")
+		require.NoError(t, err)
+
+		// a table cannot have the name of a view
+		err = db.Exec("CREATE TABLE v")
+		require.True(t, errors.Is(err, database.ErrViewAlreadyExists))
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM v")
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(2), v)
+
+		d, err = db.QueryDocument("SELECT b FROM w")
+		require.NoError(t, err)
+		v, err = d.GetByField("b")
+		require.NoError(t, err)
+		require.Equal(t, document.NewTextValue("bar"), v)
+
+		// views are read-only
+		err = db.Exec("DELETE FROM v")
+		require.Error(t, err)
+		err = db.Exec("INSERT INTO v (a) VALUES (1)")
+		require.Error(t, err)
+	})
+}
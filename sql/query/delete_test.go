@@ -59,4 +59,30 @@ func TestDeleteStmt(t *testing.T) {
 			}
 		})
 	}
+	t.Run("with returning", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`CREATE TABLE test(a INTEGER PRIMARY KEY)`)
+		require.NoError(t, err)
+		err = db.Exec(`INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz')`)
+		require.NoError(t, err)
+
+		res, err := db.Query(`DELETE FROM test WHERE a >= 2 RETURNING b, pk()`)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"b": "bar", "pk()": 2}, {"b": "baz", "pk()": 3}]`, buf.String())
+
+		res, err = db.Query(`SELECT COUNT(*) FROM test`)
+		require.NoError(t, err)
+		buf.Reset()
+		err = document.IteratorToJSON(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"COUNT(*)": 1}`, buf.String())
+	})
 }
@@ -2,6 +2,7 @@ package query
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/sql/query/expr"
@@ -62,3 +63,42 @@ func (stmt DropIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Resu
 
 	return res, err
 }
+
+// DropViewStmt is a DSL that allows creating a DROP VIEW or a DROP MATERIALIZED VIEW query.
+type DropViewStmt struct {
+	ViewName     string
+	IfExists     bool
+	Materialized bool
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt DropViewStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the DropView statement in the given transaction.
+// It implements the Statement interface.
+func (stmt DropViewStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.ViewName == "" {
+		return res, errors.New("missing view name")
+	}
+
+	cfg, err := tx.GetView(stmt.ViewName)
+	if err == database.ErrViewNotFound && stmt.IfExists {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+
+	if cfg.Materialized && !stmt.Materialized {
+		return res, fmt.Errorf("%q is a materialized view", stmt.ViewName)
+	}
+	if !cfg.Materialized && stmt.Materialized {
+		return res, fmt.Errorf("%q is not a materialized view", stmt.ViewName)
+	}
+
+	return res, tx.DropView(stmt.ViewName)
+}
@@ -1,6 +1,7 @@
 package query_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/genjidb/genji"
@@ -76,3 +77,71 @@ func TestDropIndex(t *testing.T) {
 	require.Equal(t, "idx_test1_foo", indexes[0].IndexName)
 	require.Equal(t, false, indexes[0].Unique)
 }
+
+func TestDropView(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test; CREATE VIEW v1 AS SELECT * FROM test; CREATE VIEW v2 AS SELECT * FROM test")
+	require.NoError(t, err)
+
+	err = db.Exec("DROP VIEW v1")
+	require.NoError(t, err)
+
+	err = db.Exec("DROP VIEW IF EXISTS v1")
+	require.NoError(t, err)
+
+	// Dropping a view that doesn't exist without "IF EXISTS"
+	// should return an error.
+	err = db.Exec("DROP VIEW v1")
+	require.Error(t, err)
+
+	// Dropping a table with DROP VIEW should fail.
+	err = db.Exec("DROP VIEW test")
+	require.Error(t, err)
+
+	// Assert that only the view `v1` has been dropped.
+	var views []*database.ViewConfig
+	err = db.View(func(tx *genji.Tx) error {
+		var err error
+		views, err = tx.ListViews()
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	require.Equal(t, "v2", views[0].ViewName)
+
+	err = db.Exec("SELECT * FROM v1")
+	require.Error(t, err)
+
+	// views and tables cannot be dropped or renamed while other views read them.
+	err = db.Exec("CREATE VIEW v3 AS SELECT * FROM v2 WHERE a IN (SELECT a FROM other)")
+	require.NoError(t, err)
+	err = db.Exec("DROP VIEW v2")
+	require.EqualError(t, err, `view "v3" depends on "v2"`)
+	err = db.Exec("DROP TABLE test")
+	require.EqualError(t, err, `view "v2" depends on "test"`)
+	err = db.Exec("ALTER TABLE test RENAME TO foo")
+	require.EqualError(t, err, `view "v2" depends on "test"`)
+
+	// views cannot be modified like tables.
+	for _, q := range []string{
+		"INSERT INTO v3 (a) VALUES (1)",
+		"INSERT INTO v3 SELECT * FROM test",
+		"UPDATE v3 SET a = 1",
+		"DELETE FROM v3",
+		"DROP TABLE v3",
+		"ALTER TABLE v3 RENAME TO foo",
+		"ALTER TABLE v3 ADD FIELD b",
+		"CREATE INDEX idx_v3_a ON v3 (a)",
+	} {
+		err = db.Exec(q)
+		var verr *database.ViewNotWritableError
+		require.True(t, errors.As(err, &verr), "%s: %v", q, err)
+		require.Equal(t, "v3", verr.ViewName)
+	}
+
+	err = db.Exec("DROP VIEW v3; DROP VIEW v2; DROP TABLE test")
+	require.NoError(t, err)
+}
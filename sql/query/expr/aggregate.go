@@ -0,0 +1,422 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+)
+
+// ArrayAggFunc is the ARRAY_AGG aggregator function.
+type ArrayAggFunc struct {
+	Expr     Expr
+	Alias    string
+	Distinct bool
+}
+
+// Eval extracts the aggregated array from the given document and returns it.
+func (a *ArrayAggFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, errors.New("misuse of aggregation function ARRAY_AGG()")
+	}
+	return ctx.Document.GetByField(a.String())
+}
+
+// SetAlias implements the planner.AggregatorBuilder interface.
+func (a *ArrayAggFunc) SetAlias(alias string) {
+	a.Alias = alias
+}
+
+// NewAggregator implements the planner.AggregatorBuilder interface.
+func (a *ArrayAggFunc) NewAggregator(group document.Value) document.Aggregator {
+	return newDistinctAggregator(a.Distinct, a.Expr, &ArrayAggAggregator{
+		Fn: a,
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (a *ArrayAggFunc) SetDistinct() {
+	a.Distinct = true
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (a *ArrayAggFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*ArrayAggFunc)
+	if !ok || a.Distinct != o.Distinct {
+		return false
+	}
+
+	return Equal(a.Expr, o.Expr)
+}
+
+// String returns the alias if non-zero, otherwise it returns a string representation
+// of the aggregate expression.
+func (a *ArrayAggFunc) String() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+
+	return fmt.Sprintf("ARRAY_AGG(%s%v)", distinctString(a.Distinct), a.Expr)
+}
+
+// ArrayAggAggregator is an aggregator that collects all the values, including NULL, into an array.
+type ArrayAggAggregator struct {
+	Fn     *ArrayAggFunc
+	Values document.ValueBuffer
+}
+
+// Add appends a copy of the value of the expression to the array.
+func (a *ArrayAggAggregator) Add(d document.Document) error {
+	v, err := a.Fn.Expr.Eval(EvalStack{
+		Document: d,
+	})
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+
+	v, err = copyValue(v)
+	if err != nil {
+		return err
+	}
+
+	a.Values = a.Values.Append(v)
+	return nil
+}
+
+// Aggregate adds a field to the given buffer with the array of values,
+// or NULL if the group was empty.
+func (a *ArrayAggAggregator) Aggregate(fb *document.FieldBuffer) error {
+	if a.Values == nil {
+		fb.Add(a.Fn.String(), document.NewNullValue())
+	} else {
+		fb.Add(a.Fn.String(), document.NewArrayValue(a.Values))
+	}
+
+	return nil
+}
+
+// copyValue returns a copy of v that doesn't share memory with the document it was read from.
+func copyValue(v document.Value) (document.Value, error) {
+	switch v.Type {
+	case document.DocumentValue:
+		var fb document.FieldBuffer
+		err := fb.Copy(v.V.(document.Document))
+		if err != nil {
+			return v, err
+		}
+		return document.NewDocumentValue(&fb), nil
+	case document.ArrayValue:
+		var vb document.ValueBuffer
+		err := vb.Copy(v.V.(document.Array))
+		if err != nil {
+			return v, err
+		}
+		return document.NewArrayValue(vb), nil
+	case document.BlobValue:
+		return document.NewBlobValue(append([]byte(nil), v.V.([]byte)...)), nil
+	}
+
+	return v, nil
+}
+
+// StringAggFunc is the STRING_AGG aggregator function.
+// It takes the values to concatenate and a separator, e.g. STRING_AGG(name, ', ').
+type StringAggFunc struct {
+	Expr      Expr
+	Separator Expr
+	Alias     string
+	Distinct  bool
+}
+
+// Eval extracts the concatenated text from the given document and returns it.
+func (s *StringAggFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, errors.New("misuse of aggregation function STRING_AGG()")
+	}
+	return ctx.Document.GetByField(s.String())
+}
+
+// SetAlias implements the planner.AggregatorBuilder interface.
+func (s *StringAggFunc) SetAlias(alias string) {
+	s.Alias = alias
+}
+
+// NewAggregator implements the planner.AggregatorBuilder interface.
+func (s *StringAggFunc) NewAggregator(group document.Value) document.Aggregator {
+	return newDistinctAggregator(s.Distinct, s.Expr, &StringAggAggregator{
+		Fn: s,
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (s *StringAggFunc) SetDistinct() {
+	s.Distinct = true
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s *StringAggFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*StringAggFunc)
+	if !ok || s.Distinct != o.Distinct {
+		return false
+	}
+
+	return Equal(s.Expr, o.Expr) && Equal(s.Separator, o.Separator)
+}
+
+// String returns the alias if non-zero, otherwise it returns a string representation
+// of the aggregate expression.
+func (s *StringAggFunc) String() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+
+	return fmt.Sprintf("STRING_AGG(%s%v, %v)", distinctString(s.Distinct), s.Expr, s.Separator)
+}
+
+// StringAggAggregator is an aggregator that concatenates non-null values.
+type StringAggAggregator struct {
+	Fn      *StringAggFunc
+	Builder strings.Builder
+	HasText bool
+}
+
+// Add converts the value of the expression to text and appends it to the result,
+// preceded by the separator unless it is the first value. NULL values are ignored
+// and a NULL separator is treated as an empty one.
+func (s *StringAggAggregator) Add(d document.Document) error {
+	v, err := s.Fn.Expr.Eval(EvalStack{
+		Document: d,
+	})
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if v.Type == document.NullValue {
+		return nil
+	}
+
+	if v.Type != document.TextValue {
+		v, err = v.CastAsText()
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.HasText {
+		sep, err := s.Fn.Separator.Eval(EvalStack{
+			Document: d,
+		})
+		if err != nil && err != document.ErrFieldNotFound {
+			return err
+		}
+
+		switch sep.Type {
+		case document.NullValue:
+		case document.TextValue:
+			s.Builder.WriteString(sep.V.(string))
+		default:
+			return fmt.Errorf("STRING_AGG() separator must be a text, got %s", sep.Type)
+		}
+	}
+
+	s.Builder.WriteString(v.V.(string))
+	s.HasText = true
+	return nil
+}
+
+// Aggregate adds a field to the given buffer with the concatenated text,
+// or NULL if there were no non-null values.
+func (s *StringAggAggregator) Aggregate(fb *document.FieldBuffer) error {
+	if !s.HasText {
+		fb.Add(s.Fn.String(), document.NewNullValue())
+	} else {
+		fb.Add(s.Fn.String(), document.NewTextValue(s.Builder.String()))
+	}
+
+	return nil
+}
+
+// VarianceFunc is the VARIANCE aggregator function.
+// It returns the sample variance of the non-null numeric values.
+type VarianceFunc struct {
+	Expr     Expr
+	Alias    string
+	Distinct bool
+}
+
+// Eval extracts the variance from the given document and returns it.
+func (v *VarianceFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, errors.New("misuse of aggregation function VARIANCE()")
+	}
+	return ctx.Document.GetByField(v.String())
+}
+
+// SetAlias implements the planner.AggregatorBuilder interface.
+func (v *VarianceFunc) SetAlias(alias string) {
+	v.Alias = alias
+}
+
+// NewAggregator implements the planner.AggregatorBuilder interface.
+func (v *VarianceFunc) NewAggregator(group document.Value) document.Aggregator {
+	return newDistinctAggregator(v.Distinct, v.Expr, &VarianceAggregator{
+		Fn:   v,
+		Expr: v.Expr,
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (v *VarianceFunc) SetDistinct() {
+	v.Distinct = true
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (v *VarianceFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*VarianceFunc)
+	if !ok || v.Distinct != o.Distinct {
+		return false
+	}
+
+	return Equal(v.Expr, o.Expr)
+}
+
+// String returns the alias if non-zero, otherwise it returns a string representation
+// of the aggregate expression.
+func (v *VarianceFunc) String() string {
+	if v.Alias != "" {
+		return v.Alias
+	}
+
+	return fmt.Sprintf("VARIANCE(%s%v)", distinctString(v.Distinct), v.Expr)
+}
+
+// StdDevFunc is the STDDEV aggregator function.
+// It returns the sample standard deviation of the non-null numeric values.
+type StdDevFunc struct {
+	Expr     Expr
+	Alias    string
+	Distinct bool
+}
+
+// Eval extracts the standard deviation from the given document and returns it.
+func (s *StdDevFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, errors.New("misuse of aggregation function STDDEV()")
+	}
+	return ctx.Document.GetByField(s.String())
+}
+
+// SetAlias implements the planner.AggregatorBuilder interface.
+func (s *StdDevFunc) SetAlias(alias string) {
+	s.Alias = alias
+}
+
+// NewAggregator implements the planner.AggregatorBuilder interface.
+func (s *StdDevFunc) NewAggregator(group document.Value) document.Aggregator {
+	return newDistinctAggregator(s.Distinct, s.Expr, &VarianceAggregator{
+		Fn:     s,
+		Expr:   s.Expr,
+		StdDev: true,
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (s *StdDevFunc) SetDistinct() {
+	s.Distinct = true
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s *StdDevFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*StdDevFunc)
+	if !ok || s.Distinct != o.Distinct {
+		return false
+	}
+
+	return Equal(s.Expr, o.Expr)
+}
+
+// String returns the alias if non-zero, otherwise it returns a string representation
+// of the aggregate expression.
+func (s *StdDevFunc) String() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+
+	return fmt.Sprintf("STDDEV(%s%v)", distinctString(s.Distinct), s.Expr)
+}
+
+// VarianceAggregator computes the sample variance of the non-null numeric values
+// using Welford's online algorithm. It is used by both VARIANCE and STDDEV.
+type VarianceAggregator struct {
+	Fn   fmt.Stringer
+	Expr Expr
+	// StdDev returns the square root of the variance.
+	StdDev  bool
+	Counter int64
+	Mean    float64
+	M2      float64
+}
+
+// Add updates the mean and the sum of squared differences from the mean.
+func (s *VarianceAggregator) Add(d document.Document) error {
+	v, err := s.Expr.Eval(EvalStack{
+		Document: d,
+	})
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if !v.Type.IsNumber() {
+		return nil
+	}
+
+	f, err := v.CastAsDouble()
+	if err != nil {
+		return err
+	}
+	x := f.V.(float64)
+
+	s.Counter++
+	delta := x - s.Mean
+	s.Mean += delta / float64(s.Counter)
+	s.M2 += delta * (x - s.Mean)
+
+	return nil
+}
+
+// Aggregate adds a field to the given buffer with the result,
+// or NULL if there were less than two values.
+func (s *VarianceAggregator) Aggregate(fb *document.FieldBuffer) error {
+	if s.Counter < 2 {
+		fb.Add(s.Fn.String(), document.NewNullValue())
+		return nil
+	}
+
+	res := s.M2 / float64(s.Counter-1)
+	if s.StdDev {
+		res = math.Sqrt(res)
+	}
+	fb.Add(s.Fn.String(), document.NewDoubleValue(res))
+
+	return nil
+}
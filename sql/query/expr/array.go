@@ -0,0 +1,156 @@
+package expr
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+)
+
+// arrayArg returns the array of v, or an error if v is not an array.
+func arrayArg(v document.Value) (document.Array, error) {
+	if v.Type != document.ArrayValue {
+		return nil, fmt.Errorf("expected array, got %s", v.Type)
+	}
+
+	return v.V.(document.Array), nil
+}
+
+// arrayLength returns the number of values of an array.
+func arrayLength(args []document.Value) (document.Value, error) {
+	a, err := arrayArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	n, err := document.ArrayLength(a)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewIntegerValue(int64(n)), nil
+}
+
+// arrayContains returns true if one of the values of the array is equal to the second argument.
+func arrayContains(args []document.Value) (document.Value, error) {
+	a, err := arrayArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	ok, err := document.ArrayContains(a, args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewBoolValue(ok), nil
+}
+
+// arrayAppend returns a new array made of the values of the array followed by the second argument,
+// which can be NULL. It returns NULL if the array is NULL.
+func arrayAppend(args []document.Value) (document.Value, error) {
+	if args[0].Type == document.NullValue {
+		return nullLitteral, nil
+	}
+
+	a, err := arrayArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	var vb document.ValueBuffer
+	err = vb.ScanArray(a)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewArrayValue(vb.Append(args[1])), nil
+}
+
+// arrayRemove returns a new array made of the values of the array
+// that are not equal to the second argument, which can be NULL.
+// It returns NULL if the array is NULL.
+func arrayRemove(args []document.Value) (document.Value, error) {
+	if args[0].Type == document.NullValue {
+		return nullLitteral, nil
+	}
+
+	a, err := arrayArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	vb := document.NewValueBuffer()
+	err = a.Iterate(func(i int, v document.Value) error {
+		// NULL is never equal to anything with IsEqual.
+		if v.Type == document.NullValue && args[1].Type == document.NullValue {
+			return nil
+		}
+
+		ok, err := v.IsEqual(args[1])
+		if err != nil || ok {
+			return err
+		}
+
+		vb = vb.Append(v)
+		return nil
+	})
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
+// arraySlice returns a new array made of the values of the array from the start index,
+// included, to the end index, excluded, or to the end of the array if there is none.
+// Indexes start at 0. Negative indexes are counted from the end of the array.
+func arraySlice(args []document.Value) (document.Value, error) {
+	a, err := arrayArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	var vb document.ValueBuffer
+	err = vb.ScanArray(a)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	bound := func(v document.Value) (int, error) {
+		i, err := integerArg(v)
+		if err != nil {
+			return 0, err
+		}
+
+		if i < 0 {
+			i += len(vb)
+		}
+
+		switch {
+		case i < 0:
+			return 0, nil
+		case i > len(vb):
+			return len(vb), nil
+		}
+		return i, nil
+	}
+
+	start, err := bound(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	end := len(vb)
+	if len(args) > 2 {
+		end, err = bound(args[2])
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	if start >= end {
+		return document.NewArrayValue(document.NewValueBuffer()), nil
+	}
+
+	return document.NewArrayValue(document.NewValueBuffer(vb[start:end]...)), nil
+}
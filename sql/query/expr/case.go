@@ -0,0 +1,124 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A CaseExpr is a conditional expression.
+// In its simple form, the operand is compared with the condition of each WHEN clause.
+// Otherwise, each condition is evaluated as a boolean.
+// It returns the result of the first matching WHEN clause or, if none matches,
+// the result of the ELSE clause. If there is no ELSE clause, it returns NULL.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []WhenClause
+	Else    Expr
+}
+
+// A WhenClause is a condition of a CASE expression, along with its result.
+type WhenClause struct {
+	Cond Expr
+	Then Expr
+}
+
+// Eval evaluates the conditions one after the other and returns
+// the result of the first one that matches.
+func (c CaseExpr) Eval(ctx EvalStack) (document.Value, error) {
+	var operand document.Value
+	if c.Operand != nil {
+		var err error
+		operand, err = c.Operand.Eval(ctx)
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	for _, w := range c.Whens {
+		ok, err := c.match(ctx, operand, w.Cond)
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		if ok {
+			return w.Then.Eval(ctx)
+		}
+	}
+
+	if c.Else != nil {
+		return c.Else.Eval(ctx)
+	}
+
+	return nullLitteral, nil
+}
+
+// match returns true if the condition is satisfied.
+// Comparing with NULL never matches.
+func (c CaseExpr) match(ctx EvalStack, operand document.Value, cond Expr) (bool, error) {
+	v, err := cond.Eval(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if c.Operand == nil {
+		return v.IsTruthy()
+	}
+
+	if operand.Type == document.NullValue || v.Type == document.NullValue {
+		return false, nil
+	}
+
+	return operand.IsEqual(v)
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (c CaseExpr) IsEqual(other Expr) bool {
+	o, ok := other.(CaseExpr)
+	if !ok {
+		return false
+	}
+
+	if !optionalEqual(c.Operand, o.Operand) || !optionalEqual(c.Else, o.Else) || len(c.Whens) != len(o.Whens) {
+		return false
+	}
+
+	for i := range c.Whens {
+		if !Equal(c.Whens[i].Cond, o.Whens[i].Cond) || !Equal(c.Whens[i].Then, o.Whens[i].Then) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// optionalEqual compares two expressions that can be nil.
+func optionalEqual(a, b Expr) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return Equal(a, b)
+}
+
+func (c CaseExpr) String() string {
+	var b strings.Builder
+
+	b.WriteString("CASE")
+	if c.Operand != nil {
+		fmt.Fprintf(&b, " %v", c.Operand)
+	}
+
+	for _, w := range c.Whens {
+		fmt.Fprintf(&b, " WHEN %v THEN %v", w.Cond, w.Then)
+	}
+
+	if c.Else != nil {
+		fmt.Fprintf(&b, " ELSE %v", c.Else)
+	}
+
+	b.WriteString(" END")
+	return b.String()
+}
@@ -464,7 +464,7 @@ func In(a, b Expr) Expr {
 }
 
 func (op inOp) Eval(ctx EvalStack) (document.Value, error) {
-	a, b, err := op.simpleOperator.eval(ctx)
+	a, b, err := op.eval(ctx)
 	if err != nil {
 		return nullLitteral, err
 	}
@@ -488,6 +488,23 @@ func (op inOp) Eval(ctx EvalStack) (document.Value, error) {
 	return falseLitteral, nil
 }
 
+// eval evaluates both operands. If the right operand is a subquery,
+// it is evaluated as an array.
+func (op inOp) eval(ctx EvalStack) (document.Value, document.Value, error) {
+	sq, ok := op.b.(*Subquery)
+	if !ok {
+		return op.simpleOperator.eval(ctx)
+	}
+
+	a, err := op.a.Eval(ctx)
+	if err != nil {
+		return nullLitteral, nullLitteral, err
+	}
+
+	b, err := sq.EvalArray(ctx)
+	return a, b, err
+}
+
 func (op inOp) IterateIndex(idx *database.Index, tb *database.Table, v document.Value, fn func(d document.Document) error) error {
 	if v.Type != document.ArrayValue {
 		return errors.New("IN operator takes an array")
@@ -0,0 +1,78 @@
+package expr
+
+import (
+	"bytes"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A DistinctSetter is an aggregate function that accepts the DISTINCT qualifier,
+// e.g. COUNT(DISTINCT a).
+type DistinctSetter interface {
+	SetDistinct()
+}
+
+// distinctString returns the DISTINCT qualifier, as it appears in
+// the string representation of an aggregate function.
+func distinctString(distinct bool) string {
+	if distinct {
+		return "DISTINCT "
+	}
+
+	return ""
+}
+
+// distinctAggregator wraps an aggregator so that it only receives the documents
+// for which the expression evaluates to a value that wasn't seen yet in the group.
+type distinctAggregator struct {
+	document.Aggregator
+
+	expr Expr
+	seen map[string]struct{}
+	buf  bytes.Buffer
+}
+
+// newDistinctAggregator returns agg if distinct is false,
+// otherwise it wraps it into a distinctAggregator.
+func newDistinctAggregator(distinct bool, e Expr, agg document.Aggregator) document.Aggregator {
+	if !distinct {
+		return agg
+	}
+
+	return &distinctAggregator{
+		Aggregator: agg,
+		expr:       e,
+		seen:       make(map[string]struct{}),
+	}
+}
+
+// Add evaluates the expression and passes d to the underlying aggregator
+// if the result wasn't seen before. NULL is considered as any other value.
+func (a *distinctAggregator) Add(d document.Document) error {
+	v, err := a.expr.Eval(EvalStack{
+		Document: d,
+	})
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	// integers and doubles that are equal must have the same key.
+	if v.Type == document.IntegerValue {
+		v, err = v.CastAsDouble()
+		if err != nil {
+			return err
+		}
+	}
+
+	a.buf.Reset()
+	err = document.NewValueEncoder(&a.buf).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := a.seen[a.buf.String()]; ok {
+		return nil
+	}
+	a.seen[a.buf.String()] = struct{}{}
+
+	return a.Aggregator.Add(d)
+}
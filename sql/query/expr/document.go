@@ -0,0 +1,87 @@
+package expr
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+)
+
+// documentArg returns the document of v, or an error if v is not a document.
+func documentArg(v document.Value) (document.Document, error) {
+	if v.Type != document.DocumentValue {
+		return nil, fmt.Errorf("expected document, got %s", v.Type)
+	}
+
+	return v.V.(document.Document), nil
+}
+
+// keys returns the array of the fields at the root of a document,
+// sorted lexicographically.
+func keys(args []document.Value) (document.Value, error) {
+	d, err := documentArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	fields, err := document.Fields(d)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	vb := document.NewValueBuffer()
+	for _, f := range fields {
+		vb = vb.Append(document.NewTextValue(f))
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
+// hasField returns true if the document has a field at its root named after the second argument.
+func hasField(args []document.Value) (document.Value, error) {
+	d, err := documentArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	field, err := textArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	_, err = d.GetByField(field)
+	switch err {
+	case nil:
+		return trueLitteral, nil
+	case document.ErrFieldNotFound:
+		return falseLitteral, nil
+	}
+
+	return nullLitteral, err
+}
+
+// merge returns a new document made of the fields of both documents.
+// Fields of the second document replace the fields of the first one with the same name.
+func merge(args []document.Value) (document.Value, error) {
+	var fb document.FieldBuffer
+
+	for _, v := range args {
+		d, err := documentArg(v)
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		err = d.Iterate(func(field string, v document.Value) error {
+			if _, err := fb.GetByField(field); err == nil {
+				return fb.Replace(field, v)
+			}
+
+			fb.Add(field, v)
+			return nil
+		})
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	return document.NewDocumentValue(&fb), nil
+}
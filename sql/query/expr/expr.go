@@ -133,6 +133,44 @@ func (p Parentheses) Eval(es EvalStack) (document.Value, error) {
 	return p.E.Eval(es)
 }
 
+// Walk traverses the expression tree in depth-first order, starting with e.
+// It calls fn for every expression and stops descending
+// into the children of an expression if fn returns false.
+func Walk(e Expr, fn func(Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+
+	switch t := e.(type) {
+	case Operator:
+		Walk(t.LeftHand(), fn)
+		Walk(t.RightHand(), fn)
+	case Parentheses:
+		Walk(t.E, fn)
+	case LiteralExprList:
+		for _, e := range t {
+			Walk(e, fn)
+		}
+	case KVPairs:
+		for _, kv := range t {
+			Walk(kv.V, fn)
+		}
+	case CastFunc:
+		Walk(t.Expr, fn)
+	case ScalarFunc:
+		for _, e := range t.Args {
+			Walk(e, fn)
+		}
+	case CaseExpr:
+		Walk(t.Operand, fn)
+		for _, w := range t.Whens {
+			Walk(w.Cond, fn)
+			Walk(w.Then, fn)
+		}
+		Walk(t.Else, fn)
+	}
+}
+
 func invertBoolResult(f func(ctx EvalStack) (document.Value, error)) func(ctx EvalStack) (document.Value, error) {
 	return func(ctx EvalStack) (document.Value, error) {
 		v, err := f(ctx)
@@ -62,10 +62,16 @@ func TestString(t *testing.T) {
 		`{"a": "foo", "b": 10}`,
 		"pk()",
 		"CAST(10 AS integer)",
+		`CASE a WHEN 1 THEN "one" ELSE "other" END`,
+		`LOWER(a)`,
+		`SUBSTR("hello", 1, 2)`,
+		`POSITION("l" IN a)`,
+		`EXTRACT(year FROM a)`,
+		`DATE_TRUNC("day", NOW())`,
 	}
 
 	var operators = []string{
-		"=", ">", ">=", "<", "<=",
+		"=", ">", ">=", "<", "<=", "=~", "!~",
 		"+", "-", "*", "/", "%", "&", "|", "^",
 		"AND", "OR",
 	}
@@ -16,7 +16,7 @@ type Functions struct {
 
 // BuiltinFunctions returns default map of builtin functions.
 func BuiltinFunctions() map[string]func(args ...Expr) (Expr, error) {
-	return map[string]func(args ...Expr) (Expr, error){
+	fns := map[string]func(args ...Expr) (Expr, error){
 		"pk": func(args ...Expr) (Expr, error) {
 			if len(args) != 0 {
 				return nil, fmt.Errorf("pk() takes no arguments")
@@ -53,7 +53,114 @@ func BuiltinFunctions() map[string]func(args ...Expr) (Expr, error) {
 			}
 			return &AvgFunc{Expr: args[0]}, nil
 		},
+		"array_agg": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("ARRAY_AGG() takes 1 argument")
+			}
+			return &ArrayAggFunc{Expr: args[0]}, nil
+		},
+		"string_agg": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("STRING_AGG() takes 2 arguments")
+			}
+			return &StringAggFunc{Expr: args[0], Separator: args[1]}, nil
+		},
+		"variance": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("VARIANCE() takes 1 argument")
+			}
+			return &VarianceFunc{Expr: args[0]}, nil
+		},
+		"stddev": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("STDDEV() takes 1 argument")
+			}
+			return &StdDevFunc{Expr: args[0]}, nil
+		},
+		"row_number": func(args ...Expr) (Expr, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("ROW_NUMBER() takes no arguments")
+			}
+			return RowNumberFunc{}, nil
+		},
+		"rank": func(args ...Expr) (Expr, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("RANK() takes no arguments")
+			}
+			return RankFunc{}, nil
+		},
+		"dense_rank": func(args ...Expr) (Expr, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("DENSE_RANK() takes no arguments")
+			}
+			return DenseRankFunc{}, nil
+		},
+		"lag": func(args ...Expr) (Expr, error) {
+			if len(args) < 1 || len(args) > 3 {
+				return nil, fmt.Errorf("LAG() takes between 1 and 3 arguments")
+			}
+			f := LagFunc{Expr: args[0]}
+			if len(args) > 1 {
+				f.Offset = args[1]
+			}
+			if len(args) > 2 {
+				f.Default = args[2]
+			}
+			return &f, nil
+		},
+		"lead": func(args ...Expr) (Expr, error) {
+			if len(args) < 1 || len(args) > 3 {
+				return nil, fmt.Errorf("LEAD() takes between 1 and 3 arguments")
+			}
+			f := LeadFunc{Expr: args[0]}
+			if len(args) > 1 {
+				f.Offset = args[1]
+			}
+			if len(args) > 2 {
+				f.Default = args[2]
+			}
+			return &f, nil
+		},
+		"position": func(args ...Expr) (Expr, error) {
+			// POSITION(substring IN string) is parsed as a single IN operator.
+			if len(args) != 1 {
+				return nil, fmt.Errorf("POSITION() takes a substring IN a string")
+			}
+			op, ok := args[0].(inOp)
+			if !ok {
+				return nil, fmt.Errorf("POSITION() takes a substring IN a string")
+			}
+			return ScalarFunc{Name: "POSITION", Args: []Expr{op.a, op.b}}, nil
+		},
+		"extract": func(args ...Expr) (Expr, error) {
+			// EXTRACT(field FROM expr) is parsed as two arguments.
+			// The field is parsed as a path and converted to a text.
+			if len(args) != 2 {
+				return nil, fmt.Errorf("EXTRACT() takes a field FROM a timestamp")
+			}
+			field := args[0]
+			if p, ok := field.(Path); ok && len(p) == 1 && p[0].FieldName != "" {
+				field = TextValue(strings.ToLower(p[0].FieldName))
+			}
+			return ScalarFunc{Name: "EXTRACT", Args: []Expr{field, args[1]}}, nil
+		},
+		"now": func(args ...Expr) (Expr, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("NOW() takes no arguments")
+			}
+			return NowFunc{}, nil
+		},
 	}
+
+	for name, sf := range scalarFunctions {
+		if _, ok := fns[name]; ok {
+			continue
+		}
+
+		fns[name] = sf.builder(strings.ToUpper(name))
+	}
+
+	return fns
 }
 
 func NewFunctions() Functions {
@@ -63,8 +170,15 @@ func NewFunctions() Functions {
 }
 
 // AddFunc adds function to the map.
+// Names are case insensitive.
 func (f Functions) AddFunc(name string, fn func(args ...Expr) (Expr, error)) {
-	f.m[name] = fn
+	f.m[strings.ToLower(name)] = fn
+}
+
+// HasFunc returns whether a function with the given name exists.
+func (f Functions) HasFunc(name string) bool {
+	_, ok := f.m[strings.ToLower(name)]
+	return ok
 }
 
 // GetFunc return a function expression by name.
@@ -108,10 +222,162 @@ func (k PKFunc) String() string {
 	return "pk()"
 }
 
+// A ScalarFunc is a builtin function that computes a value
+// from the values of its arguments, e.g. LOWER(a).
+type ScalarFunc struct {
+	Name string
+	Args []Expr
+
+	// fn is the function of a user-defined function.
+	// It is nil for builtin functions.
+	fn *scalarFunction
+}
+
+// Eval evaluates the arguments and calls the function with their values.
+// Unless the function handles NULL values itself, it returns NULL
+// if one of the arguments is NULL.
+func (f ScalarFunc) Eval(ctx EvalStack) (document.Value, error) {
+	sf, ok := scalarFunctions[strings.ToLower(f.Name)]
+	if f.fn != nil {
+		sf, ok = *f.fn, true
+	}
+	if !ok {
+		return nullLitteral, fmt.Errorf("no such function: %q", f.Name)
+	}
+
+	args := make([]document.Value, len(f.Args))
+	for i, e := range f.Args {
+		v, err := e.Eval(ctx)
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		if v.Type == document.NullValue && !sf.acceptNull {
+			return nullLitteral, nil
+		}
+
+		args[i] = v
+	}
+
+	v, err := sf.eval(args)
+	if err != nil {
+		return nullLitteral, fmt.Errorf("%s(): %w", f.Name, err)
+	}
+
+	return v, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (f ScalarFunc) IsEqual(other Expr) bool {
+	o, ok := other.(ScalarFunc)
+	if !ok || !strings.EqualFold(f.Name, o.Name) || len(f.Args) != len(o.Args) {
+		return false
+	}
+
+	for i := range f.Args {
+		if !Equal(f.Args[i], o.Args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f ScalarFunc) String() string {
+	if strings.EqualFold(f.Name, "POSITION") && len(f.Args) == 2 {
+		return fmt.Sprintf("POSITION(%v IN %v)", f.Args[0], f.Args[1])
+	}
+
+	if strings.EqualFold(f.Name, "EXTRACT") && len(f.Args) == 2 {
+		if lit, ok := f.Args[0].(LiteralValue); ok && lit.Type == document.TextValue {
+			return fmt.Sprintf("EXTRACT(%s FROM %v)", lit.V, f.Args[1])
+		}
+	}
+
+	args := make([]string, len(f.Args))
+	for i, e := range f.Args {
+		args[i] = fmt.Sprintf("%v", e)
+	}
+
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(args, ", "))
+}
+
+// scalarFunctions lists the builtin scalar functions, by lowercase name.
+var scalarFunctions = map[string]scalarFunction{
+	"lower":       {minArgs: 1, maxArgs: 1, eval: textFunc(strings.ToLower)},
+	"upper":       {minArgs: 1, maxArgs: 1, eval: textFunc(strings.ToUpper)},
+	"trim":        {minArgs: 1, maxArgs: 2, eval: trimFunc(strings.TrimFunc, strings.Trim)},
+	"ltrim":       {minArgs: 1, maxArgs: 2, eval: trimFunc(strings.TrimLeftFunc, strings.TrimLeft)},
+	"rtrim":       {minArgs: 1, maxArgs: 2, eval: trimFunc(strings.TrimRightFunc, strings.TrimRight)},
+	"substr":      {minArgs: 2, maxArgs: 3, eval: substr},
+	"length":      {minArgs: 1, maxArgs: 1, eval: length},
+	"concat":      {minArgs: 1, maxArgs: -1, acceptNull: true, eval: concat},
+	"replace":     {minArgs: 3, maxArgs: 3, eval: replace},
+	"split":       {minArgs: 2, maxArgs: 2, eval: split},
+	"starts_with": {minArgs: 2, maxArgs: 2, eval: startsWith},
+	"position":    {minArgs: 2, maxArgs: 2, eval: position},
+	"lpad":        {minArgs: 2, maxArgs: 3, eval: padFunc(true)},
+	"rpad":        {minArgs: 2, maxArgs: 3, eval: padFunc(false)},
+
+	"array_length":   {minArgs: 1, maxArgs: 1, eval: arrayLength},
+	"array_contains": {minArgs: 2, maxArgs: 2, eval: arrayContains},
+	"array_append":   {minArgs: 2, maxArgs: 2, acceptNull: true, eval: arrayAppend},
+	"array_remove":   {minArgs: 2, maxArgs: 2, acceptNull: true, eval: arrayRemove},
+	"array_slice":    {minArgs: 2, maxArgs: 3, eval: arraySlice},
+
+	"keys":      {minArgs: 1, maxArgs: 1, eval: keys},
+	"has_field": {minArgs: 2, maxArgs: 2, eval: hasField},
+	"merge":     {minArgs: 2, maxArgs: 2, eval: merge},
+
+	"date_trunc": {minArgs: 2, maxArgs: 2, eval: dateTrunc},
+	"extract":    {minArgs: 2, maxArgs: 2, eval: extract},
+
+	"coalesce": {minArgs: 1, maxArgs: -1, acceptNull: true, eval: coalesce},
+	"ifnull":   {minArgs: 2, maxArgs: 2, acceptNull: true, eval: coalesce},
+	"nullif":   {minArgs: 2, maxArgs: 2, acceptNull: true, eval: nullIf},
+	"typeof":   {minArgs: 1, maxArgs: 1, acceptNull: true, eval: typeOf},
+}
+
+// A scalarFunction describes a builtin scalar function.
+type scalarFunction struct {
+	// minimum and maximum number of arguments.
+	// if maxArgs is negative, the number of arguments is not limited.
+	minArgs, maxArgs int
+	// if true, NULL values are passed to eval.
+	acceptNull bool
+	eval       func(args []document.Value) (document.Value, error)
+}
+
+// builder returns a function that creates a ScalarFunc
+// after making sure it is called with the right number of arguments.
+func (sf scalarFunction) builder(name string) func(args ...Expr) (Expr, error) {
+	return func(args ...Expr) (Expr, error) {
+		if len(args) < sf.minArgs || sf.maxArgs >= 0 && len(args) > sf.maxArgs {
+			switch {
+			case sf.minArgs == sf.maxArgs && sf.minArgs == 1:
+				return nil, fmt.Errorf("%s() takes 1 argument", name)
+			case sf.minArgs == sf.maxArgs:
+				return nil, fmt.Errorf("%s() takes %d arguments", name, sf.minArgs)
+			case sf.maxArgs < 0:
+				return nil, fmt.Errorf("%s() takes at least %d argument(s)", name, sf.minArgs)
+			}
+			return nil, fmt.Errorf("%s() takes between %d and %d arguments", name, sf.minArgs, sf.maxArgs)
+		}
+
+		return ScalarFunc{Name: name, Args: args}, nil
+	}
+}
+
 // CastFunc represents the CAST expression.
 type CastFunc struct {
 	Expr   Expr
 	CastAs document.ValueType
+
+	// Precision and Scale of the result, if it is a decimal.
+	// A zero precision means the decimal is not rounded.
+	Precision int
+	Scale     int
 }
 
 // Eval returns the primary key of the current document.
@@ -121,7 +387,17 @@ func (c CastFunc) Eval(ctx EvalStack) (document.Value, error) {
 		return v, err
 	}
 
-	return v.CastAs(c.CastAs)
+	v, err = v.CastAs(c.CastAs)
+	if err != nil || v.Type != document.DecimalValue || c.Precision == 0 {
+		return v, err
+	}
+
+	d, err := v.V.(document.Decimal).WithPrecision(c.Precision, c.Scale)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewDecimalValue(d), nil
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -136,7 +412,7 @@ func (c CastFunc) IsEqual(other Expr) bool {
 		return false
 	}
 
-	if c.CastAs != o.CastAs {
+	if c.CastAs != o.CastAs || c.Precision != o.Precision || c.Scale != o.Scale {
 		return false
 	}
 
@@ -148,6 +424,10 @@ func (c CastFunc) IsEqual(other Expr) bool {
 }
 
 func (c CastFunc) String() string {
+	if c.Precision > 0 {
+		return fmt.Sprintf("CAST(%v AS %v(%d, %d))", c.Expr, c.CastAs, c.Precision, c.Scale)
+	}
+
 	return fmt.Sprintf("CAST(%v AS %v)", c.Expr, c.CastAs)
 }
 
@@ -156,6 +436,7 @@ type CountFunc struct {
 	Expr     Expr
 	Alias    string
 	Wildcard bool
+	Distinct bool
 }
 
 func (c *CountFunc) Eval(ctx EvalStack) (document.Value, error) {
@@ -170,9 +451,14 @@ func (c *CountFunc) SetAlias(alias string) {
 }
 
 func (c *CountFunc) NewAggregator(group document.Value) document.Aggregator {
-	return &CountAggregator{
+	return newDistinctAggregator(c.Distinct, c.Expr, &CountAggregator{
 		Fn: c,
-	}
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (c *CountFunc) SetDistinct() {
+	c.Distinct = true
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -183,7 +469,7 @@ func (c *CountFunc) IsEqual(other Expr) bool {
 	}
 
 	o, ok := other.(*CountFunc)
-	if !ok {
+	if !ok || c.Distinct != o.Distinct {
 		return false
 	}
 
@@ -199,7 +485,11 @@ func (c *CountFunc) String() string {
 		return c.Alias
 	}
 
-	return fmt.Sprintf("COUNT(%v)", c.Expr)
+	if c.Wildcard {
+		return "COUNT(*)"
+	}
+
+	return fmt.Sprintf("COUNT(%s%v)", distinctString(c.Distinct), c.Expr)
 }
 
 // CountAggregator is an aggregator that counts non-null expressions.
@@ -236,8 +526,9 @@ func (c *CountAggregator) Aggregate(fb *document.FieldBuffer) error {
 
 // MinFunc is the MIN aggregator function.
 type MinFunc struct {
-	Expr  Expr
-	Alias string
+	Expr     Expr
+	Alias    string
+	Distinct bool
 }
 
 // Eval extracts the min value from the given document and returns it.
@@ -255,9 +546,14 @@ func (m *MinFunc) SetAlias(alias string) {
 
 // NewAggregator implements the planner.AggregatorBuilder interface.
 func (m *MinFunc) NewAggregator(group document.Value) document.Aggregator {
-	return &MinAggregator{
+	return newDistinctAggregator(m.Distinct, m.Expr, &MinAggregator{
 		Fn: m,
-	}
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (m *MinFunc) SetDistinct() {
+	m.Distinct = true
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -268,7 +564,7 @@ func (m *MinFunc) IsEqual(other Expr) bool {
 	}
 
 	o, ok := other.(*MinFunc)
-	if !ok {
+	if !ok || m.Distinct != o.Distinct {
 		return false
 	}
 
@@ -282,7 +578,7 @@ func (m *MinFunc) String() string {
 		return m.Alias
 	}
 
-	return fmt.Sprintf("MIN(%v)", m.Expr)
+	return fmt.Sprintf("MIN(%s%v)", distinctString(m.Distinct), m.Expr)
 }
 
 // MinAggregator is an aggregator that returns the minimum non-null value.
@@ -336,8 +632,9 @@ func (m *MinAggregator) Aggregate(fb *document.FieldBuffer) error {
 
 // MaxFunc is the MAX aggregator function.
 type MaxFunc struct {
-	Expr  Expr
-	Alias string
+	Expr     Expr
+	Alias    string
+	Distinct bool
 }
 
 // Eval extracts the max value from the given document and returns it.
@@ -355,9 +652,14 @@ func (m *MaxFunc) SetAlias(alias string) {
 
 // NewAggregator implements the planner.AggregatorBuilder interface.
 func (m *MaxFunc) NewAggregator(group document.Value) document.Aggregator {
-	return &MaxAggregator{
+	return newDistinctAggregator(m.Distinct, m.Expr, &MaxAggregator{
 		Fn: m,
-	}
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (m *MaxFunc) SetDistinct() {
+	m.Distinct = true
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -368,7 +670,7 @@ func (m *MaxFunc) IsEqual(other Expr) bool {
 	}
 
 	o, ok := other.(*MaxFunc)
-	if !ok {
+	if !ok || m.Distinct != o.Distinct {
 		return false
 	}
 
@@ -382,7 +684,7 @@ func (m *MaxFunc) String() string {
 		return m.Alias
 	}
 
-	return fmt.Sprintf("MAX(%v)", m.Expr)
+	return fmt.Sprintf("MAX(%s%v)", distinctString(m.Distinct), m.Expr)
 }
 
 // MaxAggregator is an aggregator that returns the minimum non-null value.
@@ -436,8 +738,9 @@ func (m *MaxAggregator) Aggregate(fb *document.FieldBuffer) error {
 
 // SumFunc is the SUM aggregator function.
 type SumFunc struct {
-	Expr  Expr
-	Alias string
+	Expr     Expr
+	Alias    string
+	Distinct bool
 }
 
 // Eval extracts the sum value from the given document and returns it.
@@ -455,9 +758,14 @@ func (s *SumFunc) SetAlias(alias string) {
 
 // NewAggregator implements the planner.AggregatorBuilder interface.
 func (s *SumFunc) NewAggregator(group document.Value) document.Aggregator {
-	return &SumAggregator{
+	return newDistinctAggregator(s.Distinct, s.Expr, &SumAggregator{
 		Fn: s,
-	}
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (s *SumFunc) SetDistinct() {
+	s.Distinct = true
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -468,7 +776,7 @@ func (s *SumFunc) IsEqual(other Expr) bool {
 	}
 
 	o, ok := other.(*SumFunc)
-	if !ok {
+	if !ok || s.Distinct != o.Distinct {
 		return false
 	}
 
@@ -482,7 +790,7 @@ func (s *SumFunc) String() string {
 		return s.Alias
 	}
 
-	return fmt.Sprintf("SUM(%v)", s.Expr)
+	return fmt.Sprintf("SUM(%s%v)", distinctString(s.Distinct), s.Expr)
 }
 
 // SumAggregator is an aggregator that returns the minimum non-null value.
@@ -490,11 +798,13 @@ type SumAggregator struct {
 	Fn   *SumFunc
 	SumI *int64
 	SumF *float64
+	SumD *document.Decimal
 }
 
 // Add stores the sum of all non-NULL numeric values in the group.
 // The result is an integer value if all summed values are integers.
 // If any of the value is a double, the returned result will be a double.
+// Otherwise, if any of the value is a decimal, the returned result will be a decimal.
 func (s *SumAggregator) Add(d document.Document) error {
 	v, err := s.Fn.Expr.Eval(EvalStack{
 		Document: d,
@@ -502,23 +812,25 @@ func (s *SumAggregator) Add(d document.Document) error {
 	if err != nil && err != document.ErrFieldNotFound {
 		return err
 	}
-	if v.Type != document.IntegerValue && v.Type != document.DoubleValue {
+	if !v.Type.IsNumber() {
 		return nil
 	}
 
 	if s.SumF != nil {
-		if v.Type == document.IntegerValue {
-			*s.SumF += float64(v.V.(int64))
-		} else {
-			*s.SumF += float64(v.V.(float64))
+		f, err := v.CastAsDouble()
+		if err != nil {
+			return err
 		}
+		*s.SumF += f.V.(float64)
 
 		return nil
 	}
 
 	if v.Type == document.DoubleValue {
 		var sumF float64
-		if s.SumI != nil {
+		if s.SumD != nil {
+			sumF = s.SumD.Float64()
+		} else if s.SumI != nil {
 			sumF = float64(*s.SumI)
 		}
 		s.SumF = &sumF
@@ -527,6 +839,24 @@ func (s *SumAggregator) Add(d document.Document) error {
 		return nil
 	}
 
+	if s.SumD == nil && v.Type == document.DecimalValue {
+		var sumD document.Decimal
+		if s.SumI != nil {
+			sumD = document.NewDecimal(*s.SumI, 0)
+		}
+		s.SumD = &sumD
+	}
+
+	if s.SumD != nil {
+		dec, err := v.CastAsDecimal()
+		if err != nil {
+			return err
+		}
+		*s.SumD = s.SumD.Add(dec.V.(document.Decimal))
+
+		return nil
+	}
+
 	if s.SumI == nil {
 		var sumI int64
 		s.SumI = &sumI
@@ -540,6 +870,8 @@ func (s *SumAggregator) Add(d document.Document) error {
 func (s *SumAggregator) Aggregate(fb *document.FieldBuffer) error {
 	if s.SumF != nil {
 		fb.Add(s.Fn.String(), document.NewDoubleValue(*s.SumF))
+	} else if s.SumD != nil {
+		fb.Add(s.Fn.String(), document.NewDecimalValue(*s.SumD))
 	} else if s.SumI != nil {
 		fb.Add(s.Fn.String(), document.NewIntegerValue(*s.SumI))
 	} else {
@@ -551,8 +883,9 @@ func (s *SumAggregator) Aggregate(fb *document.FieldBuffer) error {
 
 // AvgFunc is the AVG aggregator function.
 type AvgFunc struct {
-	Expr  Expr
-	Alias string
+	Expr     Expr
+	Alias    string
+	Distinct bool
 }
 
 // Eval extracts the average value from the given document and returns it.
@@ -570,9 +903,14 @@ func (s *AvgFunc) SetAlias(alias string) {
 
 // NewAggregator implements the planner.AggregatorBuilder interface.
 func (s *AvgFunc) NewAggregator(group document.Value) document.Aggregator {
-	return &AvgAggregator{
+	return newDistinctAggregator(s.Distinct, s.Expr, &AvgAggregator{
 		Fn: s,
-	}
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (s *AvgFunc) SetDistinct() {
+	s.Distinct = true
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -583,7 +921,7 @@ func (s *AvgFunc) IsEqual(other Expr) bool {
 	}
 
 	o, ok := other.(*AvgFunc)
-	if !ok {
+	if !ok || s.Distinct != o.Distinct {
 		return false
 	}
 
@@ -597,7 +935,7 @@ func (s *AvgFunc) String() string {
 		return s.Alias
 	}
 
-	return fmt.Sprintf("AVG(%v)", s.Expr)
+	return fmt.Sprintf("AVG(%s%v)", distinctString(s.Distinct), s.Expr)
 }
 
 // AvgAggregator is an aggregator that returns the average non-null value.
@@ -605,9 +943,17 @@ type AvgAggregator struct {
 	Fn      *AvgFunc
 	Avg     float64
 	Counter int64
+
+	// SumD is the exact sum of the values. It is used to compute
+	// the average if at least one of the values is a decimal and none of them is a double.
+	SumD       document.Decimal
+	HasDecimal bool
+	HasDouble  bool
 }
 
 // Add stores the average value of all non-NULL numeric values in the group.
+// The result is a decimal if any of the values is a decimal and none of them is a double,
+// otherwise it is a double.
 func (s *AvgAggregator) Add(d document.Document) error {
 	v, err := s.Fn.Expr.Eval(EvalStack{
 		Document: d,
@@ -619,8 +965,16 @@ func (s *AvgAggregator) Add(d document.Document) error {
 	switch v.Type {
 	case document.IntegerValue:
 		s.Avg += float64(v.V.(int64))
+		if !s.HasDouble {
+			s.SumD = s.SumD.Add(document.NewDecimal(v.V.(int64), 0))
+		}
 	case document.DoubleValue:
 		s.Avg += v.V.(float64)
+		s.HasDouble = true
+	case document.DecimalValue:
+		s.Avg += v.V.(document.Decimal).Float64()
+		s.SumD = s.SumD.Add(v.V.(document.Decimal))
+		s.HasDecimal = true
 	default:
 		return nil
 	}
@@ -633,6 +987,12 @@ func (s *AvgAggregator) Add(d document.Document) error {
 func (s *AvgAggregator) Aggregate(fb *document.FieldBuffer) error {
 	if s.Counter == 0 {
 		fb.Add(s.Fn.String(), document.NewDoubleValue(0))
+	} else if s.HasDecimal && !s.HasDouble {
+		avg, err := s.SumD.Quo(document.NewDecimal(s.Counter, 0))
+		if err != nil {
+			return err
+		}
+		fb.Add(s.Fn.String(), document.NewDecimalValue(avg))
 	} else {
 		fb.Add(s.Fn.String(), document.NewDoubleValue(s.Avg/float64(s.Counter)))
 	}
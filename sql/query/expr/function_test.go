@@ -1,10 +1,15 @@
 package expr_test
 
 import (
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPkExpr(t *testing.T) {
@@ -25,3 +30,346 @@ func TestPkExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestTextFunctions(t *testing.T) {
+	text := document.NewTextValue
+	stack := expr.EvalStack{
+		Document: document.NewFromJSON([]byte(`{"a": "  Hello, Wörld  ", "b": 10, "c": null}`)),
+	}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"LOWER('HeLLo')", text("hello"), false},
+		{"upper('HeLLo')", text("HELLO"), false},
+		{"LOWER(b)", nullLitteral, true},
+		{"LOWER(c)", nullLitteral, false},
+		{"LOWER(d)", nullLitteral, false},
+		{"TRIM(a)", text("Hello, Wörld"), false},
+		{"TRIM('xxhixx', 'x')", text("hi"), false},
+		{"LTRIM(a)", text("Hello, Wörld  "), false},
+		{"RTRIM('xxhixx', 'x')", text("xxhi"), false},
+		{"SUBSTR('hello', 2)", text("ello"), false},
+		{"SUBSTR('hello', 2, 3)", text("ell"), false},
+		{"SUBSTR('hello', 0, 2)", text("h"), false},
+		{"SUBSTR('hello', 10)", text(""), false},
+		{"SUBSTR('hello', 2, 9223372036854775807)", text("ello"), false},
+		{"SUBSTR('hello', -9223372036854775807, 9223372036854775807)", text(""), false},
+		{"SUBSTR(TRIM(a), 8, 2)", text("Wö"), false},
+		{"SUBSTR('hello', 1, -1)", nullLitteral, true},
+		{"SUBSTR('hello', 'a')", nullLitteral, true},
+		{"LENGTH('Wörld')", document.NewIntegerValue(5), false},
+		{"LENGTH('')", document.NewIntegerValue(0), false},
+		{"CONCAT('a', b, c, 'b', [1])", text("a10b[1]"), false},
+		{"CONCAT(c)", text(""), false},
+		{"REPLACE('hello', 'l', 'L')", text("heLLo"), false},
+		{"REPLACE('hello', '', 'L')", text("hello"), false},
+		{"SPLIT('a,b,,c', ',')", document.NewArrayValue(document.NewValueBuffer(text("a"), text("b"), text(""), text("c"))), false},
+		{"STARTS_WITH(TRIM(a), 'Hell')", document.NewBoolValue(true), false},
+		{"STARTS_WITH(a, 'Hell')", document.NewBoolValue(false), false},
+		{"POSITION('ö' IN a)", document.NewIntegerValue(11), false},
+		{"POSITION('z' IN a)", document.NewIntegerValue(0), false},
+		{"POSITION('z' IN c)", nullLitteral, false},
+		{"LPAD('hi', 5)", text("   hi"), false},
+		{"LPAD('hi', 5, 'ab')", text("abahi"), false},
+		{"RPAD('hi', 5, 'ab')", text("hiaba"), false},
+		{"RPAD('hello', 2)", text("he"), false},
+		{"LPAD('hello', 0)", text(""), false},
+		{"LPAD('x', 1000000000000, 'ab')", nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stack, test.res, test.fails)
+		})
+	}
+
+	t.Run("arguments", func(t *testing.T) {
+		for _, s := range []string{"LOWER()", "LOWER('a', 'b')", "SUBSTR('a')", "CONCAT()", "POSITION('a', 'b')", "POSITION()"} {
+			_, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err, s)
+		}
+	})
+}
+
+func TestArrayFunctions(t *testing.T) {
+	integers := func(ints ...int64) document.Value {
+		vb := document.NewValueBuffer()
+		for _, i := range ints {
+			vb = vb.Append(document.NewIntegerValue(i))
+		}
+		return document.NewArrayValue(vb)
+	}
+
+	stack := expr.EvalStack{
+		Document: document.NewFromJSON([]byte(`{"a": [1, 2, 3, 2], "b": 10, "c": null}`)),
+	}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"ARRAY_LENGTH(a)", document.NewIntegerValue(4), false},
+		{"ARRAY_LENGTH([])", document.NewIntegerValue(0), false},
+		{"ARRAY_LENGTH(b)", nullLitteral, true},
+		{"ARRAY_LENGTH(c)", nullLitteral, false},
+		{"ARRAY_CONTAINS(a, 2)", document.NewBoolValue(true), false},
+		{"ARRAY_CONTAINS(a, 2.0)", document.NewBoolValue(true), false},
+		{"ARRAY_CONTAINS(a, 5)", document.NewBoolValue(false), false},
+		{"ARRAY_APPEND(a, 5)", integers(1, 2, 3, 2, 5), false},
+		{"ARRAY_APPEND(a, c)", document.NewArrayValue(document.NewValueBuffer(
+			document.NewIntegerValue(1), document.NewIntegerValue(2), document.NewIntegerValue(3), document.NewIntegerValue(2), nullLitteral)), false},
+		{"ARRAY_APPEND(c, 1)", nullLitteral, false},
+		{"ARRAY_APPEND(b, 1)", nullLitteral, true},
+		{"ARRAY_REMOVE(a, 2)", integers(1, 3), false},
+		{"ARRAY_REMOVE(a, 5)", integers(1, 2, 3, 2), false},
+		{"ARRAY_REMOVE([1, NULL], NULL)", integers(1), false},
+		{"ARRAY_SLICE(a, 1)", integers(2, 3, 2), false},
+		{"ARRAY_SLICE(a, 1, 3)", integers(2, 3), false},
+		{"ARRAY_SLICE(a, -2)", integers(3, 2), false},
+		{"ARRAY_SLICE(a, 0, -1)", integers(1, 2, 3), false},
+		{"ARRAY_SLICE(a, 3, 1)", integers(), false},
+		{"ARRAY_SLICE(a, 10)", integers(), false},
+		{"ARRAY_SLICE(a, 'a')", nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stack, test.res, test.fails)
+		})
+	}
+}
+
+func TestDocumentFunctions(t *testing.T) {
+	stack := expr.EvalStack{
+		Document: document.NewFromJSON([]byte(`{"a": {"b": 1, "a": 2}, "b": {"a": 3, "c": 4}, "c": null}`)),
+	}
+
+	tests := []struct {
+		expr  string
+		res   string
+		fails bool
+	}{
+		{"KEYS(a)", `["a", "b"]`, false},
+		{"KEYS({})", `[]`, false},
+		{"KEYS(c)", `null`, false},
+		{"KEYS([1])", ``, true},
+		{"HAS_FIELD(a, 'b')", `true`, false},
+		{"HAS_FIELD(a, 'c')", `false`, false},
+		{"HAS_FIELD(a, 1)", ``, true},
+		{"MERGE(a, b)", `{"b": 1, "a": 3, "c": 4}`, false},
+		{"MERGE(a, {})", `{"b": 1, "a": 2}`, false},
+		{"MERGE(a, c)", `null`, false},
+		{"MERGE(a, 1)", ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			v, err := e.Eval(stack)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := v.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, test.res, string(b))
+		})
+	}
+}
+
+func TestNullFunctions(t *testing.T) {
+	stack := expr.EvalStack{
+		Document: document.NewFromJSON([]byte(`{"a": 1, "b": "foo", "c": null, "d": [1], "e": {"a": 1}}`)),
+	}
+
+	tests := []struct {
+		expr  string
+		res   string
+		fails bool
+	}{
+		{"COALESCE(a)", `1`, false},
+		{"COALESCE(c, z, b, a)", `"foo"`, false},
+		{"COALESCE(c, z)", `null`, false},
+		{"IFNULL(z, 10)", `10`, false},
+		{"IFNULL(a, 10)", `1`, false},
+		{"IFNULL(a)", ``, true},
+		{"NULLIF(a, 1)", `null`, false},
+		{"NULLIF(a, 1.0)", `null`, false},
+		{"NULLIF(a, 2)", `1`, false},
+		{"NULLIF(b, c)", `"foo"`, false},
+		{"NULLIF(c, a)", `null`, false},
+		{"TYPEOF(a)", `"integer"`, false},
+		{"TYPEOF(b)", `"text"`, false},
+		{"TYPEOF(c)", `"null"`, false},
+		{"TYPEOF(z)", `"null"`, false},
+		{"TYPEOF(d)", `"array"`, false},
+		{"TYPEOF(e)", `"document"`, false},
+		{"TYPEOF(CAST(a AS DOUBLE))", `"double"`, false},
+		{"TYPEOF(a, b)", ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			if test.fails && err != nil {
+				return
+			}
+			require.NoError(t, err)
+
+			v, err := e.Eval(stack)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := v.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, test.res, string(b))
+		})
+	}
+}
+
+func TestTimeFunctions(t *testing.T) {
+	ts := func(year int, month time.Month, day, hour, min, sec, nsec int) document.Value {
+		return document.NewTimestampValue(time.Date(year, month, day, hour, min, sec, nsec, time.UTC))
+	}
+	integer := document.NewIntegerValue
+	double := document.NewDoubleValue
+
+	stack := expr.EvalStack{
+		Document: document.NewFieldBuffer().
+			Add("a", ts(2020, 11, 5, 10, 30, 15, 500000000)).
+			Add("b", document.NewTextValue("2020-11-05T11:30:15.5+01:00")).
+			Add("c", document.NewNullValue()),
+	}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"DATE_TRUNC('second', a)", ts(2020, 11, 5, 10, 30, 15, 0), false},
+		{"DATE_TRUNC('minute', a)", ts(2020, 11, 5, 10, 30, 0, 0), false},
+		{"DATE_TRUNC('hour', b)", ts(2020, 11, 5, 10, 0, 0, 0), false},
+		{"DATE_TRUNC('DAY', a)", ts(2020, 11, 5, 0, 0, 0, 0), false},
+		{"DATE_TRUNC('week', a)", ts(2020, 11, 2, 0, 0, 0, 0), false},
+		{"DATE_TRUNC('month', a)", ts(2020, 11, 1, 0, 0, 0, 0), false},
+		{"DATE_TRUNC('quarter', a)", ts(2020, 10, 1, 0, 0, 0, 0), false},
+		{"DATE_TRUNC('year', a)", ts(2020, 1, 1, 0, 0, 0, 0), false},
+		{"DATE_TRUNC('year', c)", nullLitteral, false},
+		{"DATE_TRUNC('century', a)", nullLitteral, true},
+		{"DATE_TRUNC('day', 10)", nullLitteral, true},
+		{"EXTRACT(year FROM a)", integer(2020), false},
+		{"EXTRACT(quarter FROM a)", integer(4), false},
+		{"EXTRACT(month FROM b)", integer(11), false},
+		{"EXTRACT(week FROM a)", integer(45), false},
+		{"EXTRACT(day FROM a)", integer(5), false},
+		{"EXTRACT(dow FROM a)", integer(4), false},
+		{"EXTRACT(doy FROM a)", integer(310), false},
+		{"EXTRACT(hour FROM b)", integer(10), false},
+		{"EXTRACT(minute FROM a)", integer(30), false},
+		{"EXTRACT(second FROM a)", double(15.5), false},
+		{"EXTRACT(millisecond FROM a)", double(15500), false},
+		{"EXTRACT(microsecond FROM a)", integer(15500000), false},
+		{"EXTRACT(epoch FROM a)", double(1604572215.5), false},
+		{"EXTRACT(year FROM c)", nullLitteral, false},
+		{"EXTRACT(century FROM a)", nullLitteral, true},
+		{"a + INTERVAL '1 day'", ts(2020, 11, 6, 10, 30, 15, 500000000), false},
+		{"a - INTERVAL '2 hours 30 minutes'", ts(2020, 11, 5, 8, 0, 15, 500000000), false},
+		{"a - DATE_TRUNC('day', a)", integer(37815500000), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stack, test.res, test.fails)
+		})
+	}
+
+	t.Run("NOW()", func(t *testing.T) {
+		e, _, err := parser.NewParser(strings.NewReader("NOW()")).ParseExpr()
+		require.NoError(t, err)
+
+		v, err := e.Eval(stack)
+		require.NoError(t, err)
+		require.Equal(t, document.TimestampValue, v.Type)
+		require.WithinDuration(t, time.Now(), v.V.(time.Time), time.Minute)
+	})
+}
+
+func TestDecimalAggregators(t *testing.T) {
+	dec := func(s string) document.Value {
+		d, err := document.ParseDecimal(s)
+		require.NoError(t, err)
+		return document.NewDecimalValue(d)
+	}
+
+	tests := []struct {
+		expr   string
+		values []document.Value
+		res    string
+	}{
+		{"SUM(a)", []document.Value{dec("0.10"), dec("0.10"), dec("0.10")}, "0.30"},
+		{"SUM(a)", []document.Value{document.NewIntegerValue(1), dec("0.1")}, "1.1"},
+		{"AVG(a)", []document.Value{dec("1"), dec("2"), document.NewIntegerValue(2)}, "1.6666666666666667"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			agg := e.(document.AggregatorBuilder).NewAggregator(document.Value{})
+			for _, v := range test.values {
+				require.NoError(t, agg.Add(document.NewFieldBuffer().Add("a", v)))
+			}
+
+			fb := document.NewFieldBuffer()
+			require.NoError(t, agg.Aggregate(fb))
+
+			v, err := fb.GetByField(test.expr)
+			require.NoError(t, err)
+			require.Equal(t, document.DecimalValue, v.Type)
+			require.Equal(t, test.res, v.V.(document.Decimal).String())
+		})
+	}
+}
+
+func TestStatisticalAggregators(t *testing.T) {
+	tests := []struct {
+		expr   string
+		values []int64
+		res    float64
+	}{
+		{"VARIANCE(a)", []int64{2, 4, 4, 4, 5, 5, 7, 9}, 32.0 / 7},
+		{"STDDEV(a)", []int64{2, 4, 4, 4, 5, 5, 7, 9}, math.Sqrt(32.0 / 7)},
+		{"VARIANCE(DISTINCT a)", []int64{1, 1, 2, 3, 3}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			agg := e.(document.AggregatorBuilder).NewAggregator(document.Value{})
+			for _, v := range test.values {
+				require.NoError(t, agg.Add(document.NewFieldBuffer().Add("a", document.NewIntegerValue(v))))
+			}
+
+			fb := document.NewFieldBuffer()
+			require.NoError(t, agg.Aggregate(fb))
+
+			v, err := fb.GetByField(test.expr)
+			require.NoError(t, err)
+			require.InDelta(t, test.res, v.V.(float64), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package expr
+
+import (
+	"github.com/genjidb/genji/document"
+)
+
+// coalesce returns the first argument that is not NULL,
+// or NULL if all of them are NULL.
+func coalesce(args []document.Value) (document.Value, error) {
+	for _, v := range args {
+		if v.Type != document.NullValue {
+			return v, nil
+		}
+	}
+
+	return nullLitteral, nil
+}
+
+// nullIf returns NULL if both arguments are equal, otherwise it returns the first one.
+func nullIf(args []document.Value) (document.Value, error) {
+	if args[0].Type == document.NullValue || args[1].Type == document.NullValue {
+		return args[0], nil
+	}
+
+	ok, err := args[0].IsEqual(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+	if ok {
+		return nullLitteral, nil
+	}
+
+	return args[0], nil
+}
+
+// typeOf returns the name of the type of its argument, e.g. "integer" or "null".
+func typeOf(args []document.Value) (document.Value, error) {
+	return document.NewTextValue(args[0].Type.String()), nil
+}
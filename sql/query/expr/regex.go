@@ -0,0 +1,128 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+type regexOp struct {
+	*simpleOperator
+
+	// the last compiled pattern is kept for
+	// the lifetime of the statement.
+	mu      sync.Mutex
+	pattern string
+	re      *regexp.Regexp
+}
+
+// Regex creates an expression that evaluates to the result of a =~ b.
+// It returns true if a matches the regular expression b.
+func Regex(a, b Expr) Expr {
+	return &regexOp{simpleOperator: &simpleOperator{a, b, scanner.EQREGEX}}
+}
+
+func (op *regexOp) Eval(ctx EvalStack) (document.Value, error) {
+	a, b, err := op.simpleOperator.eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if a.Type == document.NullValue || b.Type == document.NullValue {
+		return nullLitteral, nil
+	}
+
+	if a.Type != document.TextValue || b.Type != document.TextValue {
+		return nullLitteral, fmt.Errorf("%s operator takes a text", op.Tok)
+	}
+
+	re, err := op.compile(b.V.(string))
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if re.MatchString(a.V.(string)) {
+		return trueLitteral, nil
+	}
+
+	return falseLitteral, nil
+}
+
+// compile returns the compiled pattern, reusing the previous one
+// if the pattern didn't change.
+func (op *regexOp) compile(pattern string) (*regexp.Regexp, error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if op.re != nil && op.pattern == pattern {
+		return op.re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+
+	op.pattern, op.re = pattern, re
+	return re, nil
+}
+
+func (op *regexOp) String() string {
+	return fmt.Sprintf("%v =~ %v", op.a, op.b)
+}
+
+type notRegexOp struct {
+	*regexOp
+}
+
+// NotRegex creates an expression that evaluates to the result of a !~ b.
+// It returns true if a doesn't match the regular expression b.
+func NotRegex(a, b Expr) Expr {
+	return &notRegexOp{&regexOp{simpleOperator: &simpleOperator{a, b, scanner.NEQREGEX}}}
+}
+
+func (op *notRegexOp) Eval(ctx EvalStack) (document.Value, error) {
+	return invertBoolResult(op.regexOp.Eval)(ctx)
+}
+
+func (op *notRegexOp) String() string {
+	return fmt.Sprintf("%v !~ %v", op.a, op.b)
+}
+
+// PrecompileRegex compiles the patterns of the regex operators of e
+// whose right hand side is a literal, so that they are not compiled
+// during the evaluation. It returns an error if one of these patterns is invalid.
+func PrecompileRegex(e Expr) error {
+	var err error
+
+	Walk(e, func(e Expr) bool {
+		var op *regexOp
+		switch t := e.(type) {
+		case *regexOp:
+			op = t
+		case *notRegexOp:
+			op = t.regexOp
+		default:
+			return true
+		}
+
+		lit, ok := op.b.(LiteralValue)
+		if !ok || lit.Type == document.NullValue {
+			return true
+		}
+
+		if lit.Type != document.TextValue {
+			err = errors.New("regular expression must be a text")
+			return false
+		}
+
+		_, err = op.compile(lit.V.(string))
+		return err == nil
+	})
+
+	return err
+}
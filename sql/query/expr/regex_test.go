@@ -0,0 +1,32 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+)
+
+func TestRegexExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"'foo' =~ 'fo+'", document.NewBoolValue(true), false},
+		{"'foo' =~ '^o'", document.NewBoolValue(false), false},
+		{"'FOO' =~ '(?i)^foo$'", document.NewBoolValue(true), false},
+		{"'foo' !~ 'fo+'", document.NewBoolValue(false), false},
+		{"'foo' !~ '^o'", document.NewBoolValue(true), false},
+		{"NULL =~ 'foo'", nullLitteral, false},
+		{"'foo' =~ NULL", nullLitteral, false},
+		{"notFound !~ 'foo'", nullLitteral, false},
+		{"a =~ 'foo'", nullLitteral, true},
+		{"'foo' =~ 'fo('", nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, test.fails)
+		})
+	}
+}
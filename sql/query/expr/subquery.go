@@ -0,0 +1,179 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+)
+
+// A SubqueryStatement is a statement that can be run by a subquery.
+type SubqueryStatement interface {
+	// Stream runs the statement within the transaction of the stack and returns
+	// the resulting stream of documents.
+	// If the stack contains a document, the statement can use it to evaluate
+	// expressions referring to the outer query.
+	Stream(stack EvalStack) (document.Stream, error)
+
+	// Reset makes the statement use the transaction and the parameters
+	// of the stack the next time it is run.
+	Reset()
+}
+
+// A Subquery is an expression that runs a statement within the same transaction
+// as the outer query.
+// Correlated subqueries refer to the documents of the outer query and are run each
+// time they are evaluated. Uncorrelated ones are run once per execution of the outer
+// statement and their result is reused until the subquery is reset.
+// A subquery is evaluated either as a scalar, as an array or as a condition, but it must
+// always be evaluated the same way.
+type Subquery struct {
+	Stmt       SubqueryStatement
+	Correlated bool
+
+	// result of the last run of uncorrelated subqueries.
+	tx     *database.Transaction
+	result document.Value
+}
+
+// Reset clears the result of the last run. It must be called every time
+// the outer statement is executed.
+func (s *Subquery) Reset() {
+	s.tx = nil
+	s.result = document.Value{}
+	s.Stmt.Reset()
+}
+
+// Eval runs the statement and returns the only value of the only document returned.
+// If the statement doesn't return any document, it returns NULL.
+func (s *Subquery) Eval(stack EvalStack) (document.Value, error) {
+	return s.run(stack, func(st document.Stream) (document.Value, error) {
+		v := nullLitteral
+
+		var count int
+		err := st.Iterate(func(d document.Document) error {
+			count++
+			if count > 1 {
+				return errors.New("subquery returned more than one document")
+			}
+
+			var err error
+			v, err = singleValue(d)
+			return err
+		})
+
+		return v, err
+	})
+}
+
+// EvalArray runs the statement and returns an array containing the only value
+// of each document returned.
+func (s *Subquery) EvalArray(stack EvalStack) (document.Value, error) {
+	return s.run(stack, func(st document.Stream) (document.Value, error) {
+		var vb document.ValueBuffer
+
+		err := st.Iterate(func(d document.Document) error {
+			v, err := singleValue(d)
+			if err != nil {
+				return err
+			}
+
+			vb = vb.Append(v)
+			return nil
+		})
+
+		return document.NewArrayValue(vb), err
+	})
+}
+
+// Exists runs the statement and returns true if it returns at least one document.
+func (s *Subquery) Exists(stack EvalStack) (document.Value, error) {
+	return s.run(stack, func(st document.Stream) (document.Value, error) {
+		d, err := st.First()
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		return document.NewBoolValue(d != nil), nil
+	})
+}
+
+func (s *Subquery) run(stack EvalStack, fn func(st document.Stream) (document.Value, error)) (document.Value, error) {
+	if stack.Tx == nil {
+		return nullLitteral, errors.New("subqueries must be run within a transaction")
+	}
+
+	if !s.Correlated {
+		if s.tx == stack.Tx {
+			return s.result, nil
+		}
+
+		stack.Document = nil
+	}
+
+	st, err := s.Stmt.Stream(stack)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	v, err := fn(st)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if !s.Correlated {
+		s.tx = stack.Tx
+		s.result = v
+	}
+
+	return v, nil
+}
+
+// singleValue returns a copy of the only value of d.
+func singleValue(d document.Document) (document.Value, error) {
+	var fb document.FieldBuffer
+	err := fb.Copy(d)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if fb.Len() != 1 {
+		return nullLitteral, fmt.Errorf("subquery must return documents with only one field, got %d", fb.Len())
+	}
+
+	var v document.Value
+	err = fb.Iterate(func(_ string, value document.Value) error {
+		v = value
+		return nil
+	})
+	return v, err
+}
+
+func (s *Subquery) String() string {
+	return fmt.Sprintf("(%v)", s.Stmt)
+}
+
+// Exists is an expression that evaluates to true if the subquery returns
+// at least one document, or the opposite if Not is true.
+type Exists struct {
+	Subquery *Subquery
+	Not      bool
+}
+
+// Eval runs the subquery and returns whether it returned a document.
+func (e Exists) Eval(stack EvalStack) (document.Value, error) {
+	if e.Not {
+		return invertBoolResult(e.Subquery.Exists)(stack)
+	}
+
+	return e.Subquery.Exists(stack)
+}
+
+func (e Exists) String() string {
+	if e.Not {
+		return fmt.Sprintf("NOT EXISTS %v", e.Subquery)
+	}
+
+	return fmt.Sprintf("EXISTS %v", e.Subquery)
+}
@@ -0,0 +1,274 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/genjidb/genji/document"
+)
+
+// textArg returns the text of v, or an error if v is not a text.
+func textArg(v document.Value) (string, error) {
+	if v.Type != document.TextValue {
+		return "", fmt.Errorf("expected text, got %s", v.Type)
+	}
+
+	return v.V.(string), nil
+}
+
+// integerArg returns v as an integer, or an error if v is not a number.
+func integerArg(v document.Value) (int, error) {
+	if !v.Type.IsNumber() {
+		return 0, fmt.Errorf("expected number, got %s", v.Type)
+	}
+
+	v, err := v.CastAsInteger()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.V.(int64)), nil
+}
+
+// textFunc returns a function that applies fn to a text.
+func textFunc(fn func(string) string) func(args []document.Value) (document.Value, error) {
+	return func(args []document.Value) (document.Value, error) {
+		s, err := textArg(args[0])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		return document.NewTextValue(fn(s)), nil
+	}
+}
+
+// trimFunc returns a function that removes whitespaces from a text,
+// or the characters of the second argument, if any.
+func trimFunc(trimSpace func(string, func(rune) bool) string, trim func(string, string) string) func(args []document.Value) (document.Value, error) {
+	return func(args []document.Value) (document.Value, error) {
+		s, err := textArg(args[0])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		if len(args) == 1 {
+			return document.NewTextValue(trimSpace(s, unicode.IsSpace)), nil
+		}
+
+		cutset, err := textArg(args[1])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		return document.NewTextValue(trim(s, cutset)), nil
+	}
+}
+
+// substr returns the part of the text starting at the given position, counting from 1.
+// If a length is given, the result is at most length characters long.
+// Characters before the start of the text count towards the length.
+func substr(args []document.Value) (document.Value, error) {
+	s, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	start, err := integerArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	runes := []rune(s)
+	end := len(runes) + 1
+	if len(args) > 2 {
+		n, err := integerArg(args[2])
+		if err != nil {
+			return nullLitteral, err
+		}
+		if n < 0 {
+			return nullLitteral, fmt.Errorf("negative length %d", n)
+		}
+
+		// the result can't be longer than the text,
+		// clamping n prevents start+n from overflowing.
+		if n > len(runes) {
+			n = len(runes)
+		}
+		if start < end && start+n < end {
+			end = start + n
+		}
+	}
+
+	if start < 1 {
+		start = 1
+	}
+	if start >= end {
+		return document.NewTextValue(""), nil
+	}
+
+	return document.NewTextValue(string(runes[start-1 : end-1])), nil
+}
+
+// length returns the number of characters of a text.
+func length(args []document.Value) (document.Value, error) {
+	s, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewIntegerValue(int64(utf8.RuneCountInString(s))), nil
+}
+
+// concat concatenates the text representation of every argument.
+// NULL values are ignored.
+func concat(args []document.Value) (document.Value, error) {
+	var b strings.Builder
+
+	for _, v := range args {
+		if v.Type == document.NullValue {
+			continue
+		}
+
+		v, err := v.CastAsText()
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		b.WriteString(v.V.(string))
+	}
+
+	return document.NewTextValue(b.String()), nil
+}
+
+// replace replaces every occurrence of the second argument by the third one.
+func replace(args []document.Value) (document.Value, error) {
+	var s [3]string
+	for i, v := range args {
+		var err error
+		s[i], err = textArg(v)
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	if s[1] == "" {
+		return args[0], nil
+	}
+
+	return document.NewTextValue(strings.ReplaceAll(s[0], s[1], s[2])), nil
+}
+
+// split returns the array of the parts of a text separated by the second argument.
+// If the separator is empty, the text is split after each character.
+func split(args []document.Value) (document.Value, error) {
+	s, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	sep, err := textArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	parts := strings.Split(s, sep)
+	vb := make(document.ValueBuffer, len(parts))
+	for i, p := range parts {
+		vb[i] = document.NewTextValue(p)
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
+// startsWith returns true if the text starts with the second argument.
+func startsWith(args []document.Value) (document.Value, error) {
+	s, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	prefix, err := textArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewBoolValue(strings.HasPrefix(s, prefix)), nil
+}
+
+// position returns the position of the first argument in the second one,
+// counting from 1, or 0 if it's not found.
+func position(args []document.Value) (document.Value, error) {
+	sub, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	s, err := textArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	i := strings.Index(s, sub)
+	if i < 0 {
+		return document.NewIntegerValue(0), nil
+	}
+
+	return document.NewIntegerValue(int64(utf8.RuneCountInString(s[:i]) + 1)), nil
+}
+
+// maxPadLength is the maximum length of the texts returned by LPAD and RPAD.
+const maxPadLength = 1 << 20
+
+// padFunc returns a function that pads a text to the given length with the third argument,
+// or with spaces, either on the left or on the right.
+// Texts longer than length are truncated.
+func padFunc(left bool) func(args []document.Value) (document.Value, error) {
+	return func(args []document.Value) (document.Value, error) {
+		s, err := textArg(args[0])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		n, err := integerArg(args[1])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		fill := " "
+		if len(args) > 2 {
+			fill, err = textArg(args[2])
+			if err != nil {
+				return nullLitteral, err
+			}
+		}
+
+		if n > maxPadLength {
+			return nullLitteral, fmt.Errorf("length %d exceeds the maximum of %d", n, maxPadLength)
+		}
+
+		runes := []rune(s)
+		if n <= 0 {
+			return document.NewTextValue(""), nil
+		}
+		if len(runes) >= n || fill == "" {
+			if len(runes) > n {
+				runes = runes[:n]
+			}
+			return document.NewTextValue(string(runes)), nil
+		}
+
+		fillRunes := []rune(fill)
+		pad := make([]rune, n-len(runes))
+		for i := range pad {
+			pad[i] = fillRunes[i%len(fillRunes)]
+		}
+
+		if left {
+			return document.NewTextValue(string(pad) + s), nil
+		}
+
+		return document.NewTextValue(s + string(pad)), nil
+	}
+}
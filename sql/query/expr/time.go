@@ -0,0 +1,181 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genjidb/genji/document"
+)
+
+// NowFunc is the NOW() function.
+// It returns the time at which the current transaction was started,
+// so that every call within the same transaction returns the same value.
+type NowFunc struct{}
+
+// Eval returns the start time of the transaction.
+func (NowFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Tx == nil {
+		return document.NewTimestampValue(time.Now()), nil
+	}
+
+	return document.NewTimestampValue(ctx.Tx.StartedAt()), nil
+}
+
+func (NowFunc) String() string {
+	return "NOW()"
+}
+
+// timestampArg returns the time of v, or an error if v is neither a timestamp
+// nor a text representing a timestamp.
+func timestampArg(v document.Value) (time.Time, error) {
+	switch v.Type {
+	case document.TimestampValue:
+		return v.V.(time.Time), nil
+	case document.TextValue:
+		t, err := v.CastAsTimestamp()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.V.(time.Time), nil
+	}
+
+	return time.Time{}, fmt.Errorf("expected timestamp, got %s", v.Type)
+}
+
+// dateTrunc truncates a timestamp to the given precision, e.g. DATE_TRUNC('day', ts).
+// Weeks start on Monday.
+func dateTrunc(args []document.Value) (document.Value, error) {
+	unit, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	t, err := timestampArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	y, m, d := t.Date()
+
+	switch strings.ToLower(unit) {
+	case "microsecond", "microseconds":
+		t = t.Truncate(time.Microsecond)
+	case "millisecond", "milliseconds":
+		t = t.Truncate(time.Millisecond)
+	case "second", "seconds":
+		t = t.Truncate(time.Second)
+	case "minute", "minutes":
+		t = t.Truncate(time.Minute)
+	case "hour", "hours":
+		t = t.Truncate(time.Hour)
+	case "day", "days":
+		t = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	case "week", "weeks":
+		t = time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	case "month", "months":
+		t = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case "quarter", "quarters":
+		t = time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case "year", "years":
+		t = time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return nullLitteral, fmt.Errorf("unknown unit %q", unit)
+	}
+
+	return document.NewTimestampValue(t), nil
+}
+
+// extractFields lists the fields supported by EXTRACT.
+var extractFields = map[string]func(t time.Time) document.Value{
+	"year":    func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Year())) },
+	"quarter": func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Month()-1)/3 + 1) },
+	"month":   func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Month())) },
+	"week": func(t time.Time) document.Value {
+		_, w := t.ISOWeek()
+		return document.NewIntegerValue(int64(w))
+	},
+	"day":    func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Day())) },
+	"dow":    func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Weekday())) },
+	"doy":    func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.YearDay())) },
+	"hour":   func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Hour())) },
+	"minute": func(t time.Time) document.Value { return document.NewIntegerValue(int64(t.Minute())) },
+	"second": func(t time.Time) document.Value {
+		return document.NewDoubleValue(float64(t.Second()) + float64(t.Nanosecond())/1e9)
+	},
+	"millisecond": func(t time.Time) document.Value {
+		return document.NewDoubleValue(float64(t.Second())*1e3 + float64(t.Nanosecond())/1e6)
+	},
+	"microsecond": func(t time.Time) document.Value {
+		return document.NewIntegerValue(int64(t.Second())*1e6 + int64(t.Nanosecond())/1e3)
+	},
+	"epoch": func(t time.Time) document.Value {
+		return document.NewDoubleValue(float64(t.Unix()) + float64(t.Nanosecond())/1e9)
+	},
+}
+
+// extract returns a field of a timestamp, e.g. EXTRACT(year FROM ts).
+// The seconds, milliseconds and epoch include the fractional part.
+func extract(args []document.Value) (document.Value, error) {
+	field, err := textArg(args[0])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	fn, ok := extractFields[strings.ToLower(field)]
+	if !ok {
+		return nullLitteral, fmt.Errorf("unknown field %q", field)
+	}
+
+	t, err := timestampArg(args[1])
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return fn(t), nil
+}
+
+// intervalUnits lists the units accepted by interval literals.
+// Months and years are not supported since their duration varies.
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+	"week":        7 * 24 * time.Hour,
+}
+
+// ParseInterval parses the text of an interval literal, e.g. '1 day' or '2 hours 30 minutes',
+// and returns the duration it represents. Units can be singular or plural.
+func ParseInterval(s string) (time.Duration, error) {
+	parts := strings.Fields(s)
+	if len(parts) == 0 || len(parts)%2 != 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+
+	var d time.Duration
+	for i := 0; i < len(parts); i += 2 {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+
+		unit := strings.TrimSuffix(strings.ToLower(parts[i+1]), "s")
+		if unit == "month" || unit == "year" {
+			return 0, errors.New("intervals in months or years are not supported")
+		}
+
+		u, ok := intervalUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("unknown interval unit %q", parts[i+1])
+		}
+
+		d += time.Duration(n) * u
+	}
+
+	return d, nil
+}
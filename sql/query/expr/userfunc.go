@@ -0,0 +1,236 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+)
+
+var (
+	valueType = reflect.TypeOf(document.Value{})
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewUserFunc returns a builder of calls to the scalar function fn, which can be added to Functions.
+// fn must be a Go function whose parameters are of type document.Value, the last one being
+// optionally variadic, and that returns a document.Value and optionally an error, e.g.
+//
+//	func(a, b document.Value) (document.Value, error)
+//
+// The number of arguments is checked when the function call is built.
+// Unlike with most builtin functions, NULL arguments are passed to fn.
+func NewUserFunc(name string, fn interface{}) (func(args ...Expr) (Expr, error), error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%s(): expected a function, got %T", name, fn)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if in != valueType {
+			return nil, fmt.Errorf("%s(): parameters must be of type document.Value, got %s", name, t.In(i))
+		}
+	}
+
+	switch {
+	case t.NumOut() == 1 && t.Out(0) == valueType:
+	case t.NumOut() == 2 && t.Out(0) == valueType && t.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("%s(): must return a document.Value and optionally an error", name)
+	}
+
+	sf := scalarFunction{
+		minArgs:    t.NumIn(),
+		maxArgs:    t.NumIn(),
+		acceptNull: true,
+		eval: func(args []document.Value) (document.Value, error) {
+			in := make([]reflect.Value, len(args))
+			for i := range args {
+				in[i] = reflect.ValueOf(args[i])
+			}
+
+			out := v.Call(in)
+			if len(out) == 2 && !out[1].IsNil() {
+				return nullLitteral, out[1].Interface().(error)
+			}
+
+			return out[0].Interface().(document.Value), nil
+		},
+	}
+	if t.IsVariadic() {
+		sf.minArgs, sf.maxArgs = t.NumIn()-1, -1
+	}
+
+	name = strings.ToUpper(name)
+	build := sf.builder(name)
+
+	return func(args ...Expr) (Expr, error) {
+		e, err := build(args...)
+		if err != nil {
+			return nil, err
+		}
+
+		f := e.(ScalarFunc)
+		f.fn = &sf
+		return f, nil
+	}, nil
+}
+
+// An AggregatorFactory creates the aggregators of a user-defined aggregate function,
+// one per group of documents. The aggregators evaluate the arguments of the function call
+// on each document passed to their Add method. Their Aggregate method must add exactly one field
+// to the buffer, whose value is the result of the aggregation; it can be called more than once
+// when the function is used as a window function.
+// The factory must return an error if the arguments are not valid.
+type AggregatorFactory func(args ...Expr) (document.Aggregator, error)
+
+// NewUserAggregate returns a builder of calls to the aggregate function created by factory,
+// which can be added to Functions. The factory is called once when the function call is built,
+// to make sure its arguments are valid.
+func NewUserAggregate(name string, factory AggregatorFactory) func(args ...Expr) (Expr, error) {
+	name = strings.ToUpper(name)
+
+	return func(args ...Expr) (Expr, error) {
+		_, err := factory(args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s(): %w", name, err)
+		}
+
+		return &UserAggregateFunc{Name: name, Args: args, factory: factory}, nil
+	}
+}
+
+// UserAggregateFunc is a call to a user-defined aggregate function.
+type UserAggregateFunc struct {
+	Name     string
+	Args     []Expr
+	Alias    string
+	Distinct bool
+
+	factory AggregatorFactory
+}
+
+// Eval extracts the aggregated value from the given document and returns it.
+func (u *UserAggregateFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, fmt.Errorf("misuse of aggregation function %s()", u.Name)
+	}
+	return ctx.Document.GetByField(u.String())
+}
+
+// SetAlias implements the planner.AggregatorBuilder interface.
+func (u *UserAggregateFunc) SetAlias(alias string) {
+	u.Alias = alias
+}
+
+// NewAggregator implements the planner.AggregatorBuilder interface.
+// If the function is called with DISTINCT and more than one argument,
+// the documents are deduplicated using the array of the values of the arguments.
+func (u *UserAggregateFunc) NewAggregator(group document.Value) document.Aggregator {
+	agg, err := u.factory(u.Args...)
+
+	var e Expr = LiteralExprList(u.Args)
+	if len(u.Args) == 1 {
+		e = u.Args[0]
+	}
+
+	return newDistinctAggregator(u.Distinct, e, &userAggregator{
+		fn:  u,
+		agg: agg,
+		err: err,
+	})
+}
+
+// SetDistinct implements the DistinctSetter interface.
+func (u *UserAggregateFunc) SetDistinct() {
+	u.Distinct = true
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (u *UserAggregateFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*UserAggregateFunc)
+	if !ok || !strings.EqualFold(u.Name, o.Name) || u.Distinct != o.Distinct || len(u.Args) != len(o.Args) {
+		return false
+	}
+
+	for i := range u.Args {
+		if !Equal(u.Args[i], o.Args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the alias if non-zero, otherwise it returns a string representation
+// of the function call.
+func (u *UserAggregateFunc) String() string {
+	if u.Alias != "" {
+		return u.Alias
+	}
+
+	var b strings.Builder
+	b.WriteString(u.Name)
+	b.WriteString("(")
+	b.WriteString(distinctString(u.Distinct))
+	for i, e := range u.Args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%v", e)
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+// userAggregator wraps the aggregator created by the factory of a user-defined aggregate function
+// and adds its result to the aggregated documents under the name of the function call.
+type userAggregator struct {
+	fn  *UserAggregateFunc
+	agg document.Aggregator
+	// error returned by the factory.
+	err error
+}
+
+func (u *userAggregator) Add(d document.Document) error {
+	if u.err != nil {
+		return fmt.Errorf("%s(): %w", u.fn.Name, u.err)
+	}
+
+	return u.agg.Add(d)
+}
+
+// Aggregate adds a field to the given buffer with the value of the only field
+// added by the wrapped aggregator.
+func (u *userAggregator) Aggregate(fb *document.FieldBuffer) error {
+	if u.err != nil {
+		return fmt.Errorf("%s(): %w", u.fn.Name, u.err)
+	}
+
+	var res document.FieldBuffer
+	err := u.agg.Aggregate(&res)
+	if err != nil {
+		return err
+	}
+
+	fields := res.Fields()
+	if len(fields) != 1 {
+		return fmt.Errorf("%s(): the aggregator must return exactly one value, got %d", u.fn.Name, len(fields))
+	}
+
+	v, err := res.GetByField(fields[0])
+	if err != nil {
+		return err
+	}
+
+	fb.Add(u.fn.String(), v)
+	return nil
+}
@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A Window defines how documents are partitioned and ordered
+// before being passed to a window function.
+type Window struct {
+	PartitionBy []Expr
+	OrderBy     []OrderingTerm
+}
+
+// IsEqual compares this window with the other window and returns
+// true if they are equal.
+func (w Window) IsEqual(other Window) bool {
+	if len(w.PartitionBy) != len(other.PartitionBy) || len(w.OrderBy) != len(other.OrderBy) {
+		return false
+	}
+
+	for i := range w.PartitionBy {
+		if !Equal(w.PartitionBy[i], other.PartitionBy[i]) {
+			return false
+		}
+	}
+
+	for i := range w.OrderBy {
+		if !w.OrderBy[i].IsEqual(other.OrderBy[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (w Window) String() string {
+	var parts []string
+
+	if len(w.PartitionBy) > 0 {
+		s := make([]string, len(w.PartitionBy))
+		for i, e := range w.PartitionBy {
+			s[i] = fmt.Sprintf("%v", e)
+		}
+		parts = append(parts, "PARTITION BY "+strings.Join(s, ", "))
+	}
+
+	if len(w.OrderBy) > 0 {
+		s := make([]string, len(w.OrderBy))
+		for i, t := range w.OrderBy {
+			s[i] = t.String()
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(s, ", "))
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(parts, " "))
+}
+
+// An OrderingTerm is an expression used to order the documents of a window.
+type OrderingTerm struct {
+	Expr Expr
+	Desc bool
+	// NullsFirst and NullsLast determine where NULL values are placed,
+	// regardless of the direction.
+	NullsFirst bool
+	NullsLast  bool
+}
+
+// IsEqual compares this term with the other term and returns
+// true if they are equal.
+func (t OrderingTerm) IsEqual(other OrderingTerm) bool {
+	return Equal(t.Expr, other.Expr) &&
+		t.Desc == other.Desc &&
+		t.NullsFirst == other.NullsFirst &&
+		t.NullsLast == other.NullsLast
+}
+
+func (t OrderingTerm) String() string {
+	s := fmt.Sprintf("%v", t.Expr)
+	if t.Desc {
+		s += " DESC"
+	}
+
+	switch {
+	case t.NullsFirst:
+		s += " NULLS FIRST"
+	case t.NullsLast:
+		s += " NULLS LAST"
+	}
+
+	return s
+}
+
+// A WindowFunc is a function evaluated over a window of documents.
+// Unlike aggregators, it doesn't group documents and returns a value for each one of them.
+// Values are computed by the planner and stored in the document, using
+// the string representation of the expression as the field name.
+// The function is either a ranking function, LAG, LEAD or an aggregator.
+type WindowFunc struct {
+	Func   Expr
+	Window Window
+}
+
+// Eval returns the value computed for the current document.
+func (w *WindowFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return document.Value{}, fmt.Errorf("misuse of window function %v", w.Func)
+	}
+
+	v, err := ctx.Document.GetByField(w.String())
+	if err == document.ErrFieldNotFound {
+		return document.Value{}, fmt.Errorf("misuse of window function %v", w.Func)
+	}
+
+	return v, err
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (w *WindowFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*WindowFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(w.Func, o.Func) && w.Window.IsEqual(o.Window)
+}
+
+func (w *WindowFunc) String() string {
+	return fmt.Sprintf("%v OVER %s", w.Func, w.Window)
+}
+
+// IsWindowFunction returns true if e can be used with an OVER clause.
+func IsWindowFunction(e Expr) bool {
+	switch e.(type) {
+	case RowNumberFunc, RankFunc, DenseRankFunc, *LagFunc, *LeadFunc:
+		return true
+	}
+
+	_, ok := e.(document.AggregatorBuilder)
+	return ok
+}
+
+// RowNumberFunc is the ROW_NUMBER window function.
+// It returns the position of the document within its partition, starting at 1.
+type RowNumberFunc struct{}
+
+// Eval returns an error, since ROW_NUMBER must be used with an OVER clause.
+func (RowNumberFunc) Eval(ctx EvalStack) (document.Value, error) {
+	return document.Value{}, fmt.Errorf("misuse of window function ROW_NUMBER()")
+}
+
+func (RowNumberFunc) String() string {
+	return "ROW_NUMBER()"
+}
+
+// RankFunc is the RANK window function.
+// It returns the rank of the document within its partition, with gaps.
+// Documents that are equal according to the ORDER BY clause have the same rank.
+type RankFunc struct{}
+
+// Eval returns an error, since RANK must be used with an OVER clause.
+func (RankFunc) Eval(ctx EvalStack) (document.Value, error) {
+	return document.Value{}, fmt.Errorf("misuse of window function RANK()")
+}
+
+func (RankFunc) String() string {
+	return "RANK()"
+}
+
+// DenseRankFunc is the DENSE_RANK window function.
+// It returns the rank of the document within its partition, without gaps.
+type DenseRankFunc struct{}
+
+// Eval returns an error, since DENSE_RANK must be used with an OVER clause.
+func (DenseRankFunc) Eval(ctx EvalStack) (document.Value, error) {
+	return document.Value{}, fmt.Errorf("misuse of window function DENSE_RANK()")
+}
+
+func (DenseRankFunc) String() string {
+	return "DENSE_RANK()"
+}
+
+// LagFunc is the LAG window function.
+// It evaluates Expr on the document found Offset documents before
+// the current one within its partition, or returns Default if there is none.
+type LagFunc struct {
+	Expr    Expr
+	Offset  Expr
+	Default Expr
+}
+
+// Eval returns an error, since LAG must be used with an OVER clause.
+func (l *LagFunc) Eval(ctx EvalStack) (document.Value, error) {
+	return document.Value{}, fmt.Errorf("misuse of window function LAG()")
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (l *LagFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*LagFunc)
+	if !ok {
+		return false
+	}
+
+	return offsetFuncEqual(l.Expr, l.Offset, l.Default, o.Expr, o.Offset, o.Default)
+}
+
+func (l *LagFunc) String() string {
+	return offsetFuncString("LAG", l.Expr, l.Offset, l.Default)
+}
+
+// LeadFunc is the LEAD window function.
+// It evaluates Expr on the document found Offset documents after
+// the current one within its partition, or returns Default if there is none.
+type LeadFunc struct {
+	Expr    Expr
+	Offset  Expr
+	Default Expr
+}
+
+// Eval returns an error, since LEAD must be used with an OVER clause.
+func (l *LeadFunc) Eval(ctx EvalStack) (document.Value, error) {
+	return document.Value{}, fmt.Errorf("misuse of window function LEAD()")
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (l *LeadFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*LeadFunc)
+	if !ok {
+		return false
+	}
+
+	return offsetFuncEqual(l.Expr, l.Offset, l.Default, o.Expr, o.Offset, o.Default)
+}
+
+func (l *LeadFunc) String() string {
+	return offsetFuncString("LEAD", l.Expr, l.Offset, l.Default)
+}
+
+func offsetFuncEqual(e, offset, def, oe, ooffset, odef Expr) bool {
+	if !Equal(e, oe) {
+		return false
+	}
+
+	if (offset == nil) != (ooffset == nil) || offset != nil && !Equal(offset, ooffset) {
+		return false
+	}
+
+	return (def == nil) == (odef == nil) && (def == nil || Equal(def, odef))
+}
+
+func offsetFuncString(name string, e, offset, def Expr) string {
+	switch {
+	case def != nil:
+		return fmt.Sprintf("%s(%v, %v, %v)", name, e, offset, def)
+	case offset != nil:
+		return fmt.Sprintf("%s(%v, %v)", name, e, offset)
+	}
+
+	return fmt.Sprintf("%s(%v)", name, e)
+}
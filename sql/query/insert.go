@@ -14,6 +14,7 @@ type InsertStmt struct {
 	TableName  string
 	FieldNames []string
 	Values     expr.LiteralExprList
+	OnConflict *OnConflict
 }
 
 // IsReadOnly always returns false. It implements the Statement interface.
@@ -64,11 +65,15 @@ func (stmt InsertStmt) insertDocuments(t *database.Table, stack expr.EvalStack)
 			return res, fmt.Errorf("expected document, got %s", v.Type)
 		}
 
-		res.LastInsertKey, err = t.Insert(v.V.(document.Document))
+		key, err := stmt.OnConflict.Insert(t, v.V.(document.Document), stack)
 		if err != nil {
 			return res, err
 		}
+		if key == nil {
+			continue
+		}
 
+		res.LastInsertKey = key
 		res.RowsAffected++
 	}
 
@@ -104,13 +109,112 @@ func (stmt InsertStmt) insertExprList(t *database.Table, stack expr.EvalStack) (
 			return nil
 		})
 
-		res.LastInsertKey, err = t.Insert(&fb)
+		key, err := stmt.OnConflict.Insert(t, &fb, stack)
 		if err != nil {
 			return res, err
 		}
+		if key == nil {
+			continue
+		}
 
+		res.LastInsertKey = key
 		res.RowsAffected++
 	}
 
 	return res, nil
 }
+
+// OnConflict describes how to handle a document that can't be inserted
+// because it conflicts with an existing document.
+type OnConflict struct {
+	Target database.ConflictTarget
+
+	// Set lists the paths of the existing document to update, along with their new value.
+	// If empty, the document that can't be inserted is ignored.
+	Set []OnConflictSetPair
+}
+
+// OnConflictSetPair associates a path with the expression used to update it.
+// The expression is evaluated against the existing document. The document that
+// can't be inserted can be referred to using the excluded prefix, e.g. excluded.a.
+type OnConflictSetPair struct {
+	Path document.Path
+	Expr expr.Expr
+}
+
+// Insert the document in the table.
+// If d conflicts with an existing document, the existing document is either
+// left untouched or updated, according to the clause.
+// It returns the key of the inserted or updated document, or nil if d was ignored.
+// If oc is nil, conflicts are returned as errors.
+func (oc *OnConflict) Insert(t *database.Table, d document.Document, stack expr.EvalStack) ([]byte, error) {
+	if oc == nil {
+		return t.Insert(d)
+	}
+
+	info, err := t.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err = info.FieldConstraints.ValidateDocument(d)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.FindConflict(d, oc.Target)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return t.Insert(d)
+	}
+
+	if len(oc.Set) == 0 {
+		return nil, nil
+	}
+
+	old, err := t.GetDocument(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var fb document.FieldBuffer
+	err = fb.Copy(old)
+	if err != nil {
+		return nil, err
+	}
+
+	// every expression is evaluated against the existing document.
+	stack.Document = excludedDocument{Document: old, excluded: d}
+	for _, sp := range oc.Set {
+		v, err := sp.Expr.Eval(stack)
+		if err != nil && err != document.ErrFieldNotFound {
+			return nil, err
+		}
+
+		err = fb.Set(sp.Path, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, t.Replace(key, &fb)
+}
+
+// excludedDocument is the document used to evaluate the expressions of DO UPDATE.
+// The excluded field refers to the document that couldn't be inserted,
+// every other field belongs to the existing document.
+type excludedDocument struct {
+	document.Document
+
+	excluded document.Document
+}
+
+func (d excludedDocument) GetByField(field string) (document.Value, error) {
+	if field == "excluded" {
+		return document.NewDocumentValue(d.excluded), nil
+	}
+
+	return d.Document.GetByField(field)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
 	"github.com/stretchr/testify/require"
 )
 
@@ -96,6 +97,215 @@ func TestInsertStmt(t *testing.T) {
 		require.Equal(t, err, database.ErrDuplicateDocument)
 	})
 
+	t.Run("with select", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE events;
+			CREATE TABLE archive (ts INTEGER NOT NULL);
+			CREATE UNIQUE INDEX idx_archive_ts ON archive (ts);
+			INSERT INTO events (ts, name) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd');
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec("INSERT INTO archive SELECT * FROM events WHERE ts < ?", 3)
+		require.NoError(t, err)
+
+		err = db.Exec("INSERT INTO archive (ts, title) SELECT ts * 10, name FROM events WHERE ts > 3")
+		require.NoError(t, err)
+
+		// the documents are indexed
+		res, err := db.Query("SELECT * FROM archive WHERE ts > 1")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.JSONEq(t, `[{"ts": 2, "name": "b"}, {"ts": 40, "title": "d"}]`, buf.String())
+
+		// the documents are validated against the constraints of the table
+		err = db.Exec("INSERT INTO archive SELECT name FROM events")
+		require.Error(t, err)
+
+		// unique indexes are enforced
+		err = db.Exec("INSERT INTO archive SELECT * FROM events WHERE ts = 1")
+		require.Equal(t, database.ErrDuplicateDocument, err)
+
+		// field names must match the number of projected fields
+		err = db.Exec("INSERT INTO archive (ts) SELECT ts, name FROM events")
+		require.Error(t, err)
+
+		// the table can be inserted into itself
+		err = db.Exec("INSERT INTO events SELECT ts + 4 AS ts, name FROM events")
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) FROM events")
+		require.NoError(t, err)
+		v, err := d.GetByField("COUNT(*)")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(8), v)
+	})
+
+	t.Run("with on conflict", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test (id INTEGER PRIMARY KEY);
+			CREATE UNIQUE INDEX idx_name ON test (name);
+			CREATE INDEX idx_count ON test (count);
+			INSERT INTO test (id, name, count) VALUES (1, 'a', 1), (2, 'b', 1);
+		`)
+		require.NoError(t, err)
+
+		// conflicts on the primary key or on any unique index are ignored
+		err = db.Exec("INSERT INTO test (id, name) VALUES (1, 'c'), (3, 'b'), (4, 'd') ON CONFLICT DO NOTHING")
+		require.NoError(t, err)
+
+		// DO UPDATE can reference the existing and the incoming documents
+		err = db.Exec("INSERT INTO test (id, name, count) VALUES (1, 'e', 5) ON CONFLICT (id) DO UPDATE SET name = excluded.name, count = count + excluded.count")
+		require.NoError(t, err)
+
+		// conflicts can target a unique index by name
+		err = db.Exec("INSERT INTO test VALUES {id: 5, name: 'b'} ON CONFLICT ON INDEX idx_name DO UPDATE SET count = 10")
+		require.NoError(t, err)
+
+		// conflicts that don't match the target are returned
+		err = db.Exec("INSERT INTO test (id, name) VALUES (6, 'b') ON CONFLICT (id) DO NOTHING")
+		require.Equal(t, database.ErrDuplicateDocument, err)
+
+		// the target must be the primary key or a unique index
+		err = db.Exec("INSERT INTO test (id, count) VALUES (7, 1) ON CONFLICT (count) DO NOTHING")
+		require.Error(t, err)
+		err = db.Exec("INSERT INTO test (id, count) VALUES (7, 1) ON CONFLICT ON INDEX idx_count DO NOTHING")
+		require.Error(t, err)
+
+		// works with INSERT ... SELECT:
+		// {id: 3, name: 'e'} conflicts with the name of 1, {id: 4, name: 'b'} with the id of 4
+		err = db.Exec("INSERT INTO test SELECT id + 2 AS id, name FROM test WHERE id < 3 ON CONFLICT DO UPDATE SET count = 0")
+		require.NoError(t, err)
+
+		res, err := db.Query("SELECT * FROM test")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.JSONEq(t, `[
+			{"id": 1, "name": "e", "count": 0},
+			{"id": 2, "name": "b", "count": 10},
+			{"id": 4, "name": "d", "count": 0}
+		]`, buf.String())
+
+		// the index of updated fields is maintained
+		d, err := db.QueryDocument("SELECT id FROM test WHERE name = 'e'")
+		require.NoError(t, err)
+		v, err := d.GetByField("id")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(1), v)
+
+		// updates violating a unique index are returned as duplicates
+		err = db.Exec("INSERT INTO test (id, name) VALUES (1, 'x') ON CONFLICT (id) DO UPDATE SET name = 'd'")
+		require.Equal(t, database.ErrDuplicateDocument, err)
+
+		// missing values are indexed as NULL and conflict with each other
+		err = db.Exec("INSERT INTO test (id) VALUES (10)")
+		require.NoError(t, err)
+		err = db.Exec("INSERT INTO test (id) VALUES (11) ON CONFLICT DO NOTHING")
+		require.NoError(t, err)
+		err = db.Exec("INSERT INTO test (id, name) VALUES (12, NULL) ON CONFLICT (name) DO UPDATE SET count = 3")
+		require.NoError(t, err)
+
+		d, err = db.QueryDocument("SELECT COUNT(*) AS n, MAX(count) AS c FROM test WHERE id >= 10")
+		require.NoError(t, err)
+		var n, c int
+		require.NoError(t, document.Scan(d, &n, &c))
+		require.Equal(t, 1, n)
+		require.Equal(t, 3, c)
+	})
+
+	t.Run("with returning", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`CREATE TABLE test(id INTEGER PRIMARY KEY, count DOUBLE DEFAULT 0)`)
+		require.NoError(t, err)
+
+		// documents are returned as they are stored
+		res, err := db.Query(`INSERT INTO test (id) VALUES (1), (2) RETURNING *, pk()`)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"id": 1, "count": 0.0, "pk()": 1}, {"id": 2, "count": 0.0, "pk()": 2}]`, buf.String())
+
+		// ignored documents are not returned
+		res, err = db.Query(`INSERT INTO test VALUES {id: 2}, {id: 3, count: 4} ON CONFLICT DO NOTHING RETURNING id, count * 2 AS twice`)
+		require.NoError(t, err)
+		buf.Reset()
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"id": 3, "twice": 8.0}]`, buf.String())
+
+		res, err = db.Query(`INSERT INTO test SELECT id + 3 AS id FROM test WHERE id < 3 RETURNING id`)
+		require.NoError(t, err)
+		buf.Reset()
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"id": 4}, {"id": 5}]`, buf.String())
+	})
+
+	t.Run("with rows affected", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE a(id INTEGER PRIMARY KEY);
+			CREATE TABLE b(id INTEGER PRIMARY KEY);
+			CREATE TABLE c(id INTEGER PRIMARY KEY);
+		`)
+		require.NoError(t, err)
+
+		exec := func(q string) *query.Result {
+			t.Helper()
+
+			res, err := db.Query(q)
+			require.NoError(t, err)
+			require.NoError(t, res.Iterate(func(d document.Document) error { return nil }))
+			require.NoError(t, res.Close())
+			return res
+		}
+
+		values := exec("INSERT INTO a (id) VALUES (1), (2), (3)")
+		require.EqualValues(t, 3, values.RowsAffected)
+		require.NotNil(t, values.LastInsertKey)
+
+		// INSERT ... SELECT and RETURNING report the inserted documents
+		// like INSERT ... VALUES does.
+		for _, q := range []string{
+			"INSERT INTO b SELECT * FROM a",
+			"INSERT INTO c (id) VALUES (1), (2), (3) RETURNING id",
+		} {
+			res := exec(q)
+			require.EqualValues(t, 3, res.RowsAffected, q)
+			require.Equal(t, values.LastInsertKey, res.LastInsertKey, q)
+		}
+
+		// ignored documents are not counted.
+		res := exec("INSERT INTO b SELECT id + 2 AS id FROM a ON CONFLICT DO NOTHING RETURNING id")
+		require.EqualValues(t, 2, res.RowsAffected)
+	})
+
 	t.Run("with shadowing", func(t *testing.T) {
 		db, err := genji.Open(":memory:")
 		require.NoError(t, err)
@@ -0,0 +1,30 @@
+package query
+
+import (
+	"errors"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// RefreshViewStmt is a DSL that allows creating a REFRESH MATERIALIZED VIEW statement.
+type RefreshViewStmt struct {
+	ViewName string
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt RefreshViewStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the Refresh view statement in the given transaction.
+// It implements the Statement interface.
+func (stmt RefreshViewStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.ViewName == "" {
+		return res, errors.New("missing view name")
+	}
+
+	return res, tx.RefreshView(stmt.ViewName)
+}
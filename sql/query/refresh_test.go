@@ -0,0 +1,223 @@
+package query_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshView(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a, b) VALUES (1, 10), (1, 20), (2, 5);
+		CREATE MATERIALIZED VIEW v AS SELECT COUNT(*) AS n, SUM(b) AS s FROM test;
+		CREATE MATERIALIZED VIEW r AS SELECT a FROM test WHERE b > 5;
+		CREATE VIEW w AS SELECT a FROM test;
+	`)
+	require.NoError(t, err)
+
+	check := func(q, expected string) {
+		t.Helper()
+
+		st, err := db.Query(q)
+		require.NoError(t, err)
+		defer st.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, buf.String())
+	}
+
+	check("SELECT * FROM v", `[{"n": 3, "s": 35}]`)
+	check("SELECT * FROM r", `[{"a": 1}, {"a": 1}]`)
+
+	// aggregates of the whole table are maintained,
+	// the other views are only updated when refreshed.
+	err = db.Exec("INSERT INTO test (a, b) VALUES (3, 6)")
+	require.NoError(t, err)
+	check("SELECT * FROM v", `[{"n": 4, "s": 41}]`)
+	check("SELECT * FROM r", `[{"a": 1}, {"a": 1}]`)
+
+	err = db.Exec("REFRESH MATERIALIZED VIEW r")
+	require.NoError(t, err)
+	check("SELECT * FROM r", `[{"a": 1}, {"a": 1}, {"a": 3}]`)
+
+	// other aggregates of the whole table are only updated when refreshed.
+	err = db.Exec(`
+		CREATE MATERIALIZED VIEW avg AS SELECT AVG(b) AS a, MAX(b) AS m FROM test;
+		INSERT INTO test (a, b) VALUES (4, 12), (5, 3), (6, 8);
+	`)
+	require.NoError(t, err)
+	check("SELECT * FROM v", `[{"n": 7, "s": 64}]`)
+	check("SELECT * FROM avg", `[{"a": 10.25, "m": 20}]`)
+
+	err = db.Exec("REFRESH MATERIALIZED VIEW avg")
+	require.NoError(t, err)
+	check("SELECT * FROM avg", `[{"a": 9.142857142857142, "m": 20}]`)
+
+	err = db.Exec("DROP MATERIALIZED VIEW avg; DELETE FROM test WHERE a > 3")
+	require.NoError(t, err)
+
+	// the documents of the view cannot be modified directly.
+	err = db.Exec("INSERT INTO v (n) VALUES (1)")
+	require.Error(t, err)
+	err = db.Exec("DELETE FROM v")
+	require.Error(t, err)
+	err = db.Exec("DROP TABLE v")
+	require.Error(t, err)
+
+	err = db.Exec("DELETE FROM test")
+	require.NoError(t, err)
+	check("SELECT * FROM v", `[]`)
+
+	// only materialized views can be refreshed.
+	err = db.Exec("REFRESH MATERIALIZED VIEW w")
+	require.Error(t, err)
+	err = db.Exec("REFRESH MATERIALIZED VIEW test")
+	require.Error(t, err)
+
+	// materialized views must be dropped with DROP MATERIALIZED VIEW.
+	err = db.Exec("DROP VIEW v")
+	require.Error(t, err)
+	err = db.Exec("DROP MATERIALIZED VIEW w")
+	require.Error(t, err)
+
+	// the table of a maintained view cannot be dropped or renamed.
+	err = db.Exec("DROP TABLE test")
+	require.Error(t, err)
+	err = db.Exec("ALTER TABLE test RENAME TO foo")
+	require.Error(t, err)
+
+	err = db.Exec("DROP MATERIALIZED VIEW v")
+	require.NoError(t, err)
+	err = db.Exec("SELECT * FROM v")
+	require.Error(t, err)
+
+	// the other views still read the table.
+	err = db.Exec("ALTER TABLE test RENAME TO foo")
+	require.Error(t, err)
+
+	err = db.Exec("DROP MATERIALIZED VIEW r; DROP VIEW w")
+	require.NoError(t, err)
+	err = db.Exec("ALTER TABLE test RENAME TO foo")
+	require.NoError(t, err)
+}
+
+func TestMaterializedViewMaintenance(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		CREATE INDEX idx_test_a ON test (a);
+		INSERT INTO test (a, b) VALUES (1, 10), (1, 20), (2, 5), (3, 0);
+		CREATE MATERIALIZED VIEW v AS
+			SELECT a, COUNT(*) AS n, SUM(b) AS s, MAX(b) AS m FROM test WHERE b > 0 GROUP BY a;
+	`)
+	require.NoError(t, err)
+
+	// views whose documents are updated by adding
+	// or removing the contribution of each document.
+	additive := []struct {
+		name, query, order string
+	}{
+		{"c", "SELECT a, COUNT(*) AS n, COUNT(b) AS nb, SUM(b) AS s FROM test GROUP BY a", " ORDER BY a"},
+		{"ab", "SELECT a, b, COUNT(*) AS n FROM test GROUP BY a, b", " ORDER BY a, b"},
+		{"total", "SELECT COUNT(*) AS n, SUM(b) AS s FROM test WHERE b > 0", ""},
+	}
+	for _, v := range additive {
+		err = db.Exec("CREATE MATERIALIZED VIEW " + v.name + " AS " + v.query)
+		require.NoError(t, err)
+	}
+
+	toJSON := func(q string) string {
+		t.Helper()
+
+		st, err := db.Query(q)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.NoError(t, st.Close())
+		return buf.String()
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Create", ``, `[{"a": 1, "n": 2, "s": 30, "m": 20}, {"a": 2, "n": 1, "s": 5, "m": 5}]`},
+		{"Insert", `INSERT INTO test (a, b) VALUES (1, 5), (4, 1)`, `[{"a": 1, "n": 3, "s": 35, "m": 20}, {"a": 2, "n": 1, "s": 5, "m": 5}, {"a": 4, "n": 1, "s": 1, "m": 1}]`},
+		{"Insert filtered", `INSERT INTO test (a, b) VALUES (5, 0)`, `[{"a": 1, "n": 3, "s": 35, "m": 20}, {"a": 2, "n": 1, "s": 5, "m": 5}, {"a": 4, "n": 1, "s": 1, "m": 1}]`},
+		{"Insert NULL group", `INSERT INTO test (b) VALUES (2)`, `[{"a": null, "n": 1, "s": 2, "m": 2}, {"a": 1, "n": 3, "s": 35, "m": 20}, {"a": 2, "n": 1, "s": 5, "m": 5}, {"a": 4, "n": 1, "s": 1, "m": 1}]`},
+		{"Update", `UPDATE test SET a = 2 WHERE b = 20`, `[{"a": null, "n": 1, "s": 2, "m": 2}, {"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 2, "s": 25, "m": 20}, {"a": 4, "n": 1, "s": 1, "m": 1}]`},
+		{"Update into the WHERE clause", `UPDATE test SET b = 3 WHERE a = 3`, `[{"a": null, "n": 1, "s": 2, "m": 2}, {"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 2, "s": 25, "m": 20}, {"a": 3, "n": 1, "s": 3, "m": 3}, {"a": 4, "n": 1, "s": 1, "m": 1}]`},
+		{"Delete", `DELETE FROM test WHERE a = 4 OR a IS NULL`, `[{"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 2, "s": 25, "m": 20}, {"a": 3, "n": 1, "s": 3, "m": 3}]`},
+		{"Delete max", `DELETE FROM test WHERE b = 20`, `[{"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 1, "s": 5, "m": 5}, {"a": 3, "n": 1, "s": 3, "m": 3}]`},
+		{"Insert NULL value", `INSERT INTO test (a) VALUES (3)`, `[{"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 1, "s": 5, "m": 5}, {"a": 3, "n": 1, "s": 3, "m": 3}]`},
+		{"Delete last value", `DELETE FROM test WHERE a = 3 AND b = 3`, `[{"a": 1, "n": 2, "s": 15, "m": 10}, {"a": 2, "n": 1, "s": 5, "m": 5}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.query != "" {
+				err := db.Exec(test.query)
+				require.NoError(t, err)
+			}
+
+			// the view must return the same documents as its statement.
+			for _, q := range []string{
+				"SELECT * FROM v ORDER BY a",
+				"SELECT a, COUNT(*) AS n, SUM(b) AS s, MAX(b) AS m FROM test WHERE b > 0 GROUP BY a ORDER BY a",
+			} {
+				require.JSONEq(t, test.expected, toJSON(q))
+			}
+
+			for _, v := range additive {
+				require.JSONEq(t, toJSON(v.query+v.order), toJSON("SELECT * FROM "+v.name+v.order), v.name)
+			}
+		})
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		count := func(q func(string, ...interface{}) (*query.Result, error)) int {
+			t.Helper()
+
+			res, err := q("SELECT * FROM v")
+			require.NoError(t, err)
+			defer res.Close()
+
+			var n int
+			err = res.Iterate(func(d document.Document) error {
+				n++
+				return nil
+			})
+			require.NoError(t, err)
+			return n
+		}
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		err = tx.Exec("DELETE FROM test")
+		require.NoError(t, err)
+		require.Equal(t, 0, count(tx.Query))
+
+		err = tx.Rollback()
+		require.NoError(t, err)
+		require.Equal(t, 2, count(db.Query))
+	})
+}
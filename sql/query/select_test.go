@@ -9,6 +9,8 @@ import (
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,12 +36,26 @@ func TestSelectStmt(t *testing.T) {
 		{"No cond", "SELECT * FROM test", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100},{"k":3,"height":100,"weight":200}]`, nil},
 		{"With DISTINCT", "SELECT DISTINCT * FROM test", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100},{"k":3,"height":100,"weight":200}]`, nil},
 		{"With DISTINCT and expr", "SELECT DISTINCT 'a' FROM test", false, `[{"'a'":"a"}]`, nil},
+		{"With DISTINCT and aliased expr", "SELECT DISTINCT k % 2 AS k FROM test", false, `[{"k":1},{"k":0}]`, nil},
 		{"Multiple wildcards cond", "SELECT *, *, color FROM test", false, `[{"k":1,"color":"red","size":10,"shape":"square","k":1,"color":"red","size":10,"shape":"square","color":"red"},{"k":2,"color":"blue","size":10,"weight":100,"k":2,"color":"blue","size":10,"weight":100,"color":"blue"},{"k":3,"height":100,"weight":200,"k":3,"height":100,"weight":200,"color":null}]`, nil},
 		{"With fields", "SELECT color, shape FROM test", false, `[{"color":"red","shape":"square"},{"color":"blue","shape":null},{"color":null,"shape":null}]`, nil},
 		{"With expr fields", "SELECT color, color != 'red' AS notred FROM test", false, `[{"color":"red","notred":false},{"color":"blue","notred":true},{"color":null,"notred":null}]`, nil},
+		{"With case", "SELECT k, CASE WHEN weight > 150 THEN 'heavy' WHEN weight > 50 THEN 'medium' ELSE 'light' END AS w FROM test", false, `[{"k":1,"w":"light"},{"k":2,"w":"medium"},{"k":3,"w":"heavy"}]`, nil},
+		{"With simple case", "SELECT CASE color WHEN 'red' THEN 1 WHEN 'blue' THEN 2 END AS c FROM test", false, `[{"c":1},{"c":2},{"c":null}]`, nil},
+		{"With case in where", "SELECT k FROM test WHERE CASE WHEN color IS NULL THEN height ELSE size END > 50", false, `[{"k":3}]`, nil},
+		{"With case in order by", "SELECT k FROM test ORDER BY CASE color WHEN 'blue' THEN 0 ELSE 1 END, k DESC", false, `[{"k":2},{"k":3},{"k":1}]`, nil},
+		{"With regex", "SELECT k FROM test WHERE color =~ '^r'", false, `[{"k":1}]`, nil},
+		{"With not regex", "SELECT k, color !~ 'e$' AS r FROM test", false, `[{"k":1,"r":true},{"k":2,"r":false},{"k":3,"r":null}]`, nil},
+		{"With regex param", "SELECT k FROM test WHERE shape =~ ?", false, `[{"k":1}]`, []interface{}{"squ.re"}},
+		{"With invalid regex", "SELECT k FROM test WHERE color =~ 'r('", true, ``, nil},
+		{"With text functions", "SELECT UPPER(color) AS c, CONCAT(color, '-', shape) AS cs FROM test WHERE LENGTH(color) > 3", false, `[{"c":"BLUE","cs":"blue-"}]`, nil},
+		{"With text functions in order by", "SELECT k FROM test ORDER BY POSITION('e' IN color) DESC", false, `[{"k":2},{"k":1},{"k":3}]`, nil},
+		{"With null functions", "SELECT k, COALESCE(shape, color, 'none') AS s, NULLIF(size, 10) AS n FROM test", false, `[{"k":1,"s":"square","n":null},{"k":2,"s":"blue","n":null},{"k":3,"s":"none","n":null}]`, nil},
+		{"With typeof", "SELECT k, TYPEOF(k) AS tk, TYPEOF(weight) AS tw FROM test WHERE TYPEOF(shape) = 'null'", false, `[{"k":2,"tk":"integer","tw":"double"},{"k":3,"tk":"integer","tw":"double"}]`, nil},
 		{"With eq op", "SELECT * FROM test WHERE size = 10", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100}]`, nil},
 		{"With neq op", "SELECT * FROM test WHERE color != 'red'", false, `[{"k":2,"color":"blue","size":10,"weight":100}]`, nil},
 		{"With gt op", "SELECT * FROM test WHERE size > 10", false, `[]`, nil},
+		{"With always false cond", "SELECT * FROM test WHERE 1 = 2", false, `[]`, nil},
 		{"With lt op", "SELECT * FROM test WHERE size < 15", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100}]`, nil},
 		{"With lte op", "SELECT * FROM test WHERE color <= 'salmon' ORDER BY k ASC", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100}]`, nil},
 		{"With add op", "SELECT size + 10 AS s FROM test ORDER BY k", false, `[{"s":20},{"s":20},{"s":null}]`, nil},
@@ -53,6 +69,20 @@ func TestSelectStmt(t *testing.T) {
 		{"With group by", "SELECT * FROM test GROUP BY color", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100},{"k":3,"height":100,"weight":200}]`, nil},
 		{"With group by and count", "SELECT COUNT(k) FROM test GROUP BY size", false, `[{"COUNT(k)":2},{"COUNT(k)":1}]`, nil},
 		{"With group by and count wildcard", "SELECT COUNT(*  ) FROM test GROUP BY size", false, `[{"COUNT(*  )":2},{"COUNT(*  )":1}]`, nil},
+		{"With count distinct", "SELECT COUNT(DISTINCT size) FROM test", false, `[{"COUNT(DISTINCT size)":1}]`, nil},
+		{"With sum distinct", "SELECT SUM(DISTINCT size) AS s, SUM(size) AS t FROM test", false, `[{"s":10,"t":20}]`, nil},
+		{"With group by and count distinct", "SELECT COUNT(DISTINCT weight) AS c FROM test GROUP BY size", false, `[{"c":1},{"c":1}]`, nil},
+		{"With group by and group key", "SELECT size, COUNT(k) AS c FROM test GROUP BY size", false, `[{"size":10,"c":2},{"size":null,"c":1}]`, nil},
+		{"With group by multiple exprs", "SELECT size, color, COUNT(k) AS c FROM test GROUP BY size, color ORDER BY color", false, `[{"size":null,"color":null,"c":1},{"size":10,"color":"blue","c":1},{"size":10,"color":"red","c":1}]`, nil},
+		{"With group by expr", "SELECT size / 5 AS s, SUM(k) AS t FROM test GROUP BY size / 5", false, `[{"s":2,"t":3},{"s":null,"t":3}]`, nil},
+		{"With group by without aggregate", "SELECT size FROM test GROUP BY size", false, `[{"size":10},{"size":null}]`, nil},
+		{"With array_agg", "SELECT ARRAY_AGG(k) FROM test", false, `[{"ARRAY_AGG(k)":[1,2,3]}]`, nil},
+		{"With array_agg distinct", "SELECT ARRAY_AGG(DISTINCT size) AS a FROM test", false, `[{"a":[10,null]}]`, nil},
+		{"With group by and array_agg", "SELECT ARRAY_AGG(k) AS a FROM test GROUP BY size", false, `[{"a":[1,2]},{"a":[3]}]`, nil},
+		{"With string_agg", "SELECT STRING_AGG(color, ', ') FROM test", false, `[{"STRING_AGG(color, ', ')":"red, blue"}]`, nil},
+		{"With string_agg and non-text values", "SELECT STRING_AGG(DISTINCT size, '-') AS s FROM test", false, `[{"s":"10"}]`, nil},
+		{"With variance and stddev", "SELECT VARIANCE(k) AS v, STDDEV(k) AS s, VARIANCE(weight) AS w FROM test", false, `[{"v":1.0,"s":1.0,"w":5000.0}]`, nil},
+		{"With group by and stddev", "SELECT STDDEV(weight) AS s FROM test GROUP BY size", false, `[{"s":null},{"s":null}]`, nil},
 		{"With order by", "SELECT * FROM test ORDER BY color", false, `[{"k":3,"height":100,"weight":200},{"k":2,"color":"blue","size":10,"weight":100},{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
 		{"With order by asc", "SELECT * FROM test ORDER BY color ASC", false, `[{"k":3,"height":100,"weight":200},{"k":2,"color":"blue","size":10,"weight":100},{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
 		{"With order by asc numeric", "SELECT * FROM test ORDER BY weight ASC", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100},{"k":3,"height":100,"weight":200}]`, nil},
@@ -67,6 +97,13 @@ func TestSelectStmt(t *testing.T) {
 		{"With order by desc with limit offset", "SELECT * FROM test ORDER BY color DESC LIMIT 1 OFFSET 1", false, `[{"k":2,"color":"blue","size":10,"weight":100}]`, nil},
 		{"With order by pk asc", "SELECT * FROM test ORDER BY k ASC", false, `[{"k":1,"color":"red","size":10,"shape":"square"},{"k":2,"color":"blue","size":10,"weight":100},{"k":3,"height":100,"weight":200}]`, nil},
 		{"With order by pk desc", "SELECT * FROM test ORDER BY k DESC", false, `[{"k":3,"height":100,"weight":200},{"k":2,"color":"blue","size":10,"weight":100},{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
+		{"With order by multiple keys", "SELECT k FROM test ORDER BY size DESC, k DESC", false, `[{"k":2},{"k":1},{"k":3}]`, nil},
+		{"With order by nulls first", "SELECT k FROM test ORDER BY weight DESC NULLS FIRST", false, `[{"k":1},{"k":3},{"k":2}]`, nil},
+		{"With order by nulls last", "SELECT k FROM test ORDER BY color ASC NULLS LAST", false, `[{"k":2},{"k":1},{"k":3}]`, nil},
+		{"With order by nulls last and multiple keys", "SELECT k FROM test ORDER BY size NULLS LAST, weight DESC", false, `[{"k":2},{"k":1},{"k":3}]`, nil},
+		{"With order by expression", "SELECT k FROM test ORDER BY k % 2, k", false, `[{"k":2},{"k":1},{"k":3}]`, nil},
+		{"With order by projected expression", "SELECT k * 2 AS d FROM test ORDER BY k * 2 DESC", false, `[{"d":6},{"d":4},{"d":2}]`, nil},
+		{"With order by alias", "SELECT k * 2 AS d FROM test ORDER BY d DESC LIMIT 1", false, `[{"d":6}]`, nil},
 		{"With order by and where", "SELECT * FROM test WHERE color != 'blue' ORDER BY color DESC LIMIT 1", false, `[{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
 		{"With limit", "SELECT * FROM test WHERE size = 10 LIMIT 1", false, `[{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
 		{"With offset", "SELECT *, pk() FROM test WHERE size = 10 OFFSET 1", false, `[{"pk()":2,"color":"blue","size":10,"weight":100,"k":2}]`, nil},
@@ -316,3 +353,591 @@ func TestDistinct(t *testing.T) {
 		})
 	}
 }
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Inner join", "SELECT * FROM users JOIN posts ON users.id = posts.user_id", false,
+			`[{"id": 1, "name": "foo", "pid": 1, "user_id": 1, "title": "a"}, {"id": 1, "name": "foo", "pid": 2, "user_id": 1, "title": "b"}, {"id": 2, "name": "bar", "pid": 3, "user_id": 2, "title": "c"}]`},
+		{"Inner join with aliases", "SELECT u.name, p.title FROM users AS u INNER JOIN posts p ON p.user_id = u.id", false,
+			`[{"u.name": "foo", "p.title": "a"}, {"u.name": "foo", "p.title": "b"}, {"u.name": "bar", "p.title": "c"}]`},
+		{"Left join", "SELECT u.name, p.title FROM users u LEFT JOIN posts p ON u.id = p.user_id", false,
+			`[{"u.name": "foo", "p.title": "a"}, {"u.name": "foo", "p.title": "b"}, {"u.name": "bar", "p.title": "c"}, {"u.name": "baz", "p.title": null}]`},
+		{"Left outer join", "SELECT u.name, p.title FROM posts p LEFT OUTER JOIN users u ON u.id = p.user_id", false,
+			`[{"u.name": "foo", "p.title": "a"}, {"u.name": "foo", "p.title": "b"}, {"u.name": "bar", "p.title": "c"}, {"u.name": null, "p.title": "d"}]`},
+		{"Select tables", "SELECT u, p FROM users u JOIN posts p ON u.id = p.user_id WHERE p.pid = 3", false,
+			`[{"u": {"id": 2, "name": "bar"}, "p": {"pid": 3, "user_id": 2, "title": "c"}}]`},
+		{"With condition", "SELECT p.title FROM users u JOIN posts p ON u.id = p.user_id AND p.title != 'a'", false,
+			`[{"p.title": "b"}, {"p.title": "c"}]`},
+		{"With where and order by", "SELECT p.title FROM users u JOIN posts p ON u.id = p.user_id WHERE u.name = 'foo' ORDER BY p.title DESC", false,
+			`[{"p.title": "b"}, {"p.title": "a"}]`},
+		{"Multiple joins", "SELECT p.title, q.title AS other FROM users u JOIN posts p ON u.id = p.user_id JOIN posts q ON q.user_id = p.user_id AND q.pid != p.pid", false,
+			`[{"p.title": "a", "other": "b"}, {"p.title": "b", "other": "a"}]`},
+		{"With distinct", "SELECT DISTINCT u.name FROM users u JOIN posts p ON u.id = p.user_id", false,
+			`[{"u.name": "foo"}, {"u.name": "bar"}]`},
+		{"With count", "SELECT COUNT(*) FROM users u LEFT JOIN posts p ON u.id = p.user_id", false,
+			`[{"COUNT(*)": 4}]`},
+		{"Alias without join", "SELECT u.name FROM users u WHERE u.id > 1", false,
+			`[{"u.name": "bar"}, {"u.name": "baz"}]`},
+		{"Qualified paths refer to aliases", "SELECT p.title, p FROM (SELECT pid, {title: 'x'} AS p FROM posts) AS s JOIN posts p ON p.pid = s.pid WHERE p.pid = 1", false,
+			`[{"p.title": "a", "p": {"title": "x"}}]`},
+		{"Duplicate fields", "SELECT * FROM (SELECT user_id AS id, title FROM posts) AS p JOIN users u ON u.id = p.id WHERE p.title = 'c'", false,
+			`[{"p.id": 2, "title": "c", "u.id": 2, "name": "bar"}]`},
+		{"Same table twice", "SELECT * FROM users JOIN users ON id = id", true, ``},
+		{"Missing ON", "SELECT * FROM users JOIN posts", true, ``},
+		{"Unknown table", "SELECT * FROM users JOIN foo ON users.id = foo.id", true, ``},
+	}
+
+	for _, test := range tests {
+		testFn := func(withIndexes bool) func(t *testing.T) {
+			return func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY); CREATE TABLE posts")
+				require.NoError(t, err)
+				if withIndexes {
+					err = db.Exec(`
+						CREATE INDEX idx_user_id ON posts (user_id);
+						CREATE INDEX idx_name ON users (name);
+					`)
+					require.NoError(t, err)
+				}
+
+				err = db.Exec("INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz')")
+				require.NoError(t, err)
+				err = db.Exec("INSERT INTO posts (pid, user_id, title) VALUES (1, 1, 'a'), (2, 1, 'b'), (3, 2, 'c'), (4, 10, 'd')")
+				require.NoError(t, err)
+
+				st, err := db.Query(test.query)
+				defer st.Close()
+				if test.fails {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, test.expected, buf.String())
+			}
+		}
+		t.Run("No Index/"+test.name, testFn(false))
+		t.Run("With Index/"+test.name, testFn(true))
+	}
+}
+
+func TestSubquery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"In", "SELECT name FROM users WHERE id IN (SELECT user_id FROM posts)", false,
+			`[{"name": "foo"}, {"name": "bar"}]`},
+		{"Not in", "SELECT name FROM users WHERE id NOT IN (SELECT user_id FROM posts)", false,
+			`[{"name": "baz"}]`},
+		{"Exists", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM posts WHERE posts.user_id = users.id)", false,
+			`[{"name": "foo"}, {"name": "bar"}]`},
+		{"Not exists with aliases", "SELECT name FROM users u WHERE NOT EXISTS (SELECT 1 FROM posts p WHERE p.user_id = u.id)", false,
+			`[{"name": "baz"}]`},
+		{"Scalar in where", "SELECT name FROM users WHERE id = (SELECT user_id FROM posts WHERE pid = 3)", false,
+			`[{"name": "bar"}]`},
+		{"Scalar in projection", "SELECT name, (SELECT MAX(pid) FROM posts) AS m FROM users WHERE id = 1", false,
+			`[{"name": "foo", "m": 4}]`},
+		{"Correlated in projection", "SELECT name, (SELECT title FROM posts WHERE posts.user_id = users.id AND pid > 1) AS t FROM users", false,
+			`[{"name": "foo", "t": "b"}, {"name": "bar", "t": "c"}, {"name": "baz", "t": null}]`},
+		{"Nested", "SELECT name FROM users WHERE id IN (SELECT user_id FROM posts WHERE pid IN (SELECT pid FROM posts WHERE title = 'c'))", false,
+			`[{"name": "bar"}]`},
+		{"From", "SELECT * FROM (SELECT id, name FROM users WHERE id > 1) AS s WHERE s.id < 3", false,
+			`[{"id": 2, "name": "bar"}]`},
+		{"From with join", "SELECT s.name, p.title FROM (SELECT id, name FROM users) s JOIN posts p ON p.user_id = s.id WHERE p.pid > 2", false,
+			`[{"s.name": "bar", "p.title": "c"}]`},
+		{"More than one document", "SELECT name FROM users WHERE id = (SELECT user_id FROM posts)", true, ``},
+		{"More than one field", "SELECT name FROM users WHERE id IN (SELECT * FROM posts)", true, ``},
+		{"From without alias", "SELECT * FROM (SELECT * FROM users)", true, ``},
+	}
+
+	for _, test := range tests {
+		testFn := func(withIndexes bool) func(t *testing.T) {
+			return func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY); CREATE TABLE posts")
+				require.NoError(t, err)
+				if withIndexes {
+					err = db.Exec(`
+						CREATE INDEX idx_user_id ON posts (user_id);
+						CREATE INDEX idx_title ON posts (title);
+					`)
+					require.NoError(t, err)
+				}
+
+				err = db.Exec("INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz')")
+				require.NoError(t, err)
+				err = db.Exec("INSERT INTO posts (pid, user_id, title) VALUES (1, 1, 'a'), (2, 1, 'b'), (3, 2, 'c'), (4, 10, 'd')")
+				require.NoError(t, err)
+
+				st, err := db.Query(test.query)
+				if test.fails {
+					if err == nil {
+						var buf bytes.Buffer
+						err = document.IteratorToJSONArray(&buf, st)
+						st.Close()
+					}
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, test.expected, buf.String())
+			}
+		}
+		t.Run("No Index/"+test.name, testFn(false))
+		t.Run("With Index/"+test.name, testFn(true))
+	}
+}
+
+func TestSubqueryInWrites(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users; CREATE TABLE posts;
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO posts (pid, user_id) VALUES (1, 1), (2, 1), (3, 2);
+	`)
+	require.NoError(t, err)
+
+	err = db.Exec("DELETE FROM users WHERE NOT EXISTS (SELECT 1 FROM posts WHERE posts.user_id = users.id)")
+	require.NoError(t, err)
+
+	err = db.Exec("UPDATE users SET posts = (SELECT COUNT(*) FROM posts WHERE posts.user_id = users.id)")
+	require.NoError(t, err)
+
+	st, err := db.Query("SELECT * FROM users")
+	require.NoError(t, err)
+	defer st.Close()
+
+	var buf bytes.Buffer
+	err = document.IteratorToJSONArray(&buf, st)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"id": 1, "name": "foo", "posts": 2}, {"id": 2, "name": "bar", "posts": 1}]`, buf.String())
+}
+
+func TestSubqueryRerun(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a, b) VALUES (1, 1), (2, 2), (3, 3);
+	`)
+	require.NoError(t, err)
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// the same statement is run several times in the same transaction,
+	// uncorrelated subqueries must be run again every time.
+	q, err := parser.ParseQuery("SELECT COUNT(*) AS n, (SELECT MAX(a) FROM test WHERE b < ?) AS m FROM test WHERE a IN (SELECT a FROM test)")
+	require.NoError(t, err)
+
+	run := func(params ...expr.Param) string {
+		t.Helper()
+
+		res, err := q.Exec(tx.Transaction, params)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	require.JSONEq(t, `[{"n": 3, "m": 2}]`, run(expr.Param{Value: 3}))
+
+	err = tx.Exec("INSERT INTO test (a, b) VALUES (4, 4)")
+	require.NoError(t, err)
+
+	require.JSONEq(t, `[{"n": 4, "m": 3}]`, run(expr.Param{Value: 4}))
+}
+
+func TestHaving(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Projected aggregate", "SELECT COUNT(*) FROM users GROUP BY country HAVING COUNT(*) > 1", false, `[{"COUNT(*)": 2}]`},
+		{"Alias", "SELECT COUNT(*) AS c FROM users GROUP BY country HAVING c < 2", false, `[{"c": 1}, {"c": 1}]`},
+		{"Aliased aggregate", "SELECT count(*) AS c FROM users GROUP BY country HAVING COUNT(*) = 1", false, `[{"c": 1}, {"c": 1}]`},
+		{"Aggregate not projected", "SELECT MAX(age) FROM users GROUP BY country HAVING COUNT(*) > 1 AND MIN(age) >= 10", false, `[{"MAX(age)": 30}]`},
+		{"With order by", "SELECT AVG(age) AS a FROM users GROUP BY country HAVING SUM(age) > 20 ORDER BY a DESC", false, `[{"a": 40.0}, {"a": 20.0}]`},
+		{"Without group by", "SELECT COUNT(*) FROM users HAVING COUNT(*) > 10", false, `[]`},
+		{"Without group by, matching", "SELECT COUNT(*) AS c, MAX(age) FROM users HAVING c > 3", false, `[{"c": 4, "MAX(age)": 40}]`},
+		{"Without group by, aggregate not projected", "SELECT COUNT(*) AS c FROM users HAVING SUM(age) = 85", false, `[{"c": 4}]`},
+		{"Without group by or aggregates", "SELECT name FROM users HAVING age > 10", true, ``},
+		{"With params", "SELECT COUNT(*) FROM users GROUP BY country HAVING COUNT(*) > ?", false, `[{"COUNT(*)": 2}]`},
+		{"With distinct", "SELECT DISTINCT COUNT(*) AS c FROM users GROUP BY country HAVING c < 2", false, `[{"c": 1}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE users;
+				INSERT INTO users (name, age, country) VALUES
+					('foo', 10, 'fr'), ('bar', 30, 'fr'), ('baz', 40, 'us'), ('bat', 5, 'uk');
+			`)
+			require.NoError(t, err)
+
+			st, err := db.Query(test.query, 1)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestCompoundSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Union", "SELECT x FROM a UNION SELECT x FROM b", false, `[{"x": 1}, {"x": 2}, {"x": 3}, {"x": 4}]`},
+		{"Union all", "SELECT x FROM a UNION ALL SELECT x FROM b WHERE x > 3", false, `[{"x": 1}, {"x": 2}, {"x": 2}, {"x": 3}, {"x": 4}]`},
+		{"Intersect", "SELECT x FROM a INTERSECT SELECT x FROM b", false, `[{"x": 2}, {"x": 3}]`},
+		{"Except", "SELECT x FROM a EXCEPT SELECT x FROM b", false, `[{"x": 1}]`},
+		{"Multiple", "SELECT x FROM a UNION SELECT x FROM b EXCEPT SELECT 4 AS x", false, `[{"x": 1}, {"x": 2}, {"x": 3}]`},
+		{"With order by and limit", "SELECT x FROM a UNION SELECT x FROM b ORDER BY x DESC LIMIT 2", false, `[{"x": 4}, {"x": 3}]`},
+		{"In subquery", "SELECT x FROM a WHERE x IN (SELECT x FROM b EXCEPT SELECT 3 AS x)", false, `[{"x": 2}, {"x": 2}]`},
+		{"Order by before union", "SELECT x FROM a ORDER BY x UNION SELECT x FROM b", true, ``},
+		{"Large integers", "SELECT x - 9007199254740992 AS d FROM (SELECT 9007199254740992 AS x UNION SELECT 9007199254740993 AS x) AS t", false, `[{"d": 0}, {"d": 1}]`},
+		{"Except large integers", "SELECT x - 9007199254740992 AS d FROM (SELECT 9007199254740993 AS x EXCEPT SELECT 9007199254740992 AS x) AS t", false, `[{"d": 1}]`},
+		{"Distinct large integers", "SELECT x - 9007199254740992 AS d FROM (SELECT DISTINCT x FROM (SELECT 9007199254740992 AS x UNION ALL SELECT 9007199254740993 AS x) AS t) AS u", false, `[{"d": 0}, {"d": 1}]`},
+		{"Integers and doubles", "SELECT 1 AS x UNION SELECT 1.0 AS x", false, `[{"x": 1}]`},
+	}
+
+	for _, test := range tests {
+		testFn := func(withIndexes bool) func(t *testing.T) {
+			return func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec("CREATE TABLE a; CREATE TABLE b")
+				require.NoError(t, err)
+				if withIndexes {
+					err = db.Exec("CREATE INDEX idx_a_x ON a (x); CREATE INDEX idx_b_x ON b (x)")
+					require.NoError(t, err)
+				}
+
+				err = db.Exec("INSERT INTO a (x) VALUES (1), (2), (2), (3); INSERT INTO b (x) VALUES (2), (3), (4)")
+				require.NoError(t, err)
+
+				st, err := db.Query(test.query)
+				if test.fails {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, test.expected, buf.String())
+			}
+		}
+		t.Run("No Index/"+test.name, testFn(false))
+		t.Run("With Index/"+test.name, testFn(true))
+	}
+}
+
+func TestCTE(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Simple", "WITH c AS (SELECT id, name FROM categories WHERE parent_id = 1) SELECT name FROM c", false, `[{"name": "b"}, {"name": "c"}]`},
+		{"Reused", "WITH c AS (SELECT id FROM categories WHERE parent_id = 1) SELECT c1.id AS a, c2.id AS b FROM c AS c1 JOIN c AS c2 ON c1.id < c2.id", false, `[{"a": 2, "b": 3}]`},
+		{"Chained", "WITH r AS (SELECT id FROM categories WHERE parent_id IS NULL), c AS (SELECT id FROM categories WHERE parent_id IN (SELECT id FROM r)) SELECT id FROM c", false, `[{"id": 2}, {"id": 3}, {"id": 7}]`},
+		{"Shadowing a table", "WITH categories AS (SELECT id FROM categories WHERE parent_id = 2) SELECT id FROM categories", false, `[{"id": 4}]`},
+		{"Recursive", "WITH RECURSIVE t AS (SELECT id, name FROM categories WHERE id = 2 UNION ALL SELECT c.id AS id, c.name AS name FROM categories c JOIN t ON c.parent_id = t.id) SELECT name FROM t ORDER BY name", false, `[{"name": "b"}, {"name": "d"}, {"name": "e"}]`},
+		{"Recursive without aliases", "WITH RECURSIVE t AS (SELECT id, name FROM categories WHERE id = 2 UNION ALL SELECT c.id, c.name FROM categories c JOIN t ON c.parent_id = t.id) SELECT * FROM t ORDER BY name", false,
+			`[{"id": 2, "name": "b"}, {"id": 4, "name": "d"}, {"id": 5, "name": "e"}]`},
+		{"Recursive with depth", "WITH RECURSIVE t AS (SELECT id, 0 AS depth FROM categories WHERE parent_id IS NULL UNION ALL SELECT c.id AS id, t.depth + 1 AS depth FROM t JOIN categories c ON c.parent_id = t.id) SELECT id, depth FROM t ORDER BY id", false,
+			`[{"id": 1, "depth": 0}, {"id": 2, "depth": 1}, {"id": 3, "depth": 1}, {"id": 4, "depth": 2}, {"id": 5, "depth": 3}, {"id": 6, "depth": 0}, {"id": 7, "depth": 1}]`},
+		{"Recursive union", "WITH RECURSIVE n AS (SELECT 1 AS x UNION SELECT x % 3 + 1 AS x FROM n) SELECT x FROM n", false, `[{"x": 1}, {"x": 2}, {"x": 3}]`},
+		{"Recursive with limit", "WITH RECURSIVE n AS (SELECT 1 AS x UNION ALL SELECT x + 1 AS x FROM n) SELECT x FROM n LIMIT 3", false, `[{"x": 1}, {"x": 2}, {"x": 3}]`},
+		{"Recursive reference in initial select", "WITH RECURSIVE t AS (SELECT id FROM t UNION SELECT id FROM categories) SELECT id FROM t", true, ``},
+		{"Recursive without union", "WITH RECURSIVE t AS (SELECT id FROM t) SELECT id FROM t", true, ``},
+		{"Same name twice", "WITH c AS (SELECT id FROM categories), c AS (SELECT id FROM categories) SELECT id FROM c", true, ``},
+	}
+
+	for _, test := range tests {
+		testFn := func(withIndexes bool) func(t *testing.T) {
+			return func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec("CREATE TABLE categories")
+				require.NoError(t, err)
+				if withIndexes {
+					err = db.Exec("CREATE INDEX idx_categories_parent_id ON categories (parent_id)")
+					require.NoError(t, err)
+				}
+
+				err = db.Exec(`INSERT INTO categories (id, name, parent_id) VALUES
+					(1, "a", NULL), (2, "b", 1), (3, "c", 1), (4, "d", 2), (5, "e", 4), (6, "f", NULL), (7, "g", 6)`)
+				require.NoError(t, err)
+
+				st, err := db.Query(test.query)
+				if test.fails {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, test.expected, buf.String())
+			}
+		}
+		t.Run("No Index/"+test.name, testFn(false))
+		t.Run("With Index/"+test.name, testFn(true))
+	}
+}
+
+func TestWindowFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Row number", "SELECT name, ROW_NUMBER() OVER (ORDER BY salary DESC) AS n FROM employees ORDER BY n LIMIT 3", false,
+			`[{"name": "e", "n": 1}, {"name": "b", "n": 2}, {"name": "c", "n": 3}]`},
+		{"Row number by partition", "SELECT name, ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC) AS n FROM employees", false,
+			`[{"name": "a", "n": 3}, {"name": "b", "n": 1}, {"name": "c", "n": 2}, {"name": "d", "n": 2}, {"name": "e", "n": 1}]`},
+		{"Top-N per group", "WITH r AS (SELECT name, dept, RANK() OVER (PARTITION BY dept ORDER BY salary DESC) AS rank FROM employees) SELECT name FROM r WHERE rank = 1", false,
+			`[{"name": "b"}, {"name": "e"}]`},
+		{"Rank and dense rank", "SELECT salary, RANK() OVER (ORDER BY salary) AS r, DENSE_RANK() OVER (ORDER BY salary) AS dr FROM employees ORDER BY salary", false,
+			`[{"salary": 10, "r": 1, "dr": 1}, {"salary": 20, "r": 2, "dr": 2}, {"salary": 20, "r": 2, "dr": 2}, {"salary": 30, "r": 4, "dr": 3}, {"salary": 50, "r": 5, "dr": 4}]`},
+		{"Lag and lead", "SELECT name, LAG(name) OVER (ORDER BY name) AS prev, LEAD(name, 2, 'none') OVER (ORDER BY name) AS next FROM employees", false,
+			`[{"name": "a", "prev": null, "next": "c"}, {"name": "b", "prev": "a", "next": "d"}, {"name": "c", "prev": "b", "next": "e"}, {"name": "d", "prev": "c", "next": "none"}, {"name": "e", "prev": "d", "next": "none"}]`},
+		{"Running total", "SELECT name, SUM(salary) OVER (ORDER BY name) AS total FROM employees", false,
+			`[{"name": "a", "total": 10}, {"name": "b", "total": 40}, {"name": "c", "total": 60}, {"name": "d", "total": 80}, {"name": "e", "total": 130}]`},
+		{"Running total with peers", "SELECT salary, COUNT(*) OVER (ORDER BY salary) AS c FROM employees ORDER BY salary", false,
+			`[{"salary": 10, "c": 1}, {"salary": 20, "c": 3}, {"salary": 20, "c": 3}, {"salary": 30, "c": 4}, {"salary": 50, "c": 5}]`},
+		{"Aggregates over partition", "SELECT name, AVG(salary) OVER (PARTITION BY dept) AS a, MIN(salary) OVER (PARTITION BY dept) AS mi, MAX(salary) OVER () AS ma FROM employees", false,
+			`[{"name": "a", "a": 20, "mi": 10, "ma": 50}, {"name": "b", "a": 20, "mi": 10, "ma": 50}, {"name": "c", "a": 20, "mi": 10, "ma": 50}, {"name": "d", "a": 35, "mi": 20, "ma": 50}, {"name": "e", "a": 35, "mi": 20, "ma": 50}]`},
+		{"In expression", "SELECT name, salary * 100 / SUM(salary) OVER () AS pct FROM employees WHERE dept = 'x'", false,
+			`[{"name": "a", "pct": 16}, {"name": "b", "pct": 50}, {"name": "c", "pct": 33}]`},
+		{"In order by", "SELECT name FROM employees ORDER BY ROW_NUMBER() OVER (ORDER BY salary, name) DESC LIMIT 2", false,
+			`[{"name": "e"}, {"name": "b"}]`},
+		{"Without over", "SELECT ROW_NUMBER() FROM employees", true, ``},
+		{"Not a window function", "SELECT pk() OVER () FROM employees", true, ``},
+		{"In where", "SELECT name FROM employees WHERE ROW_NUMBER() OVER () = 1", true, ``},
+		{"With group by", "SELECT dept, RANK() OVER (ORDER BY dept) FROM employees GROUP BY dept", true, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec("CREATE TABLE employees (salary INTEGER)")
+			require.NoError(t, err)
+
+			err = db.Exec(`INSERT INTO employees (name, dept, salary) VALUES
+				("a", "x", 10), ("b", "x", 30), ("c", "x", 20), ("d", "y", 20), ("e", "y", 50)`)
+			require.NoError(t, err)
+
+			st, err := db.Query(test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Order by indexed timestamp", "SELECT name FROM events ORDER BY ts", false,
+			`[{"name": "c"}, {"name": "a"}, {"name": "d"}, {"name": "b"}]`},
+		{"Order by indexed timestamp desc", "SELECT name FROM events ORDER BY ts DESC", false,
+			`[{"name": "b"}, {"name": "d"}, {"name": "a"}, {"name": "c"}]`},
+		{"Filter with text", "SELECT name FROM events WHERE ts > '2020-11-02T11:00:00+01:00'", false,
+			`[{"name": "d"}, {"name": "b"}]`},
+		{"Filter with interval", "SELECT name FROM events WHERE ts >= CAST('2020-11-02' AS TIMESTAMP) + INTERVAL '10 hours'", false,
+			`[{"name": "a"}, {"name": "b"}, {"name": "d"}]`},
+		{"Projection", "SELECT ts, EXTRACT(hour FROM ts) AS h FROM events WHERE name = 'a'", false,
+			`[{"ts": "2020-11-02T10:00:00Z", "h": 10}]`},
+		{"Group by truncated timestamp", "SELECT COUNT(*) FROM events GROUP BY DATE_TRUNC('day', ts)", false,
+			`[{"COUNT(*)": 3}, {"COUNT(*)": 1}]`},
+		{"Difference", "SELECT (ts - CAST('2020-11-02' AS TIMESTAMP)) / 1000000 AS s FROM events WHERE name = 'a'", false,
+			`[{"s": 36000}]`},
+		{"Invalid timestamp", "INSERT INTO events (name, ts) VALUES ('e', 'not a timestamp')", true, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE events (ts TIMESTAMP);
+				CREATE INDEX idx_ts ON events (ts);
+				INSERT INTO events (name, ts) VALUES
+					('a', '2020-11-02T10:00:00Z'),
+					('b', '2020-11-03T09:00:00+02:00'),
+					('c', '2020-11-02T10:00:00+02:00'),
+					('d', '2020-11-02T08:30:00-03:00');
+			`)
+			require.NoError(t, err)
+
+			st, err := db.Query(test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Sum", "SELECT SUM(amount) AS s FROM payments", false,
+			`[{"s": 0.60}]`},
+		{"Avg", "SELECT AVG(amount) AS a FROM payments", false,
+			`[{"a": 0.2}]`},
+		{"Rounded on insert", "SELECT CAST(amount AS TEXT) AS a FROM payments WHERE name = 'c'", false,
+			`[{"a": "0.30"}]`},
+		{"Arithmetic", "SELECT CAST(amount * 3 + 1 AS TEXT) AS a FROM payments WHERE name = 'a'", false,
+			`[{"a": "1.30"}]`},
+		{"Order by indexed decimal", "SELECT name FROM payments ORDER BY amount DESC", false,
+			`[{"name": "c"}, {"name": "b"}, {"name": "a"}]`},
+		{"Filter with integer", "SELECT name FROM payments WHERE amount < 1", false,
+			`[{"name": "a"}, {"name": "b"}, {"name": "c"}]`},
+		{"Filter with double", "SELECT name FROM payments WHERE amount = 0.2", false,
+			`[{"name": "b"}]`},
+		{"Cast with precision", "SELECT CAST(1.005 AS DECIMAL(3, 2)) AS a", false,
+			`[{"a": 1.01}]`},
+		{"Cast overflow", "SELECT CAST(100 AS DECIMAL(3, 2)) AS a", true, ``},
+		{"Insert overflow", "INSERT INTO payments (name, amount) VALUES ('d', 123456789)", true, ``},
+		{"Invalid decimal", "INSERT INTO payments (name, amount) VALUES ('d', 'abc')", true, ``},
+		{"Order by untyped", "SELECT y FROM mixed ORDER BY y", false,
+			`[{"y": 0.5}, {"y": 1.5}, {"y": 2.5}, {"y": 3.0}]`},
+		{"Order by untyped and indexed", "SELECT x FROM mixed ORDER BY x DESC", false,
+			`[{"x": 3.0}, {"x": 2.5}, {"x": 1.5}, {"x": 0.5}]`},
+		{"Untyped stored as double", "SELECT typeof(x) AS t FROM mixed WHERE y = 2.5", false,
+			`[{"t": "double"}]`},
+		{"Range on untyped index", "SELECT x FROM mixed WHERE x > 1", false,
+			`[{"x": 1.5}, {"x": 2.5}, {"x": 3.0}]`},
+		{"Filter untyped index with decimal", "SELECT x FROM mixed WHERE x = ?", false,
+			`[{"x": 2.5}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE payments (amount DECIMAL(10, 2));
+				CREATE INDEX idx_amount ON payments (amount);
+				INSERT INTO payments (name, amount) VALUES
+					('a', 0.1),
+					('b', '0.2'),
+					('c', 0.299);
+				CREATE TABLE mixed;
+				CREATE INDEX idx_mixed_x ON mixed (x);
+				INSERT INTO mixed (x, y) VALUES
+					(1.5, 1.5),
+					(CAST(2.5 AS DECIMAL), CAST(2.5 AS DECIMAL)),
+					(3, 3),
+					(CAST(0.5 AS DECIMAL), CAST(0.5 AS DECIMAL));
+			`)
+			require.NoError(t, err)
+
+			st, err := db.Query(test.query, document.NewDecimal(25, 1))
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
@@ -34,6 +34,8 @@ func TestUpdateStmt(t *testing.T) {
 		{"SET / Positional params", "UPDATE test SET a = ?, b = ? WHERE a = ?", false, `[{"a":"a","b":"b","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, []interface{}{"a", "b", "foo1"}},
 		{"SET / Named params", "UPDATE test SET a = $a, b = $b WHERE a = $c", false, `[{"a":"a","b":"b","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, []interface{}{sql.Named("b", "b"), sql.Named("a", "a"), sql.Named("c", "foo1")}},
 
+		{"SET / With case", "UPDATE test SET b = CASE a WHEN 'foo1' THEN 1 ELSE 2 END", false, `[{"a":"foo1","b":1,"c":"baz1"},{"a":"foo2","b":2},{"a":"foo3","d":"bar3","e":"baz3","b":2}]`, nil},
+
 		// UNSET tests.
 		{"UNSET / No cond", `UPDATE test UNSET b`, false, `[{"a":"foo1","c":"baz1"},{"a":"foo2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
 		{"UNSET / No cond / with ident string", "UPDATE test UNSET `a`", true, "", nil},
@@ -93,6 +95,9 @@ func TestUpdateStmt(t *testing.T) {
 			{"SET / No cond / Nested array", `UPDATE foo SET a[1] = [1, 0, 0]`, false, `[{"a": [1, [1, 0, 0], 0]}, {"a": [2, [1, 0, 0]]}]`, nil},
 			{"SET / No cond / with multiple idents", `UPDATE foo SET a[1] = [1, 0, 0], a[1][2] = 9`, false, `[{"a": [1, [1, 0, 9], 0]}, {"a": [2, [1, 0, 9]]}]`, nil},
 			{"SET / No cond / add doc / with multiple idents with multiple indexes", `UPDATE foo SET a[1] = [1, 0, 0], a[1][2] = {"b": "foo"}`, false, `[{"a": [1, [1, 0, {"b":"foo"}], 0]}, {"a": [2, [1, 0, {"b":"foo"}]]}]`, nil},
+			{"SET / No cond / with array functions", `UPDATE foo SET a = ARRAY_APPEND(ARRAY_REMOVE(a, 0), 9)`, false, `[{"a": [1, 9]}, {"a": [2, 9]}]`, nil},
+			{"SET / With cond / with array functions", `UPDATE foo SET a = ARRAY_SLICE(a, 1) WHERE ARRAY_LENGTH(a) > 2`, false, `[{"a": [0, 0]}, {"a": [2, 0]}]`, nil},
+			{"SET / No cond / with document functions", `UPDATE foo SET b = MERGE({x: 1, y: 2}, {y: ARRAY_LENGTH(a)})`, false, `[{"a": [1, 0, 0], "b": {"x": 1, "y": 3}}, {"a": [2, 0], "b": {"x": 1, "y": 2}}]`, nil},
 		}
 
 		for _, tt := range tests {
@@ -123,4 +128,30 @@ func TestUpdateStmt(t *testing.T) {
 			require.JSONEq(t, tt.expected, buf.String())
 		}
 	})
+	t.Run("with returning", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`CREATE TABLE test(a INTEGER, b DOUBLE)`)
+		require.NoError(t, err)
+		err = db.Exec(`INSERT INTO test (a, b) VALUES (1, 1), (2, 2), (3, 3)`)
+		require.NoError(t, err)
+
+		res, err := db.Query(`UPDATE test SET b = a * 10 WHERE a > 1 RETURNING *`)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"a": 2, "b": 20.0}, {"a": 3, "b": 30.0}]`, buf.String())
+
+		res, err = db.Query(`UPDATE test UNSET b WHERE a = 4 RETURNING a`)
+		require.NoError(t, err)
+		buf.Reset()
+		err = document.IteratorToJSONArray(&buf, res)
+		res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[]`, buf.String())
+	})
 }
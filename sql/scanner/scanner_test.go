@@ -118,11 +118,13 @@ func TestScanner_Scan(t *testing.T) {
 
 		// Keywords
 		{s: `ADD`, tok: scanner.ADD_KEYWORD, raw: `ADD`},
+		{s: `ALL`, tok: scanner.ALL, raw: `ALL`},
 		{s: `ALTER`, tok: scanner.ALTER, raw: `ALTER`},
 		{s: `AS`, tok: scanner.AS, raw: `AS`},
 		{s: `ASC`, tok: scanner.ASC, raw: `ASC`},
 		{s: `BY`, tok: scanner.BY, raw: `BY`},
 		{s: `BEGIN`, tok: scanner.BEGIN, raw: `BEGIN`},
+		{s: `CASE`, tok: scanner.CASE, raw: `CASE`},
 		{s: `CAST`, tok: scanner.CAST, raw: `CAST`},
 		{s: `COMMIT`, tok: scanner.COMMIT, raw: `COMMIT`},
 		{s: `CREATE`, tok: scanner.CREATE, raw: `CREATE`},
@@ -132,38 +134,62 @@ func TestScanner_Scan(t *testing.T) {
 		{s: `DESC`, tok: scanner.DESC, raw: `DESC`},
 		{s: `DISTINCT`, tok: scanner.DISTINCT, raw: `DISTINCT`},
 		{s: `DROP`, tok: scanner.DROP, raw: `DROP`},
+		{s: `ELSE`, tok: scanner.ELSE, raw: `ELSE`},
+		{s: `END`, tok: scanner.END, raw: `END`},
+		{s: `EXCEPT`, tok: scanner.EXCEPT, raw: `EXCEPT`},
 		{s: `FIELD`, tok: scanner.FIELD, raw: `FIELD`},
 		{s: `FROM`, tok: scanner.FROM, raw: `FROM`},
 		{s: `GROUP`, tok: scanner.GROUP, raw: `GROUP`},
+		{s: `HAVING`, tok: scanner.HAVING, raw: `HAVING`},
+		{s: `INNER`, tok: scanner.INNER, raw: `INNER`},
 		{s: `INSERT`, tok: scanner.INSERT, raw: `INSERT`},
+		{s: `INTERSECT`, tok: scanner.INTERSECT, raw: `INTERSECT`},
+		{s: `INTERVAL`, tok: scanner.INTERVAL, raw: `INTERVAL`},
 		{s: `INTO`, tok: scanner.INTO, raw: `INTO`},
+		{s: `JOIN`, tok: scanner.JOIN, raw: `JOIN`},
+		{s: `LEFT`, tok: scanner.LEFT, raw: `LEFT`},
 		{s: `LIMIT`, tok: scanner.LIMIT, raw: `LIMIT`},
+		{s: `MATERIALIZED`, tok: scanner.MATERIALIZED, raw: `MATERIALIZED`},
 		{s: `ONLY`, tok: scanner.ONLY, raw: `ONLY`},
 		{s: `OFFSET`, tok: scanner.OFFSET, raw: `OFFSET`},
 		{s: `ORDER`, tok: scanner.ORDER, raw: `ORDER`},
+		{s: `OUTER`, tok: scanner.OUTER, raw: `OUTER`},
+		{s: `OVER`, tok: scanner.OVER, raw: `OVER`},
+		{s: `PARTITION`, tok: scanner.PARTITION, raw: `PARTITION`},
 		{s: `PRIMARY`, tok: scanner.PRIMARY, raw: `PRIMARY`},
 		{s: `READ`, tok: scanner.READ, raw: `READ`},
+		{s: `RECURSIVE`, tok: scanner.RECURSIVE, raw: `RECURSIVE`},
+		{s: `REFRESH`, tok: scanner.REFRESH, raw: `REFRESH`},
 		{s: `REINDEX`, tok: scanner.REINDEX, raw: `REINDEX`},
 		{s: `RENAME`, tok: scanner.RENAME, raw: `RENAME`},
+		{s: `RETURNING`, tok: scanner.RETURNING, raw: `RETURNING`},
 		{s: `ROLLBACK`, tok: scanner.ROLLBACK, raw: `ROLLBACK`},
 		{s: `SELECT`, tok: scanner.SELECT, raw: `SELECT`},
 		{s: `SET`, tok: scanner.SET, raw: `SET`},
 		{s: `TABLE`, tok: scanner.TABLE, raw: `TABLE`},
+		{s: `THEN`, tok: scanner.THEN, raw: `THEN`},
 		{s: `TO`, tok: scanner.TO, raw: `TO`},
 		{s: `TRANSACTION`, tok: scanner.TRANSACTION, raw: `TRANSACTION`},
+		{s: `UNION`, tok: scanner.UNION, raw: `UNION`},
 		{s: `UPDATE`, tok: scanner.UPDATE, raw: `UPDATE`},
 		{s: `UNSET`, tok: scanner.UNSET, raw: `UNSET`},
 		{s: `VALUES`, tok: scanner.VALUES, raw: `VALUES`},
+		{s: `VIEW`, tok: scanner.VIEW, raw: `VIEW`},
+		{s: `WHEN`, tok: scanner.WHEN, raw: `WHEN`},
 		{s: `WHERE`, tok: scanner.WHERE, raw: `WHERE`},
+		{s: `WITH`, tok: scanner.WITH, raw: `WITH`},
 		{s: `WRITE`, tok: scanner.WRITE, raw: `WRITE`},
 		{s: `seLECT`, tok: scanner.SELECT, raw: `seLECT`}, // case insensitive
 
 		// types
 		{s: "BYTES", tok: scanner.TYPEBYTES, raw: `BYTES`},
 		{s: "BOOL", tok: scanner.TYPEBOOL, raw: `BOOL`},
+		{s: "DECIMAL", tok: scanner.TYPEDECIMAL, raw: `DECIMAL`},
 		{s: "DOUBLE", tok: scanner.TYPEDOUBLE, raw: `DOUBLE`},
 		{s: "INTEGER", tok: scanner.TYPEINTEGER, raw: `INTEGER`},
+		{s: "NUMERIC", tok: scanner.TYPENUMERIC, raw: `NUMERIC`},
 		{s: "TEXT", tok: scanner.TYPETEXT, raw: `TEXT`},
+		{s: "TIMESTAMP", tok: scanner.TYPETIMESTAMP, raw: `TIMESTAMP`},
 	}
 
 	for i, tt := range tests {
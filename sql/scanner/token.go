@@ -74,11 +74,13 @@ const (
 	keywordBeg
 	// ALL and the following are Genji SQL Keywords
 	ADD_KEYWORD
+	ALL
 	ALTER
 	AS
 	ASC
 	BEGIN
 	BY
+	CASE
 	CAST
 	COMMIT
 	CREATE
@@ -87,38 +89,59 @@ const (
 	DESC
 	DISTINCT
 	DROP
+	ELSE
+	END
+	EXCEPT
 	EXISTS
 	EXPLAIN
 	FIELD
 	FROM
 	GROUP
+	HAVING
 	IF
 	INDEX
+	INNER
 	INSERT
+	INTERSECT
+	INTERVAL
 	INTO
+	JOIN
 	KEY
+	LEFT
 	LIMIT
+	MATERIALIZED
 	NOT
 	OFFSET
 	ON
 	ONLY
 	ORDER
+	OUTER
+	OVER
+	PARTITION
 	PRECISION
 	PRIMARY
 	READ
+	RECURSIVE
+	REFRESH
 	REINDEX
 	RENAME
+	RETURNING
 	ROLLBACK
 	SELECT
 	SET
 	TABLE
+	THEN
 	TO
 	TRANSACTION
+	UNION
 	UNIQUE
 	UNSET
 	UPDATE
 	VALUES
+	VIEW
+	WHEN
 	WHERE
+	WITH
 	WRITE
 
 	// Aliases
@@ -128,6 +151,7 @@ const (
 	TYPEBOOL
 	TYPEBYTES
 	TYPECHARACTER
+	TYPEDECIMAL
 	TYPEDOCUMENT
 	TYPEDOUBLE
 	TYPEINT
@@ -135,8 +159,10 @@ const (
 	TYPEINT8
 	TYPEINTEGER
 	TYPEMEDIUMINT
+	TYPENUMERIC
 	TYPESMALLINT
 	TYPETEXT
+	TYPETIMESTAMP
 	TYPETINYINT
 	TYPEREAL
 	TYPEVARCHAR
@@ -196,53 +222,76 @@ var tokens = [...]string{
 	SEMICOLON:   ";",
 	DOT:         ".",
 
-	ADD_KEYWORD: "ADD",
-	ALTER:       "ALTER",
-	AS:          "AS",
-	ASC:         "ASC",
-	BEGIN:       "BEGIN",
-	COMMIT:      "COMMIT",
-	GROUP:       "GROUP",
-	BY:          "BY",
-	CREATE:      "CREATE",
-	CAST:        "CAST",
-	DEFAULT:     "DEFAULT",
-	DELETE:      "DELETE",
-	DESC:        "DESC",
-	DISTINCT:    "DISTINCT",
-	DROP:        "DROP",
-	EXISTS:      "EXISTS",
-	EXPLAIN:     "EXPLAIN",
-	KEY:         "KEY",
-	FIELD:       "FIELD",
-	FROM:        "FROM",
-	IF:          "IF",
-	INDEX:       "INDEX",
-	INSERT:      "INSERT",
-	INTO:        "INTO",
-	LIMIT:       "LIMIT",
-	NOT:         "NOT",
-	OFFSET:      "OFFSET",
-	ON:          "ON",
-	ONLY:        "ONLY",
-	ORDER:       "ORDER",
-	PRECISION:   "PRECISION",
-	PRIMARY:     "PRIMARY",
-	READ:        "READ",
-	REINDEX:     "REINDEX",
-	RENAME:      "RENAME",
-	ROLLBACK:    "ROLLBACK",
-	SELECT:      "SELECT",
-	SET:         "SET",
-	TABLE:       "TABLE",
-	TO:          "TO",
-	TRANSACTION: "TRANSACTION",
-	UNIQUE:      "UNIQUE",
-	UNSET:       "UNSET",
-	UPDATE:      "UPDATE",
-	VALUES:      "VALUES",
-	WHERE:       "WHERE",
-	WRITE:       "WRITE",
+	ADD_KEYWORD:  "ADD",
+	ALL:          "ALL",
+	ALTER:        "ALTER",
+	AS:           "AS",
+	ASC:          "ASC",
+	BEGIN:        "BEGIN",
+	COMMIT:       "COMMIT",
+	GROUP:        "GROUP",
+	BY:           "BY",
+	CREATE:       "CREATE",
+	CASE:         "CASE",
+	CAST:         "CAST",
+	DEFAULT:      "DEFAULT",
+	DELETE:       "DELETE",
+	DESC:         "DESC",
+	DISTINCT:     "DISTINCT",
+	DROP:         "DROP",
+	ELSE:         "ELSE",
+	END:          "END",
+	EXCEPT:       "EXCEPT",
+	EXISTS:       "EXISTS",
+	EXPLAIN:      "EXPLAIN",
+	KEY:          "KEY",
+	FIELD:        "FIELD",
+	FROM:         "FROM",
+	HAVING:       "HAVING",
+	IF:           "IF",
+	INDEX:        "INDEX",
+	INNER:        "INNER",
+	INSERT:       "INSERT",
+	INTERSECT:    "INTERSECT",
+	INTERVAL:     "INTERVAL",
+	INTO:         "INTO",
+	JOIN:         "JOIN",
+	LEFT:         "LEFT",
+	LIMIT:        "LIMIT",
+	MATERIALIZED: "MATERIALIZED",
+	NOT:          "NOT",
+	OFFSET:       "OFFSET",
+	ON:           "ON",
+	ONLY:         "ONLY",
+	ORDER:        "ORDER",
+	OUTER:        "OUTER",
+	OVER:         "OVER",
+	PARTITION:    "PARTITION",
+	PRECISION:    "PRECISION",
+	PRIMARY:      "PRIMARY",
+	READ:         "READ",
+	RECURSIVE:    "RECURSIVE",
+	REFRESH:      "REFRESH",
+	REINDEX:      "REINDEX",
+	RENAME:       "RENAME",
+	RETURNING:    "RETURNING",
+	ROLLBACK:     "ROLLBACK",
+	SELECT:       "SELECT",
+	SET:          "SET",
+	TABLE:        "TABLE",
+	THEN:         "THEN",
+	TO:           "TO",
+	TRANSACTION:  "TRANSACTION",
+	UNION:        "UNION",
+	UNIQUE:       "UNIQUE",
+	UNSET:        "UNSET",
+	UPDATE:       "UPDATE",
+	VALUES:       "VALUES",
+	VIEW:         "VIEW",
+	WHEN:         "WHEN",
+	WHERE:        "WHERE",
+	WITH:         "WITH",
+	WRITE:        "WRITE",
 
 	TYPEARRAY:     "ARRAY",
 	TYPEBIGINT:    "BIGINT",
@@ -250,6 +299,7 @@ var tokens = [...]string{
 	TYPEBOOL:      "BOOL",
 	TYPEBYTES:     "BYTES",
 	TYPECHARACTER: "CHARACTER",
+	TYPEDECIMAL:   "DECIMAL",
 	TYPEDOCUMENT:  "DOCUMENT",
 	TYPEDOUBLE:    "DOUBLE",
 	TYPEINT:       "INT",
@@ -257,8 +307,10 @@ var tokens = [...]string{
 	TYPEINT8:      "INT8",
 	TYPEINTEGER:   "INTEGER",
 	TYPEMEDIUMINT: "MEDIUMINT",
+	TYPENUMERIC:   "NUMERIC",
 	TYPESMALLINT:  "SMALLINT",
 	TYPETEXT:      "TEXT",
+	TYPETIMESTAMP: "TIMESTAMP",
 	TYPETINYINT:   "TINYINT",
 	TYPEREAL:      "REAL",
 	TYPEVARCHAR:   "VARCHAR",
@@ -0,0 +1,201 @@
+package genji
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// Statement is a prepared query. The query is parsed once and the plan of its
+// SELECT statements is reused by every execution, only the transaction and the parameters
+// being bound each time. Subqueries are run again on every execution. The query is parsed and planned again if the tables or the indexes of the
+// database are modified between two executions.
+// A Statement must not be used by multiple goroutines concurrently and the result
+// returned by Query must be closed before running the statement again.
+type Statement struct {
+	db *DB
+	tx *Tx
+	p  *preparedQuery
+}
+
+// preparedQuery is the state shared by a statement and
+// the copies returned by its WithContext method.
+type preparedQuery struct {
+	q     string
+	query query.Query
+	// schema version of the database when the query was parsed.
+	schemaVersion int64
+	parsed        bool
+}
+
+// Prepare parses the query and returns a statement that can be run multiple times
+// with different parameters.
+func (db *DB) Prepare(q string) (*Statement, error) {
+	s := Statement{
+		db: db,
+		p:  &preparedQuery{q: q},
+	}
+
+	err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Prepare parses the query and returns a statement that can be run multiple times
+// within the transaction with different parameters.
+func (tx *Tx) Prepare(q string) (*Statement, error) {
+	s := Statement{
+		tx: tx,
+		p:  &preparedQuery{q: q},
+	}
+
+	err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// WithContext creates a new statement handle using the given context for every execution.
+// It shares its parsed query with s.
+func (s *Statement) WithContext(ctx context.Context) *Statement {
+	if s.db == nil {
+		return s
+	}
+
+	return &Statement{
+		db: s.db.WithContext(ctx),
+		p:  s.p,
+	}
+}
+
+// prepare parses the query if it was never parsed or if the schema
+// of the database changed since the last time it was.
+func (s *Statement) prepare() error {
+	var version int64
+	var tx *database.Transaction
+	if s.tx != nil {
+		version = s.tx.DB().SchemaVersion()
+		tx = s.tx.Transaction
+	} else {
+		version = s.db.DB.SchemaVersion()
+		tx = s.db.DB.GetAttachedTx()
+	}
+
+	// the transaction may have modified the schema,
+	// the changes are only visible to this transaction until it is closed.
+	schemaChanged := tx != nil && tx.SchemaChanged()
+
+	if s.p.parsed && !schemaChanged && s.p.schemaVersion == version {
+		return nil
+	}
+
+	pq, err := parseQuery(s.p.q, s.functions())
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range pq.Statements {
+		if t, ok := stmt.(*planner.Tree); ok {
+			t.Prepare()
+		}
+	}
+
+	s.p.query = pq
+	s.p.schemaVersion = version
+	s.p.parsed = true
+	return nil
+}
+
+func (s *Statement) functions() *expr.Functions {
+	if s.tx != nil {
+		return s.tx.functions
+	}
+
+	return s.db.functions
+}
+
+// Query runs the statement and returns the result.
+// The returned result must always be closed after usage.
+func (s *Statement) Query(args ...interface{}) (*query.Result, error) {
+	err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.tx != nil {
+		return s.p.query.Exec(s.tx.Transaction, argsToParams(args))
+	}
+
+	return s.p.query.Run(s.db.ctx, s.db.DB, argsToParams(args))
+}
+
+// QueryDocument runs the statement and returns the first document.
+// If the query returns no error, QueryDocument returns database.ErrDocumentNotFound.
+func (s *Statement) QueryDocument(args ...interface{}) (document.Document, error) {
+	res, err := s.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	r, err := res.First()
+	if err != nil {
+		return nil, err
+	}
+
+	if r == nil {
+		return nil, database.ErrDocumentNotFound
+	}
+
+	var fb document.FieldBuffer
+	err = fb.ScanDocument(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fb, nil
+}
+
+// Exec runs the statement without returning the result.
+func (s *Statement) Exec(args ...interface{}) error {
+	res, err := s.Query(args...)
+	if err != nil {
+		return err
+	}
+
+	return res.Close()
+}
+
+// Columns returns the name of the fields selected by the last statement of the query,
+// or nil if it is not a SELECT statement.
+func (s *Statement) Columns() []string {
+	if len(s.p.query.Statements) == 0 {
+		return nil
+	}
+
+	tree, ok := s.p.query.Statements[len(s.p.query.Statements)-1].(*planner.Tree)
+	if !ok {
+		return nil
+	}
+
+	pn, ok := tree.Root.(*planner.ProjectionNode)
+	if !ok || len(pn.Expressions) == 0 {
+		return nil
+	}
+
+	columns := make([]string, len(pn.Expressions))
+	for i := range pn.Expressions {
+		columns[i] = pn.Expressions[i].Name()
+	}
+
+	return columns
+}